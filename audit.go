@@ -0,0 +1,89 @@
+package query
+
+import "encoding/json"
+
+// Audit is a summary of a Query for audit logs, this is what a Query is
+// marshalled to as JSON, for example,
+//
+//     {
+//         "stmt": "SELECT",
+//         "table": "posts",
+//         "sql": "SELECT * FROM posts WHERE (user_id = $1)",
+//         "clauses": ["FROM", "WHERE"],
+//         "arg_count": 1
+//     }
+//
+// The arguments of the Query are only included if requested via Query.Audit,
+// since they could contain sensitive data.
+type Audit struct {
+	Stmt     string        `json:"stmt"`
+	Table    string        `json:"table,omitempty"`
+	SQL      string        `json:"sql"`
+	Clauses  []string      `json:"clauses"`
+	ArgCount int           `json:"arg_count"`
+	Args     []interface{} `json:"args,omitempty"`
+}
+
+// Audit returns the Audit for the Query. The arguments of the Query are only
+// included if args is true. The clauses are listed in the order they first
+// appear in the built query, with each kind of clause only listed once.
+// Unlike Build, the SQL is kept even if the Query cannot be built, such as for
+// ErrTenant, so a Query can always be logged.
+func (q Query) Audit(args bool) Audit {
+	a, _ := q.audit(args)
+	return a
+}
+
+// audit returns the Audit for the Query, along with the error from building
+// the Query, if any.
+func (q Query) audit(args bool) (Audit, error) {
+	d := dialectOr(q.dialect)
+	p, _ := q.prepare(d)
+
+	clauses := make([]string, 0, len(p.clauses))
+	seen := make(map[clauseKind]struct{}, len(p.clauses))
+
+	for _, cl := range p.clauses {
+		if _, ok := seen[cl.kind()]; ok {
+			continue
+		}
+
+		seen[cl.kind()] = struct{}{}
+		clauses = append(clauses, cl.kind().name())
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b, _, vals, err := q.appendWrite(*buf, d, d.placeholder, 1)
+	*buf = b
+
+	vals = q.finalArgs(d, d.placeholder, vals)
+
+	a := Audit{
+		Stmt:     p.Statement(),
+		Table:    p.Table(),
+		SQL:      string(b),
+		Clauses:  clauses,
+		ArgCount: len(vals),
+	}
+
+	if args {
+		a.Args = vals
+	}
+	return a, err
+}
+
+// MarshalJSON marshals the Query to JSON via its Audit, without the arguments
+// of the Query. If the Query cannot be built, such as for ErrTenant, then the
+// error is returned. This implements the json.Marshaler interface.
+func (q Query) MarshalJSON() ([]byte, error) {
+	a, err := q.audit(false)
+
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(a)
+}
+
+var _ json.Marshaler = (*Query)(nil)
@@ -0,0 +1,100 @@
+package query
+
+import "sync"
+
+// maxBufferSize is the largest capacity of a buffer that will be put back
+// into the pool, this stops a single large query from holding onto its memory
+// for the lifetime of the pool.
+const maxBufferSize = 64 << 10
+
+// buffer is a byte slice that queries are built into.
+type buffer []byte
+
+// bufferPool is the pool of buffers used when building queries, so that a new
+// buffer does not need to be allocated for every query that is built.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make(buffer, 0, 256)
+		return &buf
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *buffer {
+	buf := bufferPool.Get().(*buffer)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putBuffer puts the given buffer back into the pool.
+func putBuffer(buf *buffer) {
+	if cap(*buf) > maxBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// grow grows the capacity of the buffer, if necessary, so another n bytes can
+// be written to it without another allocation.
+func (b *buffer) grow(n int) {
+	if cap(*b)-len(*b) >= n {
+		return
+	}
+
+	grown := make(buffer, len(*b), len(*b)+n)
+	copy(grown, *b)
+	*b = grown
+}
+
+// estimate returns an estimate of the length of the given expression once it
+// has been built. This is used for growing the buffer a Query is built into
+// up front.
+func estimate(e Expr) int {
+	switch v := e.(type) {
+	case listExpr:
+		return estimateItems(v.items) + 2
+	case valuesClause:
+		return estimateItems(v.items) + 4
+	case callExpr:
+		n := len(v.name) + 2
+
+		for _, arg := range v.args {
+			n += estimate(arg) + 2
+		}
+		return n
+	case whereClause:
+		return estimate(v.left) + len(v.op) + estimate(v.right) + len(v.conjunction) + 6
+	case setClause:
+		return len(v.col) + estimate(v.expr) + 5
+	case orderClause:
+		return estimateItems(v.cols) + len(v.dir) + 1
+	case subqueryExpr:
+		return v.q.estimate() + 2
+	case unionClause:
+		return v.q.estimate() + 11
+	case nil:
+		return 0
+	}
+	return 16
+}
+
+func estimateItems(items []string) int {
+	n := 0
+
+	for _, item := range items {
+		n += len(item) + 2
+	}
+	return n
+}
+
+func (b *buffer) WriteString(s string) (int, error) {
+	*b = append(*b, s...)
+	return len(s), nil
+}
+
+func (b *buffer) WriteByte(c byte) error {
+	*b = append(*b, c)
+	return nil
+}
+
+func (b *buffer) String() string { return string(*b) }
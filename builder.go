@@ -0,0 +1,106 @@
+package query
+
+// Builder is a mutable alternative to building a Query via its constructors.
+// A Builder can be Reset and used again to build another query, reusing the
+// memory that was allocated for the previous query, for example,
+//
+//     var b query.Builder
+//
+//     for _, id := range ids {
+//         b.Reset()
+//         b.Select(query.Columns("*")).Apply(
+//             query.From("posts"),
+//             query.Where("user_id", "=", query.Arg(id)),
+//         )
+//
+//         rows, err := db.Query(b.Build(), b.Args()...)
+//     }
+//
+// The zero value of a Builder is ready to use. A Builder is not safe for
+// concurrent use.
+type Builder struct {
+	q     Query
+	hooks []Option
+}
+
+// Reset resets the Builder so it can be used to build another query. Any hooks
+// given to the Builder are kept. The memory allocated for the previous query
+// is kept, so any Query returned from the Builder before it was Reset should
+// no longer be used.
+func (b *Builder) Reset() {
+	exprs := b.q.exprs[:0]
+	clauses := b.q.clauses[:0]
+	defers := b.q.defers[:0]
+
+	for i := range b.q.clauses {
+		b.q.clauses[i] = nil
+	}
+
+	for i := range b.q.exprs {
+		b.q.exprs[i] = nil
+	}
+
+	for i := range b.q.defers {
+		b.q.defers[i] = nil
+	}
+
+	b.q = Query{
+		exprs:   exprs,
+		clauses: clauses,
+		defers:  defers,
+	}
+}
+
+func (b *Builder) stmt(stmt statement, table string, exprs ...Expr) *Builder {
+	b.q.stmt = stmt
+	b.q.table = table
+	b.q.exprs = append(b.q.exprs[:0], exprs...)
+	return b
+}
+
+// Delete sets the Builder to build a DELETE query on the given table.
+func (b *Builder) Delete(table string) *Builder { return b.stmt(_Delete, table) }
+
+// Insert sets the Builder to build an INSERT query on the given table using
+// the given leading expression.
+func (b *Builder) Insert(table string, expr Expr) *Builder { return b.stmt(_Insert, table, expr) }
+
+// Select sets the Builder to build a SELECT query using the given leading
+// expression.
+func (b *Builder) Select(expr Expr) *Builder { return b.stmt(_Select, "", expr) }
+
+// Update sets the Builder to build an UPDATE query on the given table.
+func (b *Builder) Update(table string) *Builder { return b.stmt(_Update, table) }
+
+// Hook adds the given options as hooks to the Builder. These are applied to
+// each query built by the Builder just before it is built, in the same way as
+// the Hook Option.
+func (b *Builder) Hook(opts ...Option) *Builder {
+	b.hooks = append(b.hooks, opts...)
+	return b
+}
+
+// Apply applies the given options to the query being built.
+func (b *Builder) Apply(opts ...Option) *Builder {
+	for _, opt := range opts {
+		b.q = opt(b.q)
+	}
+	return b
+}
+
+// Query returns the Query that has been built. The returned Query is only
+// valid until the Builder is next Reset.
+func (b *Builder) Query() Query {
+	q := b.q.clip()
+
+	if len(b.hooks) > 0 {
+		q.defers = append(q.defers, Options(b.hooks...))
+	}
+	return q
+}
+
+// Build builds up the query in the same way as Query.Build.
+func (b *Builder) Build() string { return b.Query().Build() }
+
+// Args returns the arguments of the query in the same way as Query.Args.
+func (b *Builder) Args() []interface{} { return b.Query().Args() }
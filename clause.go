@@ -1,6 +1,9 @@
 package query
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -28,19 +31,26 @@ const (
 	_WhereClause                  // WHERE
 	_ReturningClause              // RETURNING
 	_SetClause                    // SET
+	_ConflictClause               // ON CONFLICT
+	_FetchClause                  // FETCH
+	_GroupClause                  // GROUP BY
 )
 
+// keyword reports whether the keyword for the clause kind should be written
+// before the clause when built. Some clauses, such as UNION, build their own
+// keywords.
+func (k clauseKind) keyword() bool {
+	switch k {
+	case _UnionClause, _ConflictClause, _FetchClause:
+		return false
+	}
+	return k.name() != ""
+}
+
 func realWhere(conjunction string, left Expr, op string, right Expr) Option {
 	return func(q Query) Query {
-		leftArgs := left.Args()
-		rightArgs := right.Args()
-
-		args := make([]interface{}, 0, len(leftArgs) + len(rightArgs))
-		args = append(args, leftArgs...)
-		args = append(args, rightArgs...)
-
 		if q1, ok := right.(Query); ok {
-			right = Lit("(" + q1.buildInitial() + ")")
+			right = subqueryExpr{q: q1}
 		}
 
 		q.clauses = append(q.clauses, whereClause{
@@ -49,30 +59,209 @@ func realWhere(conjunction string, left Expr, op string, right Expr) Option {
 			left:        left,
 			right:       right,
 		})
-		q.args = append(q.args, args...)
 		return q
 	}
 }
 
 // Where appends a WHERE clause to the Query. This will append the arguments
 // of the given expression to the Query too. By default this will use AND for
-// conjoining multiple WHERE clauses.
-func Where(col, op string, expr Expr) Option {
+// conjoining multiple WHERE clauses. If the given operator is not known then
+// an error is recorded on the Query, see Err.
+func Where(col string, op string, expr Expr) Option {
+	return WhereOp(col, Op(op), expr)
+}
+
+// WhereOp appends a WHERE clause to the Query in the same way as Where, only
+// the operator is given as an Op.
+func WhereOp(col string, op Op, expr Expr) Option {
 	return func(q Query) Query {
-		return realWhere("AND", Ident(col), op, expr)(q)
+		return realWhere("AND", Ident(col), string(op), expr)(q.checkOp(op))
 	}
 }
 
 // OrWhere appends a WHERE clause to the Query. This will append the arguments
 // of the given expression to the Query too. This will use OR for conjoining
-// with a preceding WHERE clause.
-func OrWhere(col, op string, expr Expr) Option {
+// with a preceding WHERE clause. If the given operator is not known then an
+// error is recorded on the Query, see Err.
+func OrWhere(col string, op string, expr Expr) Option {
+	return OrWhereOp(col, Op(op), expr)
+}
+
+// OrWhereOp appends a WHERE clause to the Query in the same way as OrWhere,
+// only the operator is given as an Op.
+func OrWhereOp(col string, op Op, expr Expr) Option {
+	return func(q Query) Query {
+		return realWhere("OR", Ident(col), string(op), expr)(q.checkOp(op))
+	}
+}
+
+// WhereExpr appends a WHERE clause to the Query for the given expression. This
+// will append the arguments of the given expression to the Query too. This is
+// useful for predicates that cannot be expressed as a column, operator, and
+// value, and it will use AND for conjoining multiple WHERE clauses.
+func WhereExpr(expr Expr) Option {
+	return func(q Query) Query {
+		return realWhere("AND", nil, "", expr)(q)
+	}
+}
+
+// OrWhereExpr appends a WHERE clause to the Query for the given expression in
+// the same way as WhereExpr, only this will use OR for conjoining with a
+// preceding WHERE clause.
+func OrWhereExpr(expr Expr) Option {
+	return func(q Query) Query {
+		return realWhere("OR", nil, "", expr)(q)
+	}
+}
+
+// WhereExists appends a WHERE EXISTS (...) clause to the Query using the query
+// returned from the given function. The function is passed the Alias of the
+// table the outer Query is selecting from, this can then be used to reference
+// the outer table's columns within the subquery, for example,
+//
+//     query.Select(
+//         query.Columns("*"),
+//         query.From("users u"),
+//         query.WhereExists(func(u query.Alias) query.Query {
+//             return query.Select(
+//                 query.Columns("1"),
+//                 query.From("posts"),
+//                 query.Where("posts.user_id", "=", u.Ident("id")),
+//             )
+//         }),
+//     )
+func WhereExists(fn func(Alias) Query) Option {
+	return func(q Query) Query {
+		return realWhere("AND", nil, "EXISTS", fn(q.alias()))(q)
+	}
+}
+
+// WhereNotExists appends a WHERE NOT EXISTS (...) clause to the Query in the
+// same way as WhereExists.
+func WhereNotExists(fn func(Alias) Query) Option {
+	return func(q Query) Query {
+		return realWhere("AND", nil, "NOT EXISTS", fn(q.alias()))(q)
+	}
+}
+
+// WhereIn appends a WHERE [column] IN (...) clause to the Query for the given
+// slice of values. Each item in the slice will use the ? placeholder. If the
+// given value is not a slice then it will be treated as a list of one item. If
+// the slice is empty then the clause is built as 1 = 0, which is always false,
+// since IN () is not valid SQL.
+func WhereIn(col string, vals interface{}) Option {
+	items, ok := sliceVals(vals)
+
+	if !ok {
+		items = []interface{}{vals}
+	}
+	return Where(col, "IN", List(items...))
+}
+
+// OrWhereIn appends a WHERE [column] IN (...) clause to the Query in the same
+// way as WhereIn, only this will use OR for conjoining with a preceding WHERE
+// clause.
+func OrWhereIn(col string, vals interface{}) Option {
+	items, ok := sliceVals(vals)
+
+	if !ok {
+		items = []interface{}{vals}
+	}
+	return OrWhere(col, "IN", List(items...))
+}
+
+// WhereMap appends a WHERE clause to the Query for each column and value in
+// the given map. Each clause is conjoined with AND, and the columns are sorted
+// so that the built query is deterministic. A nil value will result in an
+// IS NULL clause, and a slice value will result in an IN clause using a list
+// of the slice's items, otherwise an equality clause is used.
+func WhereMap(m map[string]interface{}) Option {
+	cols := make([]string, 0, len(m))
+
+	for col := range m {
+		cols = append(cols, col)
+	}
+
+	sort.Strings(cols)
+
 	return func(q Query) Query {
-		return realWhere("OR", Ident(col), op, expr)(q)
+		for _, col := range cols {
+			q = whereValue(col, m[col])(q)
+		}
+		return q
 	}
 }
 
-// From appends a FROM clause for the given table to the Query.
+// WhereStruct appends a WHERE clause to the Query for each non-zero field in
+// the given struct that has a db tag. The column is taken from the db tag, and
+// the operator from the query tag, for example,
+//
+//     type Filter struct {
+//         Status string `db:"status"`
+//         Title  string `db:"title" query:"LIKE"`
+//     }
+//
+// If no query tag is given then the clause is built in the same way as it
+// would be via WhereMap. Each clause is conjoined with AND.
+func WhereStruct(v interface{}) Option { return tags.WhereStruct(v) }
+
+// WhereStruct appends a WHERE clause to the Query for each non-zero field in
+// the given struct in the same way as WhereStruct, only the fields are mapped
+// via the Mapper.
+func (m Mapper) WhereStruct(v interface{}) Option {
+	return func(q Query) Query {
+		for _, f := range m.tableFields(q.Table(), v) {
+			if f.zero() {
+				continue
+			}
+
+			if f.op != "" {
+				q = Where(f.col, f.op, Arg(f.value()))(q)
+				continue
+			}
+			q = whereValue(f.col, f.value())(q)
+		}
+		return q
+	}
+}
+
+// whereValue returns a WHERE clause Option for the given column, with the
+// operator and expression being derived from the given value.
+func whereValue(col string, val interface{}) Option {
+	if val == nil {
+		return Where(col, "IS", Lit("NULL"))
+	}
+
+	if vals, ok := sliceVals(val); ok {
+		return Where(col, "IN", List(vals...))
+	}
+	return Where(col, "=", Arg(val))
+}
+
+// sliceVals returns the items of the given value if it is a slice or an array.
+// A []byte is not treated as a slice, since this would typically be given as a
+// single argument.
+func sliceVals(val interface{}) ([]interface{}, bool) {
+	if _, ok := val.([]byte); ok {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(val)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	vals := make([]interface{}, 0, rv.Len())
+
+	for i := 0; i < rv.Len(); i++ {
+		vals = append(vals, rv.Index(i).Interface())
+	}
+	return vals, true
+}
+
+// From appends a FROM clause for the given table to the Query. If given more
+// than once, then the tables are separated with a comma.
 func From(table string) Option {
 	return func(q Query) Query {
 		q.clauses = append(q.clauses, fromClause{
@@ -82,7 +271,8 @@ func From(table string) Option {
 	}
 }
 
-// Limit appends a LIMIT clause with the given amount to the Query.
+// Limit appends a LIMIT clause with the given amount to the Query. If given
+// more than once, then the last amount is used.
 func Limit(n int64) Option {
 	return func(q Query) Query {
 		q.clauses = append(q.clauses, limitClause(n))
@@ -90,7 +280,8 @@ func Limit(n int64) Option {
 	}
 }
 
-// Offset appends an OFFSET clause with the given value to the Query.
+// Offset appends an OFFSET clause with the given value to the Query. If given
+// more than once, then the last value is used.
 func Offset(n int64) Option {
 	return func(q Query) Query {
 		q.clauses = append(q.clauses, offsetClause(n))
@@ -98,8 +289,29 @@ func Offset(n int64) Option {
 	}
 }
 
+// GroupBy appends a GROUP BY [column,...] clause for the given columns to the
+// Query. A column that has already been grouped by is ignored, for example,
+//
+//     q := query.Select(
+//         query.Columns("user_id", "COUNT(*)"),
+//         query.From("posts"),
+//         query.GroupBy("user_id"),
+//     )
+//
+// would result in the following query being built,
+//
+//     SELECT user_id, COUNT(*) FROM posts GROUP BY user_id
+func GroupBy(cols ...string) Option {
+	return func(q Query) Query {
+		q.clauses = append(q.clauses, groupClause{
+			cols: cols,
+		})
+		return q
+	}
+}
+
 // OrderAsc appends an ORDER BY [column,...] ASC clause for the given columns
-// to the Query.
+// to the Query. A column that has already been ordered by is ignored.
 func OrderAsc(cols ...string) Option {
 	return func(q Query) Query {
 		q.clauses = append(q.clauses, orderClause{
@@ -111,7 +323,7 @@ func OrderAsc(cols ...string) Option {
 }
 
 // OrderDesc appends an ORDER BY [column,...] DESC clause for the given columns
-// to the Query.
+// to the Query. A column that has already been ordered by is ignored.
 func OrderDesc(cols ...string) Option {
 	return func(q Query) Query {
 		q.clauses = append(q.clauses, orderClause{
@@ -122,31 +334,474 @@ func OrderDesc(cols ...string) Option {
 	}
 }
 
+// OrderedColumn is a column along with the direction in which it should be
+// ordered, this is used for keyset pagination via Seek.
+type OrderedColumn struct {
+	Col string
+	Dir string
+}
+
+// Asc returns an OrderedColumn for the given column in ascending order.
+func Asc(col string) OrderedColumn {
+	return OrderedColumn{
+		Col: col,
+		Dir: "ASC",
+	}
+}
+
+// Desc returns an OrderedColumn for the given column in descending order.
+func Desc(col string) OrderedColumn {
+	return OrderedColumn{
+		Col: col,
+		Dir: "DESC",
+	}
+}
+
+// cmp returns the comparison operator for seeking past a value in the
+// column's direction.
+func (c OrderedColumn) cmp() string {
+	if strings.ToUpper(c.Dir) == "DESC" {
+		return "<"
+	}
+	return ">"
+}
+
+// Seek appends a WHERE clause and ORDER BY clause to the Query for keyset
+// pagination on the given columns. The WHERE clause will only be appended if
+// values are given for the row to seek past, these should be in the same order
+// as the columns. If every column is ordered in the same direction then a row
+// comparison is used, for example,
+//
+//     query.Seek([]query.OrderedColumn{query.Desc("created_at"), query.Desc("id")}, t, 10)
+//
+// would result in the following being built up,
+//
+//     WHERE ((created_at, id) < ($1, $2)) ORDER BY created_at DESC, id DESC
+//
+// otherwise the comparison is expanded out for each column. If the number of
+// values does not match the number of columns then no WHERE clause is
+// appended, and ErrColumns is recorded on the Query and returned by Err.
+func Seek(order []OrderedColumn, after ...interface{}) Option {
+	return func(q Query) Query {
+		if len(after) > 0 {
+			if len(after) != len(order) {
+				q.errs = append(q.errs, fmt.Errorf("query: %w: Seek has %d values, expected %d", ErrColumns, len(after), len(order)))
+			} else {
+				q = seekWhere(order, after)(q)
+			}
+		}
+
+		for _, c := range order {
+			q.clauses = append(q.clauses, orderClause{
+				cols: []string{c.Col},
+				dir:  strings.ToUpper(c.Dir),
+			})
+		}
+		return q
+	}
+}
+
+func seekWhere(order []OrderedColumn, after []interface{}) Option {
+	cols := make([]string, 0, len(order))
+	same := true
+
+	for i, c := range order {
+		cols = append(cols, c.Col)
+
+		if i > 0 && c.cmp() != order[0].cmp() {
+			same = false
+		}
+	}
+
+	if same {
+		left := listExpr{
+			items: cols,
+			wrap:  true,
+		}
+		return func(q Query) Query {
+			return realWhere("AND", left, order[0].cmp(), List(after...))(q)
+		}
+	}
+
+	// Expand the comparison out for each column, so for (a ASC, b DESC) this
+	// would be (a > ? OR (a = ? AND b < ?)).
+	var (
+		buf  strings.Builder
+		args []interface{}
+	)
+
+	buf.WriteByte('(')
+
+	for i, c := range order {
+		if i > 0 {
+			buf.WriteString(" OR ")
+		}
+
+		if i > 0 {
+			buf.WriteByte('(')
+		}
+
+		for j := 0; j < i; j++ {
+			buf.WriteString(order[j].Col + " = ? AND ")
+			args = append(args, after[j])
+		}
+
+		buf.WriteString(c.Col + " " + c.cmp() + " ?")
+		args = append(args, after[i])
+
+		if i > 0 {
+			buf.WriteByte(')')
+		}
+	}
+
+	buf.WriteByte(')')
+
+	return WhereExpr(rawExpr{
+		sql:  buf.String(),
+		args: args,
+	})
+}
+
 // Returning appends a RETURNING [column,...] clause for the given columns to
-// the Query.
+// the Query. A column that has already been returned is ignored.
 func Returning(cols ...string) Option {
+	exprs := make([]Expr, 0, len(cols))
+
+	for _, col := range cols {
+		exprs = append(exprs, Ident(col))
+	}
+	return ReturningExpr(exprs...)
+}
+
+// ReturningExpr appends a RETURNING [expression,...] clause for the given
+// expressions to the Query, for example,
+//
+//     query.ReturningExpr(query.Ident("id"), query.Raw("lower(email) AS email"))
+//
+// This will append the arguments of the given expressions to the Query too.
+func ReturningExpr(exprs ...Expr) Option {
 	return func(q Query) Query {
 		q.clauses = append(q.clauses, returningClause{
-			cols: cols,
+			exprs: exprs,
 		})
 		return q
 	}
 }
 
+// ReturningAll appends a RETURNING * clause to the Query.
+func ReturningAll() Option { return Returning("*") }
+
+// ReturningStruct appends a RETURNING clause to the Query for the columns of
+// the fields in the given struct that have a db tag, including the fields with
+// the readonly option, for example,
+//
+//     type User struct {
+//         ID        int64     `db:"id,readonly"`
+//         Email     string    `db:"email"`
+//         CreatedAt time.Time `db:"created_at,readonly"`
+//     }
+//
+//     query.InsertStruct("users", u, query.ReturningStruct(u))
+//
+// would result in the following query being built,
+//
+//     INSERT INTO users (email) VALUES ($1) RETURNING id, email, created_at
+//
+// See ReturningInto for executing the Query and scanning the returned row back
+// into the struct.
+func ReturningStruct(v interface{}) Option {
+	return func(q Query) Query {
+		fields := tags.tableFields(q.Table(), v)
+		cols := make([]string, 0, len(fields))
+
+		for _, f := range fields {
+			cols = append(cols, f.col)
+		}
+		return Returning(cols...)(q)
+	}
+}
+
 // Set appends a SET clause for the given column and expression to the Query.
-func Set(col string, expr Expr) Option {
+// This is the same as SetExpr. If a column is set more than once, then the
+// last expression is used.
+func Set(col string, expr Expr) Option { return SetExpr(col, expr) }
+
+// SetExpr appends a SET clause for the given column and expression to the
+// Query. Any placeholders within the expression are numbered along with the
+// rest of the Query, for example,
+//
+//     query.SetExpr("count", query.Raw("count + ?", 1))
+//
+// If the expression is a Query, then it will be wrapped in parentheses as a
+// subquery. The clause is dropped if the Query is not an UPDATE statement, and
+// the error is reported via Validate. If a column is set more than once, then
+// the last expression is used.
+func SetExpr(col string, expr Expr) Option {
 	return func(q Query) Query {
-		if q.stmt == _Update {
-			q.clauses = append(q.clauses, setClause{
-				col:  col,
-				expr: Lit(expr.Build()),
-			})
-			q.args = append(q.args, expr.Args()...)
+		if q.stmt != _Update {
+			q.errs = append(q.errs, fmt.Errorf("query: %w: SET in %s", ErrClause, q.stmt))
+			return q
+		}
+
+		if q1, ok := expr.(Query); ok {
+			expr = subqueryExpr{q: q1}
+		}
+
+		q.clauses = append(q.clauses, setClause{
+			col:  col,
+			expr: expr,
+		})
+		return q
+	}
+}
+
+// Incr appends a SET [column] = [column] + n clause to the Query, where n is
+// given as an argument.
+func Incr(col string, n interface{}) Option {
+	return SetExpr(col, Raw(col+" + ?", n))
+}
+
+// Decr appends a SET [column] = [column] - n clause to the Query, where n is
+// given as an argument.
+func Decr(col string, n interface{}) Option {
+	return SetExpr(col, Raw(col+" - ?", n))
+}
+
+// SetMap appends a SET clause for each column and value in the given map to the
+// Query. The columns are sorted so that the built query is deterministic. If a
+// value is an Expr then it will be used as is, otherwise it will be given as
+// an argument via Arg.
+func SetMap(m map[string]interface{}) Option {
+	cols := make([]string, 0, len(m))
+
+	for col := range m {
+		cols = append(cols, col)
+	}
+
+	sort.Strings(cols)
+
+	return func(q Query) Query {
+		for _, col := range cols {
+			q = Set(col, valueExpr(m[col]))(q)
+		}
+		return q
+	}
+}
+
+// SetStruct appends a SET clause to the Query for each field in the given
+// struct that has a db tag. Fields with the readonly option in their db tag
+// are skipped, as are zero value fields with the omitempty option. If any
+// columns are given, then only the fields for those columns will be set, this
+// allows for a field mask to be used for partial updates.
+func SetStruct(v interface{}, cols ...string) Option { return tags.SetStruct(v, cols...) }
+
+// SetStruct appends a SET clause to the Query for each field in the given
+// struct in the same way as SetStruct, only the fields are mapped via the
+// Mapper.
+func (m Mapper) SetStruct(v interface{}, cols ...string) Option {
+	var mask map[string]struct{}
+
+	if len(cols) > 0 {
+		mask = make(map[string]struct{}, len(cols))
+
+		for _, col := range cols {
+			mask[col] = struct{}{}
+		}
+	}
+
+	return func(q Query) Query {
+		for _, f := range m.tableFields(q.Table(), v) {
+			if mask != nil {
+				if _, ok := mask[f.col]; !ok {
+					continue
+				}
+			}
+
+			if f.pk || f.has("readonly") || (f.has("omitempty") && f.zero()) {
+				continue
+			}
+			q = Set(f.col, Arg(f.val.Interface()))(q)
 		}
 		return q
 	}
 }
 
+// valueExpr returns the given value as an Expr, if the value is not already an
+// Expr then it is wrapped via Arg.
+func valueExpr(val interface{}) Expr {
+	if expr, ok := val.(Expr); ok {
+		return expr
+	}
+	return Arg(val)
+}
+
+// Timestamps configures the columns that are automatically set to NOW() when
+// a row is inserted or updated.
+type Timestamps struct {
+	// CreatedAt is the column set on INSERT, defaults to created_at.
+	CreatedAt string
+
+	// UpdatedAt is the column set on UPDATE, defaults to updated_at.
+	UpdatedAt string
+
+	// Tables is the list of tables the timestamps are set for. If empty then
+	// the timestamps are set for every table.
+	Tables []string
+}
+
+// Option returns an Option that will set the CreatedAt column on an INSERT,
+// and the UpdatedAt column on an UPDATE, if the Query is for one of the
+// configured tables. The column is only set if it has not already been given
+// in the Query. This is applied once the Query is built, so it can be given
+// anywhere in the list of options.
+func (t Timestamps) Option() Option {
+	createdAt := t.CreatedAt
+
+	if createdAt == "" {
+		createdAt = "created_at"
+	}
+
+	updatedAt := t.UpdatedAt
+
+	if updatedAt == "" {
+		updatedAt = "updated_at"
+	}
+
+	return deferOpt(func(q Query) Query {
+		if len(t.Tables) > 0 {
+			found := false
+
+			for _, table := range t.Tables {
+				if table == q.table {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return q
+			}
+		}
+
+		switch q.stmt {
+		case _Insert:
+			return insertNow(createdAt)(q)
+		case _Update:
+			return updateNow(updatedAt)(q)
+		}
+		return q
+	})
+}
+
+// insertNow adds the given column to an INSERT query, setting it to NOW() for
+// each row of values.
+func insertNow(col string) Option {
+	return func(q Query) Query {
+		if len(q.exprs) == 0 {
+			return q
+		}
+
+		cols, ok := q.exprs[0].(listExpr)
+
+		if !ok {
+			return q
+		}
+
+		for _, item := range cols.items {
+			if item == col {
+				return q
+			}
+		}
+
+		cols.items = append(cols.items[:len(cols.items):len(cols.items)], col)
+
+		q.exprs = append([]Expr{cols}, q.exprs[1:]...)
+
+		clauses := make([]clause, 0, len(q.clauses))
+
+		for _, cl := range q.clauses {
+			if v, ok := cl.(valuesClause); ok {
+				v.items = append(v.items[:len(v.items):len(v.items)], "NOW()")
+				cl = v
+			}
+			clauses = append(clauses, cl)
+		}
+
+		q.clauses = clauses
+		return q
+	}
+}
+
+// updateNow adds a SET clause to an UPDATE query for the given column setting
+// it to NOW(). This is placed after the last SET clause in the query.
+func updateNow(col string) Option {
+	return func(q Query) Query {
+		for _, cl := range q.clauses {
+			if v, ok := cl.(setClause); ok && v.col == col {
+				return q
+			}
+		}
+
+		return q.insertAfter(setClause{
+			col:  col,
+			expr: Lit("NOW()"),
+		}, _SetClause)
+	}
+}
+
+// NotDeleted appends a WHERE deleted_at IS NULL clause to the Query, for
+// excluding soft deleted rows. This is applied once the Query is built, and
+// will be placed after any other WHERE clauses and conjoined with AND, so it
+// applies to the query as a whole, with any OR clauses grouped in parentheses.
+// If the table of the Query was registered via RegisterTable, then its
+// SoftDelete column is checked instead, and if it has none, then no clause is
+// appended.
+func NotDeleted() Option {
+	return deferOpt(func(q Query) Query {
+		col, ok := softDeleteCol(q.Table())
+
+		if !ok {
+			return q
+		}
+
+		return q.insertAfter(whereClause{
+			conjunction: "AND",
+			op:          "IS",
+			left:        Ident(col),
+			right:       Lit("NULL"),
+			filter:      true,
+		}, _FromClause, _WhereClause)
+	})
+}
+
+// OnConflictDoNothing appends an ON CONFLICT [(column,...)] DO NOTHING clause
+// to the Query. For MySQL this will build an INSERT IGNORE query, and for
+// SQLite an INSERT OR IGNORE query. If an ON CONFLICT clause is given more than
+// once, then the last clause is used.
+func OnConflictDoNothing(cols ...string) Option {
+	return func(q Query) Query {
+		q.clauses = append(q.clauses, conflictClause{
+			cols: cols,
+		})
+		return q
+	}
+}
+
+// OnConflictUpdate appends an ON CONFLICT (column,...) DO UPDATE SET clause to
+// the Query, which will update each of the given update columns to the value
+// that would have been inserted. For MySQL this will build an ON DUPLICATE KEY
+// UPDATE clause instead, and the conflict columns are ignored. If an ON
+// CONFLICT clause is given more than once, then the last clause is used.
+func OnConflictUpdate(cols []string, update ...string) Option {
+	return func(q Query) Query {
+		q.clauses = append(q.clauses, conflictClause{
+			cols:   cols,
+			update: update,
+		})
+		return q
+	}
+}
+
 // Values appends a VALUES clause for the given values to the Query. Each
 // given value will use the ? placeholder when built.
 func Values(vals ...interface{}) Option {
@@ -161,11 +816,140 @@ func Values(vals ...interface{}) Option {
 			items: items,
 			args:  vals,
 		})
-		q.args = append(q.args, vals...)
 		return q
 	}
 }
 
+// ValuesRows appends a VALUES clause to the Query for each of the given rows,
+// for example,
+//
+//     q := query.Insert(
+//         "users",
+//         query.Columns("email", "username"),
+//         query.ValuesRows([][]interface{}{
+//             {"me@example.com", "me"},
+//             {"you@example.com", "you"},
+//         }),
+//     )
+//
+// would result in the following query being built,
+//
+//     INSERT INTO users (email, username) VALUES ($1, $2), ($3, $4)
+//
+// Each row must have a value for each of the columns of the INSERT statement,
+// or if there are no columns then the same number of values as the first row.
+// If a row does not then none of the rows are appended, and ErrColumns is
+// recorded for the row and returned by Err.
+func ValuesRows(rows [][]interface{}) Option {
+	return func(q Query) Query {
+		n := -1
+
+		if cols, ok := q.insertCols(); ok {
+			n = len(cols.items)
+		}
+
+		for i, row := range rows {
+			if n < 0 {
+				n = len(row)
+			}
+
+			if len(row) != n {
+				q.errs = append(q.errs, fmt.Errorf("query: %w: row %d has %d values, expected %d", ErrColumns, i, len(row), n))
+				return q
+			}
+		}
+
+		for _, row := range rows {
+			q = Values(row...)(q)
+		}
+		return q
+	}
+}
+
+type conflictClause struct {
+	cols   []string
+	update []string
+}
+
+var _ clause = (*conflictClause)(nil)
+
+func (c conflictClause) Args() []interface{} { return nil }
+func (c conflictClause) Build() string       { return c.buildFor(dialectOr(nil)) }
+func (c conflictClause) kind() clauseKind    { return _ConflictClause }
+
+func (c conflictClause) buildFor(d *Dialect) string {
+	if d.duplicateKey {
+		if len(c.update) == 0 {
+			return ""
+		}
+
+		sets := make([]string, 0, len(c.update))
+
+		for _, col := range d.idents(c.update) {
+			sets = append(sets, col+" = VALUES("+col+")")
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+
+	var buf strings.Builder
+
+	buf.WriteString("ON CONFLICT ")
+
+	if len(c.cols) > 0 {
+		buf.WriteString("(" + strings.Join(d.idents(c.cols), ", ") + ") ")
+	}
+
+	if len(c.update) == 0 {
+		buf.WriteString("DO NOTHING")
+		return buf.String()
+	}
+
+	sets := make([]string, 0, len(c.update))
+
+	for _, col := range d.idents(c.update) {
+		sets = append(sets, col+" = EXCLUDED."+col)
+	}
+
+	buf.WriteString("DO UPDATE SET " + strings.Join(sets, ", "))
+	return buf.String()
+}
+
+// fetchClause is used in place of LIMIT and OFFSET for the dialects that only
+// support OFFSET ... FETCH.
+type fetchClause struct {
+	offset int64
+	limit  int64
+	fetch  bool
+	first  bool
+}
+
+var _ clause = (*fetchClause)(nil)
+
+func (c fetchClause) Args() []interface{} { return nil }
+func (c fetchClause) kind() clauseKind    { return _FetchClause }
+
+func (c fetchClause) Build() string {
+	var buf strings.Builder
+
+	if c.offset > 0 || !c.first {
+		buf.WriteString("OFFSET " + strconv.FormatInt(c.offset, 10) + " ROWS")
+	}
+
+	if c.fetch {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+
+		next := "NEXT"
+
+		if c.first {
+			next = "FIRST"
+		}
+		buf.WriteString("FETCH " + next + " " + strconv.FormatInt(c.limit, 10) + " ROWS ONLY")
+	}
+	return buf.String()
+}
+
 type fromClause struct {
 	table string
 }
@@ -176,6 +960,20 @@ func (c fromClause) Args() []interface{} { return nil }
 func (c fromClause) Build() string       { return c.table }
 func (c fromClause) kind() clauseKind    { return _FromClause }
 
+func (c fromClause) buildFor(d *Dialect) string { return d.ident(c.table) }
+
+type groupClause struct {
+	cols []string
+}
+
+var _ clause = (*groupClause)(nil)
+
+func (c groupClause) Args() []interface{} { return nil }
+func (c groupClause) Build() string       { return c.buildFor(dialectOr(nil)) }
+func (c groupClause) kind() clauseKind    { return _GroupClause }
+
+func (c groupClause) buildFor(d *Dialect) string { return strings.Join(d.idents(c.cols), ", ") }
+
 type limitClause int64
 
 var _ clause = (*limitClause)(nil)
@@ -200,18 +998,28 @@ type orderClause struct {
 var _ clause = (*orderClause)(nil)
 
 func (c orderClause) Args() []interface{} { return nil }
-func (c orderClause) Build() string       { return strings.Join(c.cols, ", ") + " " + c.dir }
+func (c orderClause) Build() string       { return c.buildFor(dialectOr(nil)) }
 func (c orderClause) kind() clauseKind    { return _OrderClause }
 
+func (c orderClause) buildFor(d *Dialect) string {
+	cols := d.idents(c.cols)
+
+	if c.dir == "" {
+		return strings.Join(cols, ", ")
+	}
+	return strings.Join(cols, ", ") + " " + c.dir
+}
+
 type returningClause struct {
-	cols []string
+	exprs []Expr
 }
 
 var _ clause = (*returningClause)(nil)
 
-func (c returningClause) Args() []interface{} { return nil }
-func (c returningClause) Build() string       { return strings.Join(c.cols, ", ") }
-func (c returningClause) kind() clauseKind    { return _ReturningClause }
+func (c returningClause) Args() []interface{}   { return sqlArgs(c, nil) }
+func (c returningClause) Build() string         { return buildSQL(c, nil) }
+func (c returningClause) kind() clauseKind      { return _ReturningClause }
+func (c returningClause) WriteSQL(w *SQLWriter) { w.WriteExprs(", ", c.exprs...) }
 
 type setClause struct {
 	col  string
@@ -220,19 +1028,26 @@ type setClause struct {
 
 var _ clause = (*setClause)(nil)
 
-func (c setClause) Args() []interface{} { return nil }
-func (c setClause) Build() string       { return c.col + " = " + c.expr.Build() }
+func (c setClause) Args() []interface{} { return sqlArgs(c, nil) }
+func (c setClause) Build() string       { return buildSQL(c, nil) }
 func (c setClause) kind() clauseKind    { return _SetClause }
 
+func (c setClause) WriteSQL(w *SQLWriter) {
+	w.WriteIdent(c.col)
+	w.WriteString(" = ")
+	w.WriteExpr(c.expr)
+}
+
 type unionClause struct {
 	q Query
 }
 
 var _ clause = (*unionClause)(nil)
 
-func (c unionClause) Args() []interface{}  { return nil }
-func (c unionClause) Build() string        { return c.q.buildInitial() }
-func (c unionClause) kind() clauseKind     { return _UnionClause }
+func (c unionClause) Args() []interface{}   { return c.q.Args() }
+func (c unionClause) Build() string         { return c.q.buildInitial() }
+func (c unionClause) kind() clauseKind      { return _UnionClause }
+func (c unionClause) WriteSQL(w *SQLWriter) { w.WriteExpr(c.q) }
 
 type valuesClause struct {
 	items []string
@@ -250,14 +1065,148 @@ type whereClause struct {
 	op          string
 	left        Expr
 	right       Expr
+
+	// filter is set for a clause that must apply to the query as a whole,
+	// such as the one appended by ForTenant.
+	filter bool
 }
 
 var _ clause = (*whereClause)(nil)
 
-func (c whereClause) Args() []interface{} { return nil }
+func (c whereClause) Args() []interface{} { return sqlArgs(c, nil) }
+func (c whereClause) Build() string       { return buildSQL(c, nil) }
+func (c whereClause) kind() clauseKind    { return _WhereClause }
 
-func (c whereClause) Build() string {
-	return c.left.Build() + " " + c.op + " " + c.right.Build()
+func (c whereClause) WriteSQL(w *SQLWriter) {
+	if pred, ok := c.emptyIn(); ok {
+		w.WriteString(pred)
+		return
+	}
+
+	if c.left != nil {
+		w.WriteExpr(c.left)
+		w.WriteByte(' ')
+		w.WriteString(c.op)
+		w.WriteByte(' ')
+	} else if c.op != "" {
+		w.WriteString(c.op)
+		w.WriteByte(' ')
+	}
+	w.WriteExpr(c.right)
+}
+
+// whereGroup is a group of WHERE clauses that is written in parentheses, so it
+// can be conjoined with another clause as a single predicate.
+type whereGroup struct {
+	clauses []whereClause
+}
+
+var _ SQLExpr = (*whereGroup)(nil)
+
+func (e whereGroup) Args() []interface{} { return sqlArgs(e, nil) }
+func (e whereGroup) Build() string       { return buildSQL(e, nil) }
+
+// WriteSQL writes the clauses of the group, wrapping each run of clauses with
+// the same conjunction in parentheses in the same way as a Query does. The
+// group as a whole is wrapped too if it has more than one run of clauses.
+func (e whereGroup) WriteSQL(w *SQLWriter) {
+	runs := false
+
+	for i := 2; i < len(e.clauses); i++ {
+		if e.clauses[i].conjunction != e.clauses[i-1].conjunction {
+			runs = true
+		}
+	}
+
+	if runs {
+		w.WriteByte('(')
+	}
+	w.WriteByte('(')
+
+	for i, cl := range e.clauses {
+		if i > 0 {
+			conj := " " + cl.conjunction + " "
+
+			if i > 1 && cl.conjunction != e.clauses[i-1].conjunction {
+				conj = ")" + conj + "("
+			}
+			w.WriteString(conj)
+		}
+		w.WriteExpr(cl)
+	}
+
+	w.WriteByte(')')
+
+	if runs {
+		w.WriteByte(')')
+	}
+}
+
+// grouped returns the Query with its WHERE clauses grouped in parentheses, if
+// any of them are conjoined with OR and the Query has a filter clause. This
+// makes the filter apply to every row the other clauses match, rather than
+// only to the last run of clauses conjoined with AND.
+func (q Query) grouped() Query {
+	var (
+		preds   []whereClause
+		filters bool
+		or      bool
+	)
+
+	for _, cl := range q.clauses {
+		if v, ok := cl.(whereClause); ok {
+			if v.filter {
+				filters = true
+				continue
+			}
+
+			if len(preds) > 0 && v.conjunction != "AND" {
+				or = true
+			}
+			preds = append(preds, v)
+		}
+	}
+
+	if !filters || !or {
+		return q
+	}
+
+	clauses := make([]clause, 0, len(q.clauses)-len(preds)+1)
+	group := false
+
+	for _, cl := range q.clauses {
+		if v, ok := cl.(whereClause); ok && !v.filter {
+			if !group {
+				group = true
+				clauses = append(clauses, whereClause{
+					conjunction: "AND",
+					right:       whereGroup{clauses: preds},
+				})
+			}
+			continue
+		}
+		clauses = append(clauses, cl)
+	}
+
+	q.clauses = clauses
+	return q
 }
 
-func (c whereClause) kind() clauseKind { return _WhereClause }
+// emptyIn returns the predicate to use in place of an IN, or NOT IN clause for
+// an empty list, since IN () is not valid SQL. An empty IN is always false,
+// and an empty NOT IN is always true.
+func (c whereClause) emptyIn() (string, bool) {
+	list, ok := c.right.(listExpr)
+
+	if !ok || !list.wrap || len(list.items) > 0 || c.left == nil {
+		return "", false
+	}
+
+	switch strings.ToUpper(c.op) {
+	case "IN":
+		return "1 = 0", true
+	case "NOT IN":
+		return "1 = 1", true
+	}
+	return "", false
+}
@@ -17,11 +17,14 @@ func _() {
 	_ = x[_WhereClause-6]
 	_ = x[_ReturningClause-7]
 	_ = x[_SetClause-8]
+	_ = x[_ConflictClause-9]
+	_ = x[_FetchClause-10]
+	_ = x[_GroupClause-11]
 }
 
-const _clauseKind_name = "FROMLIMITOFFSETORDER BYUNIONVALUESWHERERETURNINGSET"
+const _clauseKind_name = "FROMLIMITOFFSETORDER BYUNIONVALUESWHERERETURNINGSETON CONFLICTFETCHGROUP BY"
 
-var _clauseKind_index = [...]uint8{0, 4, 9, 15, 23, 28, 34, 39, 48, 51}
+var _clauseKind_index = [...]uint8{0, 4, 9, 15, 23, 28, 34, 39, 48, 51, 62, 67, 75}
 
 func (i clauseKind) String() string {
 	if i >= clauseKind(len(_clauseKind_index)-1) {
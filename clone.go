@@ -0,0 +1,125 @@
+package query
+
+// Clone returns a deep copy of the Query. The expressions and clauses of the
+// Query, along with the slices of arguments they hold, are copied, so the
+// returned Query shares no memory with the original. This allows for a base
+// Query to be specialized into multiple variants, for example,
+//
+//     base := query.Select(query.Columns("*"), query.From("posts"), query.Where("user_id", "=", query.Arg(id)))
+//
+//     page := query.Options(query.Limit(25), query.Offset(50))(base.Clone())
+//     export := query.OrderAsc("id")(base.Clone())
+//
+// The values of the arguments themselves are not copied, so an argument that
+// is a pointer or a slice will still refer to the same memory.
+func (q Query) Clone() Query {
+	if q.exprs != nil {
+		exprs := make([]Expr, 0, len(q.exprs))
+
+		for _, expr := range q.exprs {
+			exprs = append(exprs, cloneExpr(expr))
+		}
+		q.exprs = exprs
+	}
+
+	if q.clauses != nil {
+		clauses := make([]clause, 0, len(q.clauses))
+
+		for _, cl := range q.clauses {
+			clauses = append(clauses, cloneExpr(cl).(clause))
+		}
+		q.clauses = clauses
+	}
+
+	q.defers = cloneSlice(q.defers)
+	q.errs = cloneSlice(q.errs)
+	return q
+}
+
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+func cloneExprs(exprs []Expr) []Expr {
+	if exprs == nil {
+		return nil
+	}
+
+	cloned := make([]Expr, 0, len(exprs))
+
+	for _, expr := range exprs {
+		cloned = append(cloned, cloneExpr(expr))
+	}
+	return cloned
+}
+
+// cloneExpr returns a deep copy of the given expression. Expressions that hold
+// no slices are returned as is.
+func cloneExpr(e Expr) Expr {
+	switch v := e.(type) {
+	case Query:
+		return v.Clone()
+	case subqueryExpr:
+		v.q = v.q.Clone()
+		return v
+	case explainExpr:
+		v.q = v.q.Clone()
+		v.opts = cloneSlice(v.opts)
+		return v
+	case listExpr:
+		v.items = cloneSlice(v.items)
+		v.args = cloneSlice(v.args)
+		return v
+	case rawExpr:
+		v.args = cloneSlice(v.args)
+		return v
+	case callExpr:
+		v.args = cloneExprs(v.args)
+		return v
+	case joinExpr:
+		v.exprs = cloneExprs(v.exprs)
+		return v
+	case whereClause:
+		if v.left != nil {
+			v.left = cloneExpr(v.left)
+		}
+		v.right = cloneExpr(v.right)
+		return v
+	case setClause:
+		v.expr = cloneExpr(v.expr)
+		return v
+	case orderClause:
+		v.cols = cloneSlice(v.cols)
+		return v
+	case groupClause:
+		v.cols = cloneSlice(v.cols)
+		return v
+	case returningClause:
+		v.exprs = cloneExprs(v.exprs)
+		return v
+	case conflictClause:
+		v.cols = cloneSlice(v.cols)
+		v.update = cloneSlice(v.update)
+		return v
+	case valuesClause:
+		v.items = cloneSlice(v.items)
+		v.args = cloneSlice(v.args)
+		return v
+	case unionClause:
+		v.q = v.q.Clone()
+		return v
+	case fromQueryClause:
+		v.q = v.q.Clone()
+		return v
+	case customClause:
+		v.cl = cloneExpr(v.cl).(CustomClause)
+		return v
+	case kindExpr:
+		v.expr = cloneExpr(v.expr)
+		return v
+	}
+	return e
+}
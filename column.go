@@ -0,0 +1,102 @@
+package query
+
+// Column is a column whose values are of type T. The predicates returned from
+// the methods of a Column only accept values of type T, so the values given
+// for a column are checked at compile time. A Column can be declared by hand,
+// or generated via querygen, for example,
+//
+//     var (
+//         PostID     = query.Column[int64]("id")
+//         PostUserID = query.Column[int64]("user_id")
+//         PostStatus = query.Column[string]("status")
+//     )
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.WhereExpr(PostUserID.Eq(10)),
+//         query.WhereExpr(PostStatus.In("draft", "review")),
+//     )
+//
+// would result in the following query being built,
+//
+//     SELECT * FROM posts WHERE (user_id = $1 AND status IN ($2, $3))
+//
+// A Column is an Expr itself, so it can be used anywhere an identifier can be
+// used.
+type Column[T any] string
+
+// Name returns the name of the column.
+func (c Column[T]) Name() string { return string(c) }
+
+func (c Column[T]) Args() []interface{}        { return nil }
+func (c Column[T]) Build() string              { return string(c) }
+func (c Column[T]) buildFor(d *Dialect) string { return d.ident(string(c)) }
+
+// pred returns the predicate for the column with the given operator and
+// right-hand expression.
+func (c Column[T]) pred(op string, right ...Expr) joinExpr {
+	exprs := make([]Expr, 0, len(right)+2)
+	exprs = append(exprs, Ident(string(c)), Lit(op))
+
+	return joinExpr{
+		sep:   " ",
+		exprs: append(exprs, right...),
+	}
+}
+
+// Eq returns the predicate that the column is equal to the given value.
+func (c Column[T]) Eq(v T) joinExpr { return c.pred("=", Arg(v)) }
+
+// Ne returns the predicate that the column is not equal to the given value.
+func (c Column[T]) Ne(v T) joinExpr { return c.pred("!=", Arg(v)) }
+
+// Lt returns the predicate that the column is less than the given value.
+func (c Column[T]) Lt(v T) joinExpr { return c.pred("<", Arg(v)) }
+
+// Le returns the predicate that the column is less than or equal to the given
+// value.
+func (c Column[T]) Le(v T) joinExpr { return c.pred("<=", Arg(v)) }
+
+// Gt returns the predicate that the column is greater than the given value.
+func (c Column[T]) Gt(v T) joinExpr { return c.pred(">", Arg(v)) }
+
+// Ge returns the predicate that the column is greater than or equal to the
+// given value.
+func (c Column[T]) Ge(v T) joinExpr { return c.pred(">=", Arg(v)) }
+
+func columnList[T any](vs []T) listExpr {
+	vals := make([]interface{}, 0, len(vs))
+
+	for _, v := range vs {
+		vals = append(vals, v)
+	}
+	return List(vals...)
+}
+
+// In returns the predicate that the column is one of the given values. If no
+// values are given then the predicate is 1 = 0, which is always false.
+func (c Column[T]) In(vs ...T) joinExpr {
+	if len(vs) == 0 {
+		return joinExpr{exprs: []Expr{Lit("1 = 0")}}
+	}
+	return c.pred("IN", columnList(vs))
+}
+
+// NotIn returns the predicate that the column is none of the given values. If
+// no values are given then the predicate is 1 = 1, which is always true.
+func (c Column[T]) NotIn(vs ...T) joinExpr {
+	if len(vs) == 0 {
+		return joinExpr{exprs: []Expr{Lit("1 = 1")}}
+	}
+	return c.pred("NOT IN", columnList(vs))
+}
+
+// IsNull returns the predicate that the column is NULL.
+func (c Column[T]) IsNull() joinExpr { return c.pred("IS NULL") }
+
+// IsNotNull returns the predicate that the column is not NULL.
+func (c Column[T]) IsNotNull() joinExpr { return c.pred("IS NOT NULL") }
+
+// Set returns a SET clause that sets the column to the given value.
+func (c Column[T]) Set(v T) Option { return Set(string(c), Arg(v)) }
@@ -0,0 +1,220 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// joinExpr is the expression for the fragments joined via Append.
+type joinExpr struct {
+	sep   string
+	exprs []Expr
+}
+
+var _ Expr = (*joinExpr)(nil)
+
+// Numbered returns a raw expression for the given SQL that uses numbered
+// placeholders, such as $1, along with the arguments for those placeholders.
+// Each placeholder is replaced with ?, and the arguments are reordered to
+// match, so the SQL can be combined with other expressions and renumbered when
+// the Query is built. A placeholder can be used more than once, for example,
+//
+//     query.Numbered("SELECT * FROM posts WHERE user_id = $1 OR author_id = $1", 10)
+//
+// Placeholders within single quoted strings and double quoted identifiers are
+// left as they are. This panics if a placeholder refers to an argument that
+// was not given.
+func Numbered(sql string, args ...interface{}) rawExpr {
+	var (
+		buf  strings.Builder
+		vals []interface{}
+	)
+
+	buf.Grow(len(sql))
+
+	var quote byte
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			buf.WriteByte(c)
+			continue
+		}
+
+		if c == '\'' || c == '"' {
+			quote = c
+			buf.WriteByte(c)
+			continue
+		}
+
+		if c != '$' || i+1 >= len(sql) || sql[i+1] < '0' || sql[i+1] > '9' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		j := i + 1
+
+		for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+			j++
+		}
+
+		n, _ := strconv.Atoi(sql[i+1 : j])
+
+		if n < 1 || n > len(args) {
+			panic("query: Numbered placeholder $" + sql[i+1:j] + " out of range for " + strconv.Itoa(len(args)) + " arguments")
+		}
+
+		buf.WriteByte('?')
+		vals = append(vals, args[n-1])
+
+		i = j - 1
+	}
+
+	return rawExpr{
+		sql:  buf.String(),
+		args: vals,
+	}
+}
+
+// Append returns a Query that joins the given expressions with the given
+// separator. The placeholders of each expression are numbered along with the
+// rest of the Query when it is built, and the arguments of each expression are
+// merged in order. This allows for hand written SQL to be combined with queries
+// made via the builder, for example,
+//
+//     q := query.Append(" ",
+//         query.Numbered("WITH recent AS (SELECT * FROM posts WHERE created_at > $1)", since),
+//         query.Select(query.Columns("*"), query.From("recent"), query.Where("user_id", "=", query.Arg(10))),
+//     )
+//
+// would result in the following query being built,
+//
+//     WITH recent AS (SELECT * FROM posts WHERE created_at > $1) SELECT * FROM recent WHERE (user_id = $2)
+//
+// The returned Query can be given options such as WithDialect, but it cannot
+// be given any clauses.
+func Append(sep string, exprs ...Expr) Query {
+	q := Query{
+		exprs: []Expr{joinExpr{sep: sep, exprs: exprs}},
+	}
+	return q.apply(nil)
+}
+
+func (e joinExpr) Args() []interface{} {
+	_, args := writeSQL(e, dialectOr(nil))
+	return args
+}
+
+func (e joinExpr) Build() string {
+	s, _ := writeSQL(e, dialectOr(nil))
+	return s
+}
+
+func (e joinExpr) WriteSQL(w *SQLWriter) { w.WriteExprs(e.sep, e.exprs...) }
+
+// Format returns a Query for the given format, in the same vein as
+// fmt.Sprintf, where the verbs distinguish between identifiers and arguments.
+// This allows for one-off queries to be written by hand without building
+// them up via options, while still keeping the values out of the SQL, for
+// example,
+//
+//     query.Format("SELECT * FROM %s WHERE id = %a AND status IN %a", query.Ident("users"), 10, query.List("active", "pending"))
+//
+// would result in the following query being built,
+//
+//     SELECT * FROM "users" WHERE id = $1 AND status IN ($2, $3)
+//
+// The verbs are,
+//
+//     %s  an identifier, given as a string, or via Ident or Quote, this is always
+//         quoted for the Dialect of the Query
+//     %a  an argument, this is bound via a placeholder, unless it is an Expr
+//         such as List or a subquery, which is written as is
+//     %%  a literal percent sign
+//
+// The rest of the format is placed into the built up query as is, so it should
+// never contain any user input. This panics if the format uses an unknown
+// verb, or if the number of values does not match the number of verbs.
+func Format(format string, vals ...interface{}) Query {
+	var (
+		buf   strings.Builder
+		exprs []Expr
+		n     int
+	)
+
+	flush := func() {
+		if buf.Len() > 0 {
+			exprs = append(exprs, rawExpr{sql: buf.String()})
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+
+		if c != '%' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		i++
+
+		if i >= len(format) {
+			panic("query: Format missing verb at end of format")
+		}
+
+		verb := format[i]
+
+		if verb == '%' {
+			buf.WriteByte('%')
+			continue
+		}
+
+		if n >= len(vals) {
+			panic("query: Format missing value for %" + string(verb))
+		}
+
+		val := vals[n]
+		n++
+
+		flush()
+
+		switch verb {
+		case 's':
+			switch v := val.(type) {
+			case string:
+				exprs = append(exprs, quoteExpr(v))
+			case identExpr:
+				exprs = append(exprs, quoteExpr(v))
+			case quoteExpr:
+				exprs = append(exprs, v)
+			default:
+				panic(fmt.Sprintf("query: Format cannot use %T as identifier", val))
+			}
+		case 'a':
+			switch v := val.(type) {
+			case Query:
+				exprs = append(exprs, subqueryExpr{q: v})
+			case Expr:
+				exprs = append(exprs, v)
+			default:
+				exprs = append(exprs, Arg(v))
+			}
+		default:
+			panic("query: Format unknown verb %" + string(verb))
+		}
+	}
+
+	if n != len(vals) {
+		panic("query: Format expected " + strconv.Itoa(n) + " values, got " + strconv.Itoa(len(vals)))
+	}
+
+	flush()
+
+	return Append("", exprs...)
+}
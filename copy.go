@@ -0,0 +1,82 @@
+package query
+
+import (
+	"context"
+	"strings"
+)
+
+// Copy is a COPY FROM STDIN statement for bulk loading rows into a table in
+// PostgreSQL. This is typically much faster than inserting the same rows via
+// multiple INSERT statements.
+type Copy struct {
+	table string
+	cols  []string
+}
+
+// CopyIn returns a COPY statement for loading rows into the given columns of
+// the given table. When built, the table and columns are quoted in the same
+// way as lib/pq's CopyIn, so the built statement can be prepared directly on a
+// transaction that uses lib/pq as its driver, for example,
+//
+//     c := query.CopyIn("users", "email", "username")
+//
+//     n, err := c.Exec(ctx, tx, rows)
+//
+// For pgx, see the CopyFrom function in the querypgx package.
+func CopyIn(table string, cols ...string) Copy {
+	return Copy{
+		table: table,
+		cols:  cols,
+	}
+}
+
+// Table returns the table that the rows will be copied into.
+func (c Copy) Table() string { return c.table }
+
+// Columns returns the columns that the rows will be copied into.
+func (c Copy) Columns() []string { return c.cols }
+
+// Build builds up the COPY statement, for example,
+//
+//     COPY "users" ("email", "username") FROM STDIN
+func (c Copy) Build() string {
+	var buf strings.Builder
+
+	buf.WriteString("COPY " + Postgres.Quote(c.table) + " (")
+
+	for i, col := range c.cols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(Postgres.Quote(col))
+	}
+
+	buf.WriteString(") FROM STDIN")
+	return buf.String()
+}
+
+// Exec copies the given rows via the given Preparer. This prepares the COPY
+// statement, executes the statement for each of the rows, and then executes
+// the statement without any arguments to flush the rows, as expected by
+// lib/pq. With lib/pq the given Preparer must be a *sql.Tx. The number of rows
+// copied is returned.
+func (c Copy) Exec(ctx context.Context, db Preparer, rows [][]interface{}) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, c.Build())
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, err
+	}
+	return int64(len(rows)), nil
+}
@@ -0,0 +1,117 @@
+package query
+
+import "strings"
+
+// fromQueryClause is a FROM clause for a subquery, this is aliased since
+// PostgreSQL requires every subquery in a FROM clause to have an alias.
+type fromQueryClause struct {
+	q     Query
+	alias string
+}
+
+var _ clause = (*fromQueryClause)(nil)
+
+func (c fromQueryClause) Args() []interface{} { return c.q.Args() }
+func (c fromQueryClause) Build() string       { return buildSQL(c, nil) }
+func (c fromQueryClause) kind() clauseKind    { return _FromClause }
+
+func (c fromQueryClause) WriteSQL(w *SQLWriter) {
+	w.WriteSubquery(c.q)
+	w.WriteString(" AS ")
+	w.WriteIdent(c.alias)
+}
+
+// CountOf returns a Query that counts the rows the given SELECT query would
+// return. The ORDER BY, LIMIT, and OFFSET clauses of the query are removed, and
+// the columns are replaced with COUNT(*), for example,
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(10)),
+//         query.OrderDesc("created_at"),
+//         query.Limit(25),
+//     )
+//
+//     query.CountOf(q)
+//
+// would result in the following query being built,
+//
+//     SELECT COUNT(*) FROM posts WHERE (user_id = $1)
+//
+// If the query is a SELECT DISTINCT, has a GROUP BY, or is a UNION, then the
+// query is counted as a subquery instead, since replacing its columns would
+// change the number of rows it returns,
+//
+//     SELECT COUNT(*) FROM (SELECT DISTINCT user_id FROM posts) AS count
+//
+// This allows for the total number of rows to be shown alongside a page of
+// results, without duplicating the WHERE clauses of the query.
+//
+// If the table of the query was registered via RegisterTable with a
+// SoftDelete column, then soft deleted rows are not counted, as if the query
+// was given NotDeleted, unless the query already has a WHERE clause on that
+// column. A table with a Tenant column is counted for the tenant the query is
+// scoped to, so the query must be given ForTenant, or AllTenants, as it would
+// be when built.
+func CountOf(q Query) Query {
+	q = q.finalize()
+
+	if def, ok := LookupTable(q.Table()); ok && def.SoftDelete != "" && !q.whereCol(def.SoftDelete) {
+		q = NotDeleted()(q).finalize()
+	}
+
+	clauses := make([]clause, 0, len(q.clauses))
+	grouped := false
+
+	for _, cl := range q.clauses {
+		switch cl.kind() {
+		case _OrderClause, _LimitClause, _OffsetClause, _FetchClause:
+			continue
+		case _GroupClause:
+			grouped = true
+		}
+		clauses = append(clauses, cl)
+	}
+
+	q.clauses = clauses
+
+	if q.stmt == _Select && !grouped {
+		q.exprs = []Expr{Count("*")}
+		return q.clip()
+	}
+
+	return Query{
+		stmt:    _Select,
+		exprs:   []Expr{Count("*")},
+		clauses: []clause{fromQueryClause{q: q, alias: "count"}},
+		dialect: q.dialect,
+		quote:   q.quote,
+		dedupe:  q.dedupe,
+	}
+}
+
+// whereCol reports whether the Query has a WHERE clause on the given column,
+// either qualified or not.
+func (q Query) whereCol(col string) bool {
+	for _, cl := range q.clauses {
+		v, ok := cl.(whereClause)
+
+		if !ok {
+			continue
+		}
+
+		if id, ok := v.left.(identExpr); ok {
+			name := string(id)
+
+			if i := strings.LastIndexByte(name, '.'); i >= 0 {
+				name = name[i+1:]
+			}
+
+			if name == col {
+				return true
+			}
+		}
+	}
+	return false
+}
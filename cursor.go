@@ -0,0 +1,165 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Cursor is a server-side cursor in PostgreSQL for a Query. This allows for the
+// rows of a large query to be fetched in batches, rather than all at once, for
+// example,
+//
+//     c := query.DeclareCursor("export", q)
+//
+//     tx.ExecContext(ctx, c.Declare(), c.Args()...)
+//
+//     rows, err := tx.QueryContext(ctx, c.Fetch(1000))
+//
+//     tx.ExecContext(ctx, c.Close())
+//
+// Unless WithHold is given, a cursor can only be used within the transaction
+// it was declared in.
+type Cursor struct {
+	name   string
+	q      Query
+	scroll string
+	hold   bool
+}
+
+// CursorOption is the type for the first-class functions that set the options
+// of a Cursor when it is declared.
+type CursorOption func(c Cursor) Cursor
+
+// DeclareCursor returns a Cursor with the given name for the given Query,
+// applying the given options.
+func DeclareCursor(name string, q Query, opts ...CursorOption) Cursor {
+	c := Cursor{
+		name: name,
+		q:    q,
+	}
+
+	for _, opt := range opts {
+		c = opt(c)
+	}
+	return c
+}
+
+// Scroll allows for the cursor to fetch rows backwards as well as forwards.
+func Scroll() CursorOption {
+	return func(c Cursor) Cursor {
+		c.scroll = "SCROLL"
+		return c
+	}
+}
+
+// NoScroll only allows for the cursor to fetch rows forwards.
+func NoScroll() CursorOption {
+	return func(c Cursor) Cursor {
+		c.scroll = "NO SCROLL"
+		return c
+	}
+}
+
+// WithHold allows for the cursor to be used after the transaction it was
+// declared in has been committed.
+func WithHold() CursorOption {
+	return func(c Cursor) Cursor {
+		c.hold = true
+		return c
+	}
+}
+
+// Name returns the name of the cursor.
+func (c Cursor) Name() string { return c.name }
+
+// Args returns the arguments of the cursor's Query, these should be given when
+// the cursor is declared.
+func (c Cursor) Args() []interface{} { return c.q.Args() }
+
+// Declare builds up the DECLARE statement for the cursor, for example,
+//
+//     DECLARE "export" NO SCROLL CURSOR WITH HOLD FOR SELECT * FROM posts
+func (c Cursor) Declare() string {
+	var buf strings.Builder
+
+	buf.WriteString("DECLARE " + Postgres.Quote(c.name) + " ")
+
+	if c.scroll != "" {
+		buf.WriteString(c.scroll + " ")
+	}
+
+	buf.WriteString("CURSOR ")
+
+	if c.hold {
+		buf.WriteString("WITH HOLD ")
+	}
+
+	buf.WriteString("FOR " + c.q.Build())
+	return buf.String()
+}
+
+// Fetch builds up the FETCH statement for fetching the next n rows from the
+// cursor.
+func (c Cursor) Fetch(n int) string {
+	return "FETCH FORWARD " + strconv.Itoa(n) + " FROM " + Postgres.Quote(c.name)
+}
+
+// Close builds up the CLOSE statement for the cursor.
+func (c Cursor) Close() string { return "CLOSE " + Postgres.Quote(c.name) }
+
+// Each declares the cursor on the given database, and fetches the rows from
+// the cursor in batches of n, calling the given function for each row. Once
+// all of the rows have been fetched, or if an error occurs, the cursor is
+// closed. The given database should be a *sql.Tx, unless the cursor was
+// declared WithHold.
+func (c Cursor) Each(ctx context.Context, db Execer, n int, fn func(rows *sql.Rows) error) error {
+	if _, err := db.ExecContext(ctx, c.Declare(), c.Args()...); err != nil {
+		return err
+	}
+
+	if err := c.each(ctx, db, n, fn); err != nil {
+		db.ExecContext(ctx, c.Close())
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, c.Close())
+	return err
+}
+
+func (c Cursor) each(ctx context.Context, db Execer, n int, fn func(rows *sql.Rows) error) error {
+	fetch := c.Fetch(n)
+
+	for {
+		rows, err := db.QueryContext(ctx, fetch)
+
+		if err != nil {
+			return err
+		}
+
+		fetched := 0
+
+		for rows.Next() {
+			fetched++
+
+			if err := fn(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		if fetched < n {
+			return nil
+		}
+	}
+}
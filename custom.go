@@ -0,0 +1,183 @@
+package query
+
+import (
+	"strings"
+	"sync"
+)
+
+// customKind is the first clause kind given to a clause registered via
+// RegisterClause. This leaves room for the kinds of clauses in the package.
+const customKind clauseKind = 64
+
+// customDef is the definition of a clause kind registered via RegisterClause.
+type customDef struct {
+	keyword string
+	before  []clauseKind
+}
+
+var (
+	customMu   sync.RWMutex
+	customDefs = make(map[clauseKind]customDef)
+	customNext = customKind
+)
+
+// ClauseKind is a kind of clause registered via RegisterClause.
+type ClauseKind struct {
+	kind clauseKind
+}
+
+// CustomClause is the interface implemented by a clause of a kind registered
+// via RegisterClause. This is added to a Query via AddClause.
+type CustomClause interface {
+	Expr
+
+	// Kind returns the kind of the clause, as returned from RegisterClause.
+	Kind() ClauseKind
+}
+
+// customClause wraps a CustomClause so it can be used as a clause in a Query.
+type customClause struct {
+	cl CustomClause
+}
+
+// kindExpr is the CustomClause returned from ClauseKind.Clause.
+type kindExpr struct {
+	k    ClauseKind
+	expr Expr
+}
+
+// clauseKeywords returns the kind of clause for each keyword, including the
+// kinds of clauses that have been registered.
+func clauseKeywords() map[string]clauseKind {
+	m := make(map[string]clauseKind)
+
+	for k := _FromClause; k <= _GroupClause; k++ {
+		m[k.String()] = k
+	}
+
+	for k, def := range customDefs {
+		if def.keyword != "" {
+			m[def.keyword] = k
+		}
+	}
+	return m
+}
+
+// RegisterClause registers a new kind of clause with the given keyword. The
+// keyword is written once before the clauses of the kind when built, and
+// multiple clauses of the kind are separated with a comma. If the keyword is
+// empty then each clause is expected to build its own keyword, and multiple
+// clauses are separated with a space. Clauses of the kind are placed before
+// the first clause with one of the given keywords, otherwise they are placed
+// at the end of the Query. For example, a hint for SQL Server could be
+// registered like so,
+//
+//     var OptionHint = query.RegisterClause("OPTION")
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.AddClause(OptionHint.Clause(query.Lit("(MAXDOP 1)"))),
+//     )
+//
+// which would result in the following query being built,
+//
+//     SELECT * FROM posts OPTION (MAXDOP 1)
+//
+// The keywords given for placement can be those of the clauses in the
+// package, such as WHERE or ORDER BY, or those of previously registered
+// clauses. This panics if the keyword has already been registered, or if a
+// keyword given for placement is not known. This should be called during
+// initialization.
+func RegisterClause(keyword string, before ...string) ClauseKind {
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	keyword = strings.ToUpper(strings.TrimSpace(keyword))
+	keywords := clauseKeywords()
+
+	if keyword != "" {
+		if _, ok := keywords[keyword]; ok {
+			panic("query: clause " + keyword + " already registered")
+		}
+	}
+
+	def := customDef{
+		keyword: keyword,
+		before:  make([]clauseKind, 0, len(before)),
+	}
+
+	for _, kw := range before {
+		k, ok := keywords[strings.ToUpper(strings.TrimSpace(kw))]
+
+		if !ok {
+			panic("query: unknown clause " + kw)
+		}
+		def.before = append(def.before, k)
+	}
+
+	k := customNext
+	customNext++
+
+	customDefs[k] = def
+	return ClauseKind{kind: k}
+}
+
+// customDefOf returns the definition of the given kind of clause if it was
+// registered via RegisterClause.
+func customDefOf(k clauseKind) (customDef, bool) {
+	if k < customKind {
+		return customDef{}, false
+	}
+
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	def, ok := customDefs[k]
+	return def, ok
+}
+
+// name returns the keyword of the clause kind, this differs from String in
+// that the keyword of a registered clause kind is returned.
+func (k clauseKind) name() string {
+	if def, ok := customDefOf(k); ok {
+		return def.keyword
+	}
+	return k.String()
+}
+
+// String returns the keyword of the clause kind.
+func (k ClauseKind) String() string { return k.kind.name() }
+
+// Clause returns a CustomClause of the kind for the given expression.
+func (k ClauseKind) Clause(expr Expr) CustomClause {
+	return kindExpr{k: k, expr: expr}
+}
+
+// AddClause adds the given clause to the Query. The clause is placed when the
+// Query is built, after any other clauses of the same kind, otherwise before
+// the first clause the kind was registered to be placed before.
+func AddClause(cl CustomClause) Option {
+	return deferOpt(func(q Query) Query {
+		kind := cl.Kind().kind
+
+		for _, c := range q.clauses {
+			if c.kind() == kind {
+				return q.insertAfter(customClause{cl: cl}, kind)
+			}
+		}
+
+		def, _ := customDefOf(kind)
+		return q.insertBefore(customClause{cl: cl}, def.before...)
+	})
+}
+
+func (e kindExpr) Kind() ClauseKind      { return e.k }
+func (e kindExpr) Args() []interface{}   { return sqlArgs(e, nil) }
+func (e kindExpr) Build() string         { return buildSQL(e, nil) }
+func (e kindExpr) WriteSQL(w *SQLWriter) { w.WriteExpr(e.expr) }
+
+func (c customClause) kind() clauseKind      { return c.cl.Kind().kind }
+func (c customClause) Args() []interface{}   { return c.cl.Args() }
+func (c customClause) Build() string         { return c.cl.Build() }
+func (c customClause) WriteSQL(w *SQLWriter) { w.WriteExpr(c.cl) }
@@ -0,0 +1,43 @@
+package ddl
+
+import "strings"
+
+// Comment is a COMMENT ON statement.
+type Comment struct {
+	typ     string
+	name    string
+	comment string
+}
+
+// CommentOn returns a COMMENT ON statement that sets the comment of the object
+// of the given type and name, for example,
+//
+//     ddl.CommentOn("column", "users.email", "The user's login, it's unique.")
+//
+// would result in the following statement being built,
+//
+//     COMMENT ON COLUMN users.email IS 'The user''s login, it''s unique.'
+//
+// The comment is written as a quoted literal. If the comment is empty then the
+// comment of the object is removed.
+func CommentOn(typ, name, comment string) Comment {
+	return Comment{
+		typ:     strings.ToUpper(typ),
+		name:    name,
+		comment: comment,
+	}
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a Comment can be used as a query.Expr.
+func (c Comment) Args() []interface{} { return nil }
+
+// Build builds the COMMENT ON statement.
+func (c Comment) Build() string {
+	comment := "NULL"
+
+	if c.comment != "" {
+		comment = quote(c.comment)
+	}
+	return "COMMENT ON " + c.typ + " " + c.name + " IS " + comment
+}
@@ -0,0 +1,222 @@
+package ddl
+
+import (
+	"strings"
+
+	"github.com/andrewpillar/query"
+)
+
+// Action is the action taken on the rows referencing a row via a foreign key
+// when that row is deleted or updated.
+type Action string
+
+const (
+	NoAction   Action = "NO ACTION"
+	Restrict   Action = "RESTRICT"
+	Cascade    Action = "CASCADE"
+	SetNull    Action = "SET NULL"
+	SetDefault Action = "SET DEFAULT"
+)
+
+// tableConstraint is a constraint on a table in a CREATE TABLE statement.
+type tableConstraint struct {
+	name       string
+	def        string
+	onDelete   Action
+	onUpdate   Action
+	where      query.Expr
+	deferrable bool
+	deferred   bool
+}
+
+// ConstraintOption is the type for the first-class functions that are used for
+// modifying a table constraint.
+type ConstraintOption func(c tableConstraint) tableConstraint
+
+// ExcludeElement is a single element of an EXCLUDE constraint, this is the
+// column or expression, and the operator it is compared with.
+type ExcludeElement struct {
+	Elem string
+	Op   string
+}
+
+func addConstraint(def string, opts []ConstraintOption) TableOption {
+	c := tableConstraint{
+		def: def,
+	}
+
+	for _, opt := range opts {
+		c = opt(c)
+	}
+
+	return func(t Table) Table {
+		t.constraints = append(t.constraints[:len(t.constraints):len(t.constraints)], c)
+		return t
+	}
+}
+
+// PrimaryKeyOn adds a PRIMARY KEY constraint on the given columns to the table.
+// This should be used for a primary key of multiple columns, otherwise
+// PrimaryKey should be used on the column.
+func PrimaryKeyOn(cols []string, opts ...ConstraintOption) TableOption {
+	return addConstraint("PRIMARY KEY ("+strings.Join(cols, ", ")+")", opts)
+}
+
+// ForeignKey adds a FOREIGN KEY constraint to the table, for the given columns
+// that reference the given columns of the given table, for example,
+//
+//     ddl.ForeignKey([]string{"user_id"}, "users", []string{"id"}, ddl.OnDelete(ddl.Cascade))
+//
+// would result in the following constraint being built,
+//
+//     FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+//
+// If no referenced columns are given then the primary key of the table is
+// referenced.
+func ForeignKey(cols []string, table string, refs []string, opts ...ConstraintOption) TableOption {
+	def := "FOREIGN KEY (" + strings.Join(cols, ", ") + ") REFERENCES " + table
+
+	if len(refs) > 0 {
+		def += " (" + strings.Join(refs, ", ") + ")"
+	}
+	return addConstraint(def, opts)
+}
+
+// UniqueOn adds a UNIQUE constraint on the given columns to the table.
+func UniqueOn(cols []string, opts ...ConstraintOption) TableOption {
+	return addConstraint("UNIQUE ("+strings.Join(cols, ", ")+")", opts)
+}
+
+// Check adds a CHECK constraint to the table for the given predicate. Any
+// arguments in the predicate are written as literals, for example,
+//
+//     ddl.Check(query.Raw("price >= ?", 0), ddl.Named("price_positive"))
+//
+// would result in the following constraint being built,
+//
+//     CONSTRAINT price_positive CHECK (price >= 0)
+func Check(pred query.Expr, opts ...ConstraintOption) TableOption {
+	return addConstraint("CHECK ("+inlineExpr(pred)+")", opts)
+}
+
+// ExcludeWith returns an element of an EXCLUDE constraint for the given column
+// or expression, and operator.
+func ExcludeWith(elem, op string) ExcludeElement {
+	return ExcludeElement{
+		Elem: elem,
+		Op:   op,
+	}
+}
+
+// Exclude adds an EXCLUDE constraint to the table using the given index
+// method, for example,
+//
+//     ddl.Exclude("gist", []ddl.ExcludeElement{
+//         ddl.ExcludeWith("room_id", "="),
+//         ddl.ExcludeWith("during", "&&"),
+//     })
+//
+// would result in the following constraint being built,
+//
+//     EXCLUDE USING gist (room_id WITH =, during WITH &&)
+//
+// The constraint can be made partial via the Where option.
+func Exclude(method string, elems []ExcludeElement, opts ...ConstraintOption) TableOption {
+	items := make([]string, 0, len(elems))
+
+	for _, el := range elems {
+		items = append(items, el.Elem+" WITH "+el.Op)
+	}
+
+	def := "EXCLUDE "
+
+	if method != "" {
+		def += "USING " + method + " "
+	}
+	return addConstraint(def+"("+strings.Join(items, ", ")+")", opts)
+}
+
+// Named sets the name of the constraint.
+func Named(name string) ConstraintOption {
+	return func(c tableConstraint) tableConstraint {
+		c.name = name
+		return c
+	}
+}
+
+// OnDelete sets the action taken when a referenced row is deleted. This only
+// applies to a FOREIGN KEY constraint.
+func OnDelete(a Action) ConstraintOption {
+	return func(c tableConstraint) tableConstraint {
+		c.onDelete = a
+		return c
+	}
+}
+
+// OnUpdate sets the action taken when a referenced row is updated. This only
+// applies to a FOREIGN KEY constraint.
+func OnUpdate(a Action) ConstraintOption {
+	return func(c tableConstraint) tableConstraint {
+		c.onUpdate = a
+		return c
+	}
+}
+
+// Where sets the predicate of a partial EXCLUDE constraint. Any arguments in
+// the predicate are written as literals.
+func Where(pred query.Expr) ConstraintOption {
+	return func(c tableConstraint) tableConstraint {
+		c.where = pred
+		return c
+	}
+}
+
+// Deferrable makes the constraint deferrable, so it can be checked at the end
+// of a transaction.
+func Deferrable() ConstraintOption {
+	return func(c tableConstraint) tableConstraint {
+		c.deferrable = true
+		return c
+	}
+}
+
+// InitiallyDeferred makes the constraint deferrable, and checked at the end of
+// each transaction by default.
+func InitiallyDeferred() ConstraintOption {
+	return func(c tableConstraint) tableConstraint {
+		c.deferrable = true
+		c.deferred = true
+		return c
+	}
+}
+
+func (c tableConstraint) build() string {
+	var buf strings.Builder
+
+	if c.name != "" {
+		buf.WriteString("CONSTRAINT " + c.name + " ")
+	}
+
+	buf.WriteString(c.def)
+
+	if c.onDelete != "" {
+		buf.WriteString(" ON DELETE " + string(c.onDelete))
+	}
+
+	if c.onUpdate != "" {
+		buf.WriteString(" ON UPDATE " + string(c.onUpdate))
+	}
+
+	if c.where != nil {
+		buf.WriteString(" WHERE (" + inlineExpr(c.where) + ")")
+	}
+
+	if c.deferrable {
+		buf.WriteString(" DEFERRABLE")
+	}
+
+	if c.deferred {
+		buf.WriteString(" INITIALLY DEFERRED")
+	}
+	return buf.String()
+}
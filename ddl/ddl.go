@@ -0,0 +1,107 @@
+// Package ddl provides builders for the DDL statements used to define a
+// schema, such as CREATE TABLE. These are built up via first class functions
+// in the same way as a query.Query, for example,
+//
+//     t := ddl.CreateTable(
+//         "users",
+//         ddl.IfNotExists(),
+//         ddl.Column("id", "bigserial", ddl.PrimaryKey()),
+//         ddl.Column("email", "text", ddl.NotNull(), ddl.Unique()),
+//         ddl.Column("created_at", "timestamp", ddl.NotNull(), ddl.Default(query.Lit("NOW()"))),
+//     )
+//
+// would result in the following statement being built,
+//
+//     CREATE TABLE IF NOT EXISTS users (id bigserial PRIMARY KEY, email text NOT NULL UNIQUE, created_at timestamp NOT NULL DEFAULT NOW())
+//
+// DDL statements do not support parameters, so any values in a statement are
+// written as literals.
+package ddl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewpillar/query"
+)
+
+// literal returns the given value as an SQL literal. A query.Expr is built as
+// is, and a string is quoted.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case query.Expr:
+		return v.Build()
+	case string:
+		return quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + `'`
+	case time.Time:
+		return quote(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return quote(v.String())
+	}
+	return fmt.Sprint(v)
+}
+
+// quote returns the given string quoted with single quotes, any single quotes
+// within the string are escaped by doubling them.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// inline builds the given Query with each of its arguments written as a
+// literal in place of its placeholder.
+func inline(q query.Query) string { return inlineSQL(q.BuildWith(query.Question), q.Args()) }
+
+// inlineExpr builds the given expression with each of its arguments written
+// as a literal in place of its placeholder.
+func inlineExpr(e query.Expr) string {
+	if q, ok := e.(query.Query); ok {
+		return inline(q)
+	}
+	return inlineSQL(query.WriteSQL(e, query.Postgres))
+}
+
+// inlineSQL replaces each ? placeholder in the given SQL with its argument
+// written as a literal.
+func inlineSQL(s string, args []interface{}) string {
+	if len(args) == 0 {
+		return s
+	}
+
+	var buf strings.Builder
+
+	n := 0
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '"':
+			j := strings.IndexByte(s[i+1:], c)
+
+			if j < 0 {
+				buf.WriteString(s[i:])
+				return buf.String()
+			}
+
+			buf.WriteString(s[i : i+j+2])
+			i += j + 1
+		case '?':
+			if n < len(args) {
+				buf.WriteString(literal(args[n]))
+				n++
+				continue
+			}
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
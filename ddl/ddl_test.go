@@ -0,0 +1,369 @@
+package ddl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/query"
+)
+
+type status string
+
+func (s status) String() string { return string(s) }
+
+func Test_CreateTable(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			"CREATE TABLE IF NOT EXISTS users (id bigserial PRIMARY KEY, email text NOT NULL UNIQUE, created_at timestamp NOT NULL DEFAULT NOW())",
+			CreateTable(
+				"users",
+				IfNotExists(),
+				Column("id", "bigserial", PrimaryKey()),
+				Column("email", "text", NotNull(), Unique()),
+				Column("created_at", "timestamp", NotNull(), Default(query.Lit("NOW()"))),
+			),
+		},
+		{
+			"CREATE TEMPORARY TABLE posts (id int PRIMARY KEY, user_id bigint NOT NULL REFERENCES users (id), title text DEFAULT 'it''s new', status text DEFAULT 'draft', views int DEFAULT 0, published bool DEFAULT false, deleted_at timestamp NULL DEFAULT NULL, timeout interval DEFAULT '5s')",
+			CreateTable(
+				"posts",
+				Temporary(),
+				Column("id", "int", PrimaryKey()),
+				Column("user_id", "bigint", NotNull(), References("users", "id")),
+				Column("title", "text", Default("it's new")),
+				Column("status", "text", Default(status("draft"))),
+				Column("views", "int", Default(0)),
+				Column("published", "bool", Default(false)),
+				Column("deleted_at", "timestamp", Null(), Default(nil)),
+				Column("timeout", "interval", Default(5*time.Second)),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.stmt.Args(); args != nil {
+			t.Errorf("tests[%d]: expected no args, got %v\n", i, args)
+		}
+	}
+}
+
+func Test_CreateView(t *testing.T) {
+	published := query.Select(
+		query.Columns("id", "title"),
+		query.From("posts"),
+		query.Where("published", "=", query.Arg(true)),
+		query.Where("title", "!=", query.Arg("it's $1")),
+		query.Where("created_at", ">", query.Arg(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))),
+	)
+
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			"CREATE OR REPLACE VIEW published_posts AS SELECT id, title FROM posts WHERE (published = true AND title != 'it''s $1' AND created_at > '2021-01-01T00:00:00Z')",
+			CreateView("published_posts", published, OrReplace(), ViewIfNotExists()),
+		},
+		{
+			"CREATE MATERIALIZED VIEW IF NOT EXISTS post_counts (user_id, total) AS SELECT user_id, COUNT(*) FROM posts WITH NO DATA",
+			CreateMaterializedView(
+				"post_counts",
+				query.Select(query.Lit("user_id, COUNT(*)"), query.From("posts")),
+				OrReplace(),
+				ViewIfNotExists(),
+				ViewColumns("user_id", "total"),
+				WithNoData(),
+			),
+		},
+		{
+			"SELECT id, title FROM posts WHERE (published = ? AND title != ? AND created_at > ?)",
+			CreateView("published_posts", query.WithDialect(query.MySQL)(published)).Query(),
+		},
+		{
+			"CREATE VIEW published_posts AS SELECT id, title FROM posts WHERE (published = true AND title != 'it''s $1' AND created_at > '2021-01-01T00:00:00Z')",
+			CreateView("published_posts", query.WithDialect(query.SQLServer)(published)),
+		},
+		{
+			"REFRESH MATERIALIZED VIEW post_counts",
+			RefreshMaterializedView("post_counts"),
+		},
+		{
+			"REFRESH MATERIALIZED VIEW CONCURRENTLY post_counts WITH NO DATA",
+			RefreshMaterializedView("post_counts", Concurrently(), RefreshWithNoData()),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_Constraints(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			"CREATE TABLE post_tags (post_id bigint NOT NULL, tag_id bigint NOT NULL, PRIMARY KEY (post_id, tag_id), FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE, CONSTRAINT post_tags_tag_fk FOREIGN KEY (tag_id) REFERENCES tags ON DELETE RESTRICT ON UPDATE SET NULL DEFERRABLE INITIALLY DEFERRED)",
+			CreateTable(
+				"post_tags",
+				Column("post_id", "bigint", NotNull()),
+				Column("tag_id", "bigint", NotNull()),
+				PrimaryKeyOn([]string{"post_id", "tag_id"}),
+				ForeignKey([]string{"post_id"}, "posts", []string{"id"}, OnDelete(Cascade)),
+				ForeignKey([]string{"tag_id"}, "tags", nil, Named("post_tags_tag_fk"), OnDelete(Restrict), OnUpdate(SetNull), InitiallyDeferred()),
+			),
+		},
+		{
+			"CREATE TABLE products (price numeric, discount numeric, CONSTRAINT price_positive CHECK (price >= 0), CHECK (discount < price AND discount != 0.5), UNIQUE (price, discount) DEFERRABLE)",
+			CreateTable(
+				"products",
+				Column("price", "numeric"),
+				Column("discount", "numeric"),
+				Check(query.Raw("price >= ?", 0), Named("price_positive")),
+				Check(query.Append(" AND ", query.Lit("discount < price"), query.Raw("discount != ?", 0.5))),
+				UniqueOn([]string{"price", "discount"}, Deferrable()),
+			),
+		},
+		{
+			"CREATE TABLE bookings (room_id int, during tsrange, cancelled bool, EXCLUDE USING gist (room_id WITH =, during WITH &&) WHERE (NOT cancelled AND room_id != 0))",
+			CreateTable(
+				"bookings",
+				Column("room_id", "int"),
+				Column("during", "tsrange"),
+				Column("cancelled", "bool"),
+				Exclude("gist", []ExcludeElement{
+					ExcludeWith("room_id", "="),
+					ExcludeWith("during", "&&"),
+				}, Where(query.Raw("NOT cancelled AND room_id != ?", 0))),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_Partition(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			"CREATE TABLE IF NOT EXISTS events (id bigserial, created_at timestamp NOT NULL, PRIMARY KEY (id, created_at)) PARTITION BY RANGE (created_at)",
+			CreateTable(
+				"events",
+				IfNotExists(),
+				Column("id", "bigserial"),
+				Column("created_at", "timestamp", NotNull()),
+				PrimaryKeyOn([]string{"id", "created_at"}),
+				PartitionByRange("created_at"),
+			),
+		},
+		{
+			"CREATE TABLE events_2024 PARTITION OF events FOR VALUES FROM ('2024-01-01T00:00:00Z') TO ('2025-01-01T00:00:00Z')",
+			CreateTable(
+				"events_2024",
+				PartitionOf("events", ValuesFromTo(
+					[]interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+					[]interface{}{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				)),
+			),
+		},
+		{
+			"CREATE TABLE events_old PARTITION OF events (created_at DEFAULT NOW()) FOR VALUES FROM (MINVALUE) TO ('2024-01-01')",
+			CreateTable(
+				"events_old",
+				PartitionOf("events", ValuesFromTo([]interface{}{MinValue}, []interface{}{"2024-01-01"})),
+				Column("created_at", "timestamp", Default(query.Lit("NOW()"))),
+			),
+		},
+		{
+			"CREATE TABLE accounts_eu PARTITION OF accounts FOR VALUES IN ('de', 'fr') PARTITION BY HASH (id)",
+			CreateTable("accounts_eu", PartitionOf("accounts", ValuesIn("de", "fr")), PartitionByHash("id")),
+		},
+		{
+			"CREATE TABLE accounts_eu_0 PARTITION OF accounts_eu FOR VALUES WITH (MODULUS 4, REMAINDER 0)",
+			CreateTable("accounts_eu_0", PartitionOf("accounts_eu", ValuesWith(4, 0))),
+		},
+		{
+			"CREATE TABLE accounts_other PARTITION OF accounts DEFAULT",
+			CreateTable("accounts_other", PartitionOf("accounts", DefaultPartition())),
+		},
+		{
+			"CREATE TABLE accounts (id bigint, region text) PARTITION BY LIST (region)",
+			CreateTable("accounts", Column("id", "bigint"), Column("region", "text"), PartitionByList("region")),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_CreateSchema(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{"CREATE SCHEMA tenant_1", CreateSchema("tenant_1")},
+		{
+			"CREATE SCHEMA IF NOT EXISTS tenant_1 AUTHORIZATION tenant_1_owner",
+			CreateSchema("tenant_1", SchemaIfNotExists(), Authorization("tenant_1_owner")),
+		},
+		{"CREATE SCHEMA AUTHORIZATION tenant_2", CreateSchema("", Authorization("tenant_2"))},
+		{
+			`CREATE SCHEMA IF NOT EXISTS "Tenant ""3"""`,
+			CreateSchema(query.Postgres.Quote(`Tenant "3"`), SchemaIfNotExists()),
+		},
+		{"CREATE EXTENSION pgcrypto", CreateExtension("pgcrypto")},
+		{
+			"CREATE EXTENSION IF NOT EXISTS postgis_topology WITH SCHEMA extensions VERSION '3.4' CASCADE",
+			CreateExtension("postgis_topology", ExtensionIfNotExists(), InSchema("extensions"), Version("3.4"), WithCascade()),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_CommentOn(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{"COMMENT ON TABLE users IS 'The users of the application.'", CommentOn("TABLE", "users", "The users of the application.")},
+		{"COMMENT ON COLUMN users.email IS 'The user''s login, it''s unique.'", CommentOn("column", "users.email", "The user's login, it's unique.")},
+		{`COMMENT ON MATERIALIZED VIEW post_counts IS 'Refreshed nightly; see C:\jobs\refresh.'`, CommentOn("materialized view", "post_counts", `Refreshed nightly; see C:\jobs\refresh.`)},
+		{"COMMENT ON SCHEMA tenant_1 IS NULL", CommentOn("schema", "tenant_1", "")},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_Grant(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			`GRANT SELECT, INSERT ON TABLE posts, tenant_1.users TO app, "Tenant-1"`,
+			Grant([]string{"SELECT", "INSERT"}, OnTables("posts", "tenant_1.users"), To("app", "Tenant-1")),
+		},
+		{
+			`GRANT USAGE, SELECT ON SEQUENCE "Tenant-1".posts_id_seq TO PUBLIC WITH GRANT OPTION`,
+			Grant([]string{"USAGE", "SELECT"}, OnSequences(`"Tenant-1".posts_id_seq`), To("public"), WithGrantOption()),
+		},
+		{
+			`GRANT ALL ON ALL TABLES IN SCHEMA "Tenant ""1""" TO "o'brien"`,
+			Grant([]string{"ALL"}, OnAllTablesInSchema(`Tenant "1"`), To("o'brien")),
+		},
+		{
+			"GRANT USAGE ON ALL SEQUENCES IN SCHEMA tenant_1 TO CURRENT_USER",
+			Grant([]string{"USAGE"}, OnAllSequencesInSchema("tenant_1"), To("current_user")),
+		},
+		{
+			"REVOKE ALL ON SCHEMA tenant_1 FROM app CASCADE",
+			Revoke([]string{"ALL"}, OnSchemas("tenant_1"), From("app"), RevokeCascade()),
+		},
+		{
+			"REVOKE GRANT OPTION FOR SELECT ON TABLE posts FROM app",
+			Revoke([]string{"SELECT"}, OnTables("posts"), From("app"), WithGrantOption()),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_CreateTrigger(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			"CREATE TRIGGER users_updated_at BEFORE UPDATE ON users FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION set_updated_at()",
+			CreateTrigger(
+				"users_updated_at",
+				"users",
+				Before(EventUpdate),
+				ForEachRow(),
+				When(query.Lit("OLD.* IS DISTINCT FROM NEW.*")),
+				Execute("set_updated_at"),
+			),
+		},
+		{
+			"CREATE OR REPLACE TRIGGER posts_audit AFTER INSERT OR UPDATE OF title, body OR DELETE ON posts EXECUTE FUNCTION audit('posts', 2)",
+			CreateTrigger(
+				"posts_audit",
+				"posts",
+				TriggerOrReplace(),
+				After(EventInsert, UpdateOf("title", "body"), EventDelete),
+				Execute("audit", "posts", 2),
+			),
+		},
+		{
+			"CREATE TRIGGER posts_limit BEFORE INSERT ON posts FOR EACH ROW WHEN (NEW.status = 'draft') EXECUTE FUNCTION check_limit()",
+			CreateTrigger(
+				"posts_limit",
+				"posts",
+				Before(EventInsert),
+				ForEachRow(),
+				When(query.Raw("NEW.status = ?", "draft")),
+				Execute("check_limit"),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_CreateFunction(t *testing.T) {
+	tests := []struct {
+		expected string
+		stmt     query.Expr
+	}{
+		{
+			"CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN NEW.updated_at = NOW(); RETURN NEW; END; $$",
+			CreateFunction("set_updated_at", "trigger", " BEGIN NEW.updated_at = NOW(); RETURN NEW; END; ", FunctionOrReplace()),
+		},
+		{
+			"CREATE FUNCTION add(a int, b int) RETURNS int LANGUAGE sql AS $body0$SELECT '$$' || a + b$body0$",
+			CreateFunction("add", "int", "SELECT '$$' || a + b", Params("a int", "b int"), Language("sql")),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.stmt.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package ddl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Function is a CREATE FUNCTION statement.
+type Function struct {
+	name     string
+	params   []string
+	returns  string
+	language string
+	body     string
+	replace  bool
+}
+
+// FunctionOption is the type for the first-class functions that are used for
+// modifying a CREATE FUNCTION statement.
+type FunctionOption func(f Function) Function
+
+// CreateFunction returns a CREATE FUNCTION statement for a function with the
+// given name, return type, and body, applying the given options. The language
+// of the function is plpgsql unless set via Language, for example,
+//
+//     ddl.CreateFunction("set_updated_at", "trigger", `
+//     BEGIN
+//         NEW.updated_at = NOW();
+//         RETURN NEW;
+//     END;
+//     `, ddl.FunctionOrReplace())
+//
+// would result in the following statement being built,
+//
+//     CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger LANGUAGE plpgsql AS $$
+//     BEGIN
+//         NEW.updated_at = NOW();
+//         RETURN NEW;
+//     END;
+//     $$
+//
+// The body is written as is within dollar quotes, so it does not need to be
+// escaped. A different tag is used for the quotes if the body contains $$.
+func CreateFunction(name, returns, body string, opts ...FunctionOption) Function {
+	f := Function{
+		name:     name,
+		returns:  returns,
+		language: "plpgsql",
+		body:     body,
+	}
+
+	for _, opt := range opts {
+		f = opt(f)
+	}
+	return f
+}
+
+// FunctionOrReplace adds OR REPLACE to the statement, so an existing function
+// with the same signature is replaced.
+func FunctionOrReplace() FunctionOption {
+	return func(f Function) Function {
+		f.replace = true
+		return f
+	}
+}
+
+// Params sets the parameters of the function, each parameter is the name and
+// type of the parameter, for example "id bigint".
+func Params(params ...string) FunctionOption {
+	return func(f Function) Function {
+		f.params = params
+		return f
+	}
+}
+
+// Language sets the language the body of the function is written in.
+func Language(lang string) FunctionOption {
+	return func(f Function) Function {
+		f.language = lang
+		return f
+	}
+}
+
+// dollarQuote returns the given string within dollar quotes, using a tag that
+// does not appear in the string.
+func dollarQuote(s string) string {
+	tag := "$$"
+
+	for i := 0; strings.Contains(s, tag); i++ {
+		tag = "$body" + strconv.Itoa(i) + "$"
+	}
+	return tag + s + tag
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a Function can be used as a query.Expr.
+func (f Function) Args() []interface{} { return nil }
+
+// Build builds the CREATE FUNCTION statement.
+func (f Function) Build() string {
+	var buf strings.Builder
+
+	buf.WriteString("CREATE ")
+
+	if f.replace {
+		buf.WriteString("OR REPLACE ")
+	}
+
+	buf.WriteString("FUNCTION " + f.name + "(" + strings.Join(f.params, ", ") + ")")
+	buf.WriteString(" RETURNS " + f.returns)
+
+	if f.language != "" {
+		buf.WriteString(" LANGUAGE " + f.language)
+	}
+
+	buf.WriteString(" AS " + dollarQuote(f.body))
+	return buf.String()
+}
@@ -0,0 +1,208 @@
+package ddl
+
+import "strings"
+
+// Privileges is a GRANT or REVOKE statement for the privileges on a set of
+// objects.
+type Privileges struct {
+	revoke      bool
+	privileges  []string
+	on          string
+	objects     []string
+	roles       []string
+	grantOption bool
+	cascade     bool
+}
+
+// PrivilegeOption is the type for the first-class functions that are used for
+// modifying a GRANT or REVOKE statement.
+type PrivilegeOption func(p Privileges) Privileges
+
+// Grant returns a GRANT statement for the given privileges, applying the given
+// options, for example,
+//
+//     ddl.Grant([]string{"SELECT", "INSERT"}, ddl.OnTables("posts"), ddl.To("app", "Tenant-1"))
+//
+// would result in the following statement being built,
+//
+//     GRANT SELECT, INSERT ON TABLE posts TO app, "Tenant-1"
+//
+// The names of the objects and roles are quoted when they are not a lower case
+// identifier, so they do not need to be quoted beforehand.
+func Grant(privileges []string, opts ...PrivilegeOption) Privileges {
+	p := Privileges{
+		privileges: privileges,
+	}
+
+	for _, opt := range opts {
+		p = opt(p)
+	}
+	return p
+}
+
+// Revoke returns a REVOKE statement for the given privileges, applying the
+// given options. The roles the privileges are revoked from are given via
+// From.
+func Revoke(privileges []string, opts ...PrivilegeOption) Privileges {
+	p := Grant(privileges, opts...)
+	p.revoke = true
+	return p
+}
+
+func on(typ string, objects []string) PrivilegeOption {
+	return func(p Privileges) Privileges {
+		p.on = typ
+		p.objects = objects
+		return p
+	}
+}
+
+// OnTables sets the tables the privileges are on.
+func OnTables(tables ...string) PrivilegeOption { return on("TABLE", tables) }
+
+// OnSequences sets the sequences the privileges are on.
+func OnSequences(sequences ...string) PrivilegeOption { return on("SEQUENCE", sequences) }
+
+// OnSchemas sets the schemas the privileges are on.
+func OnSchemas(schemas ...string) PrivilegeOption { return on("SCHEMA", schemas) }
+
+// OnAllTablesInSchema sets the privileges to be on all of the tables in the
+// given schemas.
+func OnAllTablesInSchema(schemas ...string) PrivilegeOption {
+	return on("ALL TABLES IN SCHEMA", schemas)
+}
+
+// OnAllSequencesInSchema sets the privileges to be on all of the sequences in
+// the given schemas.
+func OnAllSequencesInSchema(schemas ...string) PrivilegeOption {
+	return on("ALL SEQUENCES IN SCHEMA", schemas)
+}
+
+// To sets the roles the privileges are granted to. PUBLIC, CURRENT_USER, and
+// SESSION_USER are written as is.
+func To(roles ...string) PrivilegeOption {
+	return func(p Privileges) Privileges {
+		p.roles = roles
+		return p
+	}
+}
+
+// From sets the roles the privileges are revoked from, this is the same as To.
+func From(roles ...string) PrivilegeOption { return To(roles...) }
+
+// WithGrantOption allows the roles to grant the privileges to other roles.
+// For a REVOKE statement, only the option to grant the privileges is revoked.
+func WithGrantOption() PrivilegeOption {
+	return func(p Privileges) Privileges {
+		p.grantOption = true
+		return p
+	}
+}
+
+// RevokeCascade revokes the privileges from the roles that were granted them
+// by the roles the privileges are being revoked from.
+func RevokeCascade() PrivilegeOption {
+	return func(p Privileges) Privileges {
+		p.cascade = true
+		return p
+	}
+}
+
+// keywordRoles are the roles that are keywords, and so are never quoted.
+var keywordRoles = map[string]struct{}{
+	"PUBLIC":       {},
+	"CURRENT_ROLE": {},
+	"CURRENT_USER": {},
+	"SESSION_USER": {},
+}
+
+// ident returns the given name quoted if it is not a lower case identifier.
+// Each part of a qualified name is quoted separately, and a name that is
+// already quoted is left as it is.
+func ident(name string) string {
+	parts := strings.Split(name, ".")
+
+	for i, part := range parts {
+		if plain(part) || strings.HasPrefix(part, `"`) && strings.HasSuffix(part, `"`) && len(part) > 1 {
+			continue
+		}
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// plain reports whether the given name is a lower case identifier that does
+// not need quoting.
+func plain(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func idents(names []string) string {
+	quoted := make([]string, 0, len(names))
+
+	for _, name := range names {
+		quoted = append(quoted, ident(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so Privileges can be used as a query.Expr.
+func (p Privileges) Args() []interface{} { return nil }
+
+// Build builds the GRANT or REVOKE statement.
+func (p Privileges) Build() string {
+	var buf strings.Builder
+
+	if p.revoke {
+		buf.WriteString("REVOKE ")
+
+		if p.grantOption {
+			buf.WriteString("GRANT OPTION FOR ")
+		}
+	} else {
+		buf.WriteString("GRANT ")
+	}
+
+	buf.WriteString(strings.Join(p.privileges, ", "))
+	buf.WriteString(" ON " + p.on + " " + idents(p.objects))
+
+	if p.revoke {
+		buf.WriteString(" FROM ")
+	} else {
+		buf.WriteString(" TO ")
+	}
+
+	for i, role := range p.roles {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+
+		if _, ok := keywordRoles[strings.ToUpper(role)]; ok {
+			buf.WriteString(strings.ToUpper(role))
+			continue
+		}
+		buf.WriteString(ident(role))
+	}
+
+	if p.grantOption && !p.revoke {
+		buf.WriteString(" WITH GRANT OPTION")
+	}
+
+	if p.cascade && p.revoke {
+		buf.WriteString(" CASCADE")
+	}
+	return buf.String()
+}
@@ -0,0 +1,106 @@
+package ddl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andrewpillar/query"
+)
+
+var (
+	// MinValue is the lower bound of a range partition that has no lower
+	// bound.
+	MinValue query.Expr = query.Lit("MINVALUE")
+
+	// MaxValue is the upper bound of a range partition that has no upper
+	// bound.
+	MaxValue query.Expr = query.Lit("MAXVALUE")
+)
+
+// PartitionBound is the bound of the values of a partition of a table.
+type PartitionBound struct {
+	def string
+}
+
+func partitionBy(strategy string, cols []string) TableOption {
+	return func(t Table) Table {
+		t.partitionBy = "PARTITION BY " + strategy + " (" + strings.Join(cols, ", ") + ")"
+		return t
+	}
+}
+
+// PartitionByRange partitions the table by ranges of the values of the given
+// columns or expressions.
+func PartitionByRange(cols ...string) TableOption { return partitionBy("RANGE", cols) }
+
+// PartitionByList partitions the table by lists of the values of the given
+// columns or expressions.
+func PartitionByList(cols ...string) TableOption { return partitionBy("LIST", cols) }
+
+// PartitionByHash partitions the table by the hash of the values of the given
+// columns or expressions.
+func PartitionByHash(cols ...string) TableOption { return partitionBy("HASH", cols) }
+
+// PartitionOf makes the table a partition of the given table for the values
+// within the given bound, for example,
+//
+//     ddl.CreateTable(
+//         "events_2024",
+//         ddl.PartitionOf("events", ddl.ValuesFromTo([]interface{}{"2024-01-01"}, []interface{}{"2025-01-01"})),
+//     )
+//
+// would result in the following statement being built,
+//
+//     CREATE TABLE events_2024 PARTITION OF events FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')
+//
+// Columns given to a partition set the constraints of the columns that are
+// inherited from the table, and the type of each column is ignored.
+func PartitionOf(parent string, bound PartitionBound) TableOption {
+	return func(t Table) Table {
+		t.partitionOf = parent
+		t.bound = bound
+		return t
+	}
+}
+
+func literals(vals []interface{}) string {
+	items := make([]string, 0, len(vals))
+
+	for _, v := range vals {
+		items = append(items, literal(v))
+	}
+	return strings.Join(items, ", ")
+}
+
+// ValuesFromTo returns the bound of a range partition, from the given values
+// inclusive, to the given values exclusive. There is a value for each of the
+// columns the table is partitioned by, MinValue and MaxValue can be given for
+// a range that is unbounded.
+func ValuesFromTo(from, to []interface{}) PartitionBound {
+	return PartitionBound{
+		def: "FOR VALUES FROM (" + literals(from) + ") TO (" + literals(to) + ")",
+	}
+}
+
+// ValuesIn returns the bound of a list partition for the given values.
+func ValuesIn(vals ...interface{}) PartitionBound {
+	return PartitionBound{
+		def: "FOR VALUES IN (" + literals(vals) + ")",
+	}
+}
+
+// ValuesWith returns the bound of a hash partition for the rows whose hash
+// modulo the given modulus is the given remainder.
+func ValuesWith(modulus, remainder int) PartitionBound {
+	return PartitionBound{
+		def: "FOR VALUES WITH (MODULUS " + strconv.Itoa(modulus) + ", REMAINDER " + strconv.Itoa(remainder) + ")",
+	}
+}
+
+// DefaultPartition returns the bound of the default partition, which has the
+// rows that do not fit in any other partition.
+func DefaultPartition() PartitionBound {
+	return PartitionBound{
+		def: "DEFAULT",
+	}
+}
@@ -0,0 +1,173 @@
+package ddl
+
+// Schema is a CREATE SCHEMA statement.
+type Schema struct {
+	name          string
+	ifNotExists   bool
+	authorization string
+}
+
+// SchemaOption is the type for the first-class functions that are used for
+// modifying a CREATE SCHEMA statement.
+type SchemaOption func(s Schema) Schema
+
+// Extension is a CREATE EXTENSION statement.
+type Extension struct {
+	name        string
+	ifNotExists bool
+	schema      string
+	version     string
+	cascade     bool
+}
+
+// ExtensionOption is the type for the first-class functions that are used for
+// modifying a CREATE EXTENSION statement.
+type ExtensionOption func(e Extension) Extension
+
+// CreateSchema returns a CREATE SCHEMA statement for the given schema, applying
+// the given options, for example,
+//
+//     ddl.CreateSchema("tenant_1", ddl.SchemaIfNotExists(), ddl.Authorization("tenant_1_owner"))
+//
+// would result in the following statement being built,
+//
+//     CREATE SCHEMA IF NOT EXISTS tenant_1 AUTHORIZATION tenant_1_owner
+//
+// If the name is empty, then the schema is named after the role given via
+// Authorization. The name is written as is, so a name that is not trusted
+// should be quoted via query.Postgres.Quote.
+func CreateSchema(name string, opts ...SchemaOption) Schema {
+	s := Schema{
+		name: name,
+	}
+
+	for _, opt := range opts {
+		s = opt(s)
+	}
+	return s
+}
+
+// SchemaIfNotExists adds IF NOT EXISTS to the statement, so no error occurs if
+// the schema already exists.
+func SchemaIfNotExists() SchemaOption {
+	return func(s Schema) Schema {
+		s.ifNotExists = true
+		return s
+	}
+}
+
+// Authorization sets the role that will own the schema.
+func Authorization(role string) SchemaOption {
+	return func(s Schema) Schema {
+		s.authorization = role
+		return s
+	}
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a Schema can be used as a query.Expr.
+func (s Schema) Args() []interface{} { return nil }
+
+// Build builds the CREATE SCHEMA statement.
+func (s Schema) Build() string {
+	stmt := "CREATE SCHEMA "
+
+	if s.ifNotExists {
+		stmt += "IF NOT EXISTS "
+	}
+
+	stmt += s.name
+
+	if s.authorization != "" {
+		if s.name != "" {
+			stmt += " "
+		}
+		stmt += "AUTHORIZATION " + s.authorization
+	}
+	return stmt
+}
+
+// CreateExtension returns a CREATE EXTENSION statement for the given
+// extension, applying the given options, for example,
+//
+//     ddl.CreateExtension("pgcrypto", ddl.ExtensionIfNotExists(), ddl.InSchema("extensions"))
+//
+// would result in the following statement being built,
+//
+//     CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA extensions
+func CreateExtension(name string, opts ...ExtensionOption) Extension {
+	e := Extension{
+		name: name,
+	}
+
+	for _, opt := range opts {
+		e = opt(e)
+	}
+	return e
+}
+
+// ExtensionIfNotExists adds IF NOT EXISTS to the statement, so no error occurs
+// if the extension is already installed.
+func ExtensionIfNotExists() ExtensionOption {
+	return func(e Extension) Extension {
+		e.ifNotExists = true
+		return e
+	}
+}
+
+// InSchema sets the schema the objects of the extension are installed in.
+func InSchema(schema string) ExtensionOption {
+	return func(e Extension) Extension {
+		e.schema = schema
+		return e
+	}
+}
+
+// Version sets the version of the extension to install.
+func Version(version string) ExtensionOption {
+	return func(e Extension) Extension {
+		e.version = version
+		return e
+	}
+}
+
+// WithCascade installs any extensions the extension depends on that are not
+// already installed.
+func WithCascade() ExtensionOption {
+	return func(e Extension) Extension {
+		e.cascade = true
+		return e
+	}
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so an Extension can be used as a query.Expr.
+func (e Extension) Args() []interface{} { return nil }
+
+// Build builds the CREATE EXTENSION statement.
+func (e Extension) Build() string {
+	stmt := "CREATE EXTENSION "
+
+	if e.ifNotExists {
+		stmt += "IF NOT EXISTS "
+	}
+
+	stmt += e.name
+
+	if e.schema != "" || e.version != "" || e.cascade {
+		stmt += " WITH"
+	}
+
+	if e.schema != "" {
+		stmt += " SCHEMA " + e.schema
+	}
+
+	if e.version != "" {
+		stmt += " VERSION " + quote(e.version)
+	}
+
+	if e.cascade {
+		stmt += " CASCADE"
+	}
+	return stmt
+}
@@ -0,0 +1,180 @@
+package ddl
+
+import "strings"
+
+// Table is a CREATE TABLE statement.
+type Table struct {
+	name        string
+	ifNotExists bool
+	temporary   bool
+	cols        []column
+	constraints []tableConstraint
+	partitionBy string
+	partitionOf string
+	bound       PartitionBound
+}
+
+// column is a single column in a CREATE TABLE statement.
+type column struct {
+	name        string
+	typ         string
+	constraints []string
+}
+
+// TableOption is the type for the first-class functions that are used for
+// modifying a CREATE TABLE statement.
+type TableOption func(t Table) Table
+
+// ColumnOption is the type for the first-class functions that are used for
+// setting the default value, and the constraints of a column.
+type ColumnOption func(c column) column
+
+// CreateTable returns a CREATE TABLE statement for the given table, applying
+// the given options.
+func CreateTable(name string, opts ...TableOption) Table {
+	t := Table{
+		name: name,
+	}
+
+	for _, opt := range opts {
+		t = opt(t)
+	}
+	return t
+}
+
+// IfNotExists adds IF NOT EXISTS to the statement, so no error occurs if the
+// table already exists.
+func IfNotExists() TableOption {
+	return func(t Table) Table {
+		t.ifNotExists = true
+		return t
+	}
+}
+
+// Temporary makes the table a temporary table, which is dropped at the end of
+// the session.
+func Temporary() TableOption {
+	return func(t Table) Table {
+		t.temporary = true
+		return t
+	}
+}
+
+// Column adds a column of the given name and type to the table, applying the
+// given options. The constraints of the column are built in the order they
+// are given.
+func Column(name, typ string, opts ...ColumnOption) TableOption {
+	c := column{
+		name: name,
+		typ:  typ,
+	}
+
+	for _, opt := range opts {
+		c = opt(c)
+	}
+
+	return func(t Table) Table {
+		t.cols = append(t.cols[:len(t.cols):len(t.cols)], c)
+		return t
+	}
+}
+
+func constraint(s string) ColumnOption {
+	return func(c column) column {
+		c.constraints = append(c.constraints[:len(c.constraints):len(c.constraints)], s)
+		return c
+	}
+}
+
+// PrimaryKey adds a PRIMARY KEY constraint to the column.
+func PrimaryKey() ColumnOption { return constraint("PRIMARY KEY") }
+
+// NotNull adds a NOT NULL constraint to the column.
+func NotNull() ColumnOption { return constraint("NOT NULL") }
+
+// Null explicitly marks the column as NULL.
+func Null() ColumnOption { return constraint("NULL") }
+
+// Unique adds a UNIQUE constraint to the column.
+func Unique() ColumnOption { return constraint("UNIQUE") }
+
+// Default sets the default value of the column. A query.Expr is built as is,
+// for example query.Lit("NOW()"), a string is quoted, and any other value is
+// formatted as a literal.
+func Default(v interface{}) ColumnOption { return constraint("DEFAULT " + literal(v)) }
+
+// References adds a REFERENCES constraint to the column for the given column
+// of the given table. If no column is given then the primary key of the table
+// is referenced.
+func References(table string, col ...string) ColumnOption {
+	s := "REFERENCES " + table
+
+	if len(col) > 0 {
+		s += " (" + strings.Join(col, ", ") + ")"
+	}
+	return constraint(s)
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a Table can be used as a query.Expr.
+func (t Table) Args() []interface{} { return nil }
+
+// Build builds the CREATE TABLE statement.
+func (t Table) Build() string {
+	var buf strings.Builder
+
+	buf.WriteString("CREATE ")
+
+	if t.temporary {
+		buf.WriteString("TEMPORARY ")
+	}
+
+	buf.WriteString("TABLE ")
+
+	if t.ifNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+
+	buf.WriteString(t.name)
+
+	if t.partitionOf != "" {
+		buf.WriteString(" PARTITION OF " + t.partitionOf)
+	}
+
+	if t.partitionOf == "" || len(t.cols) > 0 || len(t.constraints) > 0 {
+		buf.WriteString(" (")
+
+		for i, c := range t.cols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+
+			buf.WriteString(c.name)
+
+			if t.partitionOf == "" {
+				buf.WriteString(" " + c.typ)
+			}
+
+			for _, s := range c.constraints {
+				buf.WriteString(" " + s)
+			}
+		}
+
+		for i, c := range t.constraints {
+			if i > 0 || len(t.cols) > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(c.build())
+		}
+		buf.WriteByte(')')
+	}
+
+	if t.partitionOf != "" {
+		buf.WriteString(" " + t.bound.def)
+	}
+
+	if t.partitionBy != "" {
+		buf.WriteString(" " + t.partitionBy)
+	}
+	return buf.String()
+}
@@ -0,0 +1,169 @@
+package ddl
+
+import (
+	"strings"
+
+	"github.com/andrewpillar/query"
+)
+
+// Event is an event that fires a trigger.
+type Event string
+
+const (
+	EventInsert   Event = "INSERT"
+	EventUpdate   Event = "UPDATE"
+	EventDelete   Event = "DELETE"
+	EventTruncate Event = "TRUNCATE"
+)
+
+// Trigger is a CREATE TRIGGER statement.
+type Trigger struct {
+	name    string
+	table   string
+	replace bool
+	timing  string
+	events  []Event
+	row     bool
+	when    query.Expr
+	fn      string
+	args    []interface{}
+}
+
+// TriggerOption is the type for the first-class functions that are used for
+// modifying a CREATE TRIGGER statement.
+type TriggerOption func(t Trigger) Trigger
+
+// CreateTrigger returns a CREATE TRIGGER statement for a trigger with the
+// given name on the given table, applying the given options, for example,
+//
+//     ddl.CreateTrigger(
+//         "users_updated_at",
+//         "users",
+//         ddl.Before(ddl.EventUpdate),
+//         ddl.ForEachRow(),
+//         ddl.When(query.Lit("OLD.* IS DISTINCT FROM NEW.*")),
+//         ddl.Execute("set_updated_at"),
+//     )
+//
+// would result in the following statement being built,
+//
+//     CREATE TRIGGER users_updated_at BEFORE UPDATE ON users FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION set_updated_at()
+//
+// The trigger fires AFTER the events unless set otherwise, and once for each
+// statement unless ForEachRow is given.
+func CreateTrigger(name, table string, opts ...TriggerOption) Trigger {
+	t := Trigger{
+		name:   name,
+		table:  table,
+		timing: "AFTER",
+	}
+
+	for _, opt := range opts {
+		t = opt(t)
+	}
+	return t
+}
+
+// UpdateOf returns an UPDATE event that only fires the trigger when one of the
+// given columns is updated.
+func UpdateOf(cols ...string) Event {
+	return Event("UPDATE OF " + strings.Join(cols, ", "))
+}
+
+func triggerTiming(timing string, events []Event) TriggerOption {
+	return func(t Trigger) Trigger {
+		t.timing = timing
+		t.events = append(t.events[:len(t.events):len(t.events)], events...)
+		return t
+	}
+}
+
+// Before fires the trigger before the given events.
+func Before(events ...Event) TriggerOption { return triggerTiming("BEFORE", events) }
+
+// After fires the trigger after the given events.
+func After(events ...Event) TriggerOption { return triggerTiming("AFTER", events) }
+
+// InsteadOf fires the trigger instead of the given events. This is only
+// supported for triggers on views.
+func InsteadOf(events ...Event) TriggerOption { return triggerTiming("INSTEAD OF", events) }
+
+// TriggerOrReplace adds OR REPLACE to the statement, so an existing trigger
+// with the same name on the table is replaced.
+func TriggerOrReplace() TriggerOption {
+	return func(t Trigger) Trigger {
+		t.replace = true
+		return t
+	}
+}
+
+// ForEachRow fires the trigger once for each row affected by the event, rather
+// than once for each statement.
+func ForEachRow() TriggerOption {
+	return func(t Trigger) Trigger {
+		t.row = true
+		return t
+	}
+}
+
+// When sets the condition that must be true for the trigger to fire. Any
+// arguments in the predicate are written as literals.
+func When(pred query.Expr) TriggerOption {
+	return func(t Trigger) Trigger {
+		t.when = pred
+		return t
+	}
+}
+
+// Execute sets the function executed when the trigger fires, and the arguments
+// passed to it. The arguments are written as literals.
+func Execute(fn string, args ...interface{}) TriggerOption {
+	return func(t Trigger) Trigger {
+		t.fn = fn
+		t.args = args
+		return t
+	}
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a Trigger can be used as a query.Expr.
+func (t Trigger) Args() []interface{} { return nil }
+
+// Build builds the CREATE TRIGGER statement.
+func (t Trigger) Build() string {
+	var buf strings.Builder
+
+	buf.WriteString("CREATE ")
+
+	if t.replace {
+		buf.WriteString("OR REPLACE ")
+	}
+
+	buf.WriteString("TRIGGER " + t.name + " " + t.timing + " ")
+
+	for i, ev := range t.events {
+		if i > 0 {
+			buf.WriteString(" OR ")
+		}
+		buf.WriteString(string(ev))
+	}
+
+	buf.WriteString(" ON " + t.table)
+
+	if t.row {
+		buf.WriteString(" FOR EACH ROW")
+	}
+
+	if t.when != nil {
+		buf.WriteString(" WHEN (" + inlineExpr(t.when) + ")")
+	}
+
+	args := make([]string, 0, len(t.args))
+
+	for _, arg := range t.args {
+		args = append(args, literal(arg))
+	}
+
+	buf.WriteString(" EXECUTE FUNCTION " + t.fn + "(" + strings.Join(args, ", ") + ")")
+	return buf.String()
+}
@@ -0,0 +1,200 @@
+package ddl
+
+import (
+	"strings"
+
+	"github.com/andrewpillar/query"
+)
+
+// View is a CREATE VIEW or CREATE MATERIALIZED VIEW statement.
+type View struct {
+	name         string
+	materialized bool
+	replace      bool
+	ifNotExists  bool
+	cols         []string
+	q            query.Query
+	noData       bool
+}
+
+// ViewOption is the type for the first-class functions that are used for
+// modifying a CREATE VIEW statement.
+type ViewOption func(v View) View
+
+// Refresh is a REFRESH MATERIALIZED VIEW statement.
+type Refresh struct {
+	name         string
+	concurrently bool
+	noData       bool
+}
+
+// RefreshOption is the type for the first-class functions that are used for
+// modifying a REFRESH MATERIALIZED VIEW statement.
+type RefreshOption func(r Refresh) Refresh
+
+// CreateView returns a CREATE VIEW statement for the given view, defined by the
+// given Query, applying the given options. The Query is built with its
+// arguments written as literals, since views do not support parameters, for
+// example,
+//
+//     v := ddl.CreateView(
+//         "published_posts",
+//         query.Select(
+//             query.Columns("*"),
+//             query.From("posts"),
+//             query.Where("published", "=", query.Arg(true)),
+//         ),
+//         ddl.OrReplace(),
+//     )
+//
+// would result in the following statement being built,
+//
+//     CREATE OR REPLACE VIEW published_posts AS SELECT * FROM posts WHERE (published = true)
+func CreateView(name string, q query.Query, opts ...ViewOption) View {
+	v := View{
+		name: name,
+		q:    q,
+	}
+
+	for _, opt := range opts {
+		v = opt(v)
+	}
+	return v
+}
+
+// CreateMaterializedView returns a CREATE MATERIALIZED VIEW statement for the
+// given view, defined by the given Query, applying the given options.
+func CreateMaterializedView(name string, q query.Query, opts ...ViewOption) View {
+	v := CreateView(name, q, opts...)
+	v.materialized = true
+	return v
+}
+
+// OrReplace adds OR REPLACE to a CREATE VIEW statement, so an existing view is
+// replaced. This is not supported for materialized views, and is ignored.
+func OrReplace() ViewOption {
+	return func(v View) View {
+		v.replace = true
+		return v
+	}
+}
+
+// ViewIfNotExists adds IF NOT EXISTS to a CREATE MATERIALIZED VIEW statement,
+// so no error occurs if the view already exists. This is not supported for
+// views that are not materialized, and is ignored.
+func ViewIfNotExists() ViewOption {
+	return func(v View) View {
+		v.ifNotExists = true
+		return v
+	}
+}
+
+// ViewColumns sets the names of the columns of the view.
+func ViewColumns(cols ...string) ViewOption {
+	return func(v View) View {
+		v.cols = cols
+		return v
+	}
+}
+
+// WithNoData adds WITH NO DATA to a CREATE MATERIALIZED VIEW statement, so the
+// view is not populated when it is created.
+func WithNoData() ViewOption {
+	return func(v View) View {
+		v.noData = true
+		return v
+	}
+}
+
+// Query returns the Query the view is defined by.
+func (v View) Query() query.Query { return v.q }
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a View can be used as a query.Expr.
+func (v View) Args() []interface{} { return nil }
+
+// Build builds the CREATE VIEW statement. The Query of the view is built with
+// its arguments written as literals.
+func (v View) Build() string {
+	var buf strings.Builder
+
+	buf.WriteString("CREATE ")
+
+	if v.replace && !v.materialized {
+		buf.WriteString("OR REPLACE ")
+	}
+
+	if v.materialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+
+	buf.WriteString("VIEW ")
+
+	if v.ifNotExists && v.materialized {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+
+	buf.WriteString(v.name)
+
+	if len(v.cols) > 0 {
+		buf.WriteString(" (" + strings.Join(v.cols, ", ") + ")")
+	}
+
+	buf.WriteString(" AS " + inline(v.q))
+
+	if v.noData && v.materialized {
+		buf.WriteString(" WITH NO DATA")
+	}
+	return buf.String()
+}
+
+// RefreshMaterializedView returns a REFRESH MATERIALIZED VIEW statement for the
+// given view, applying the given options.
+func RefreshMaterializedView(name string, opts ...RefreshOption) Refresh {
+	r := Refresh{
+		name: name,
+	}
+
+	for _, opt := range opts {
+		r = opt(r)
+	}
+	return r
+}
+
+// Concurrently refreshes the materialized view without locking out
+// concurrent selects on the view. This requires a UNIQUE index on the view.
+func Concurrently() RefreshOption {
+	return func(r Refresh) Refresh {
+		r.concurrently = true
+		return r
+	}
+}
+
+// RefreshWithNoData adds WITH NO DATA to the statement, so the materialized
+// view is left in an unscannable state.
+func RefreshWithNoData() RefreshOption {
+	return func(r Refresh) Refresh {
+		r.noData = true
+		return r
+	}
+}
+
+// Args returns nil, since DDL statements do not support parameters. This is
+// implemented so a Refresh can be used as a query.Expr.
+func (r Refresh) Args() []interface{} { return nil }
+
+// Build builds the REFRESH MATERIALIZED VIEW statement.
+func (r Refresh) Build() string {
+	s := "REFRESH MATERIALIZED VIEW "
+
+	if r.concurrently {
+		s += "CONCURRENTLY "
+	}
+
+	s += r.name
+
+	if r.noData {
+		s += " WITH NO DATA"
+	}
+	return s
+}
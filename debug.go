@@ -0,0 +1,35 @@
+package query
+
+import "fmt"
+
+// DebugString builds up the query with each of its arguments written inline as
+// a quoted literal, in place of its placeholder, for example,
+//
+//     SELECT * FROM posts WHERE (user_id = 10 AND title = 'It''s here')
+//
+// Placeholders within quoted strings and identifiers are left as is, as with
+// BuildInlined. This is meant for logging, and for copying a query into psql
+// when investigating an issue. The returned string should never be executed,
+// as arguments that cannot be written as a literal are formatted via fmt, and
+// quoted as strings.
+func (q Query) DebugString() string {
+	d := dialectOr(q.dialect)
+
+	s, args, _ := q.write(d)
+	s, _, _ = inline(s, d.args(unnamed(args)), func(arg interface{}) (string, error) {
+		return debugLiteral(d, arg), nil
+	})
+	return s
+}
+
+// debugLiteral returns the given argument as a literal for the given Dialect.
+// Arguments that cannot be written as a literal are formatted via fmt and
+// quoted as strings.
+func debugLiteral(d *Dialect, arg interface{}) string {
+	lit, err := d.literal(arg)
+
+	if err != nil {
+		return quoteString(fmt.Sprint(arg))
+	}
+	return lit
+}
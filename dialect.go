@@ -0,0 +1,425 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes how a Query should be built for a particular database.
+// A Dialect can be set for a single Query via WithDialect, or for every Query
+// via DefaultDialect.
+type Dialect struct {
+	name         string
+	version      string
+	placeholder  Placeholder
+	quote        [2]string
+	returning    bool
+	maxParams    int
+	ignore       string
+	duplicateKey bool
+	noBool       bool
+	autoQuote    bool
+	rewrite      Option
+	lit          literalFunc
+}
+
+// dialectExpr is an Expr whose built form depends on the Dialect the Query is
+// being built for.
+type dialectExpr interface {
+	buildFor(d *Dialect) string
+}
+
+var (
+	// Postgres is the Dialect for PostgreSQL.
+	Postgres = &Dialect{
+		name:        "postgres",
+		placeholder: Dollar,
+		maxParams:   MaxParams,
+		quote:       [2]string{`"`, `"`},
+		returning:   true,
+		lit:         pgLiteral,
+	}
+
+	// MySQL is the Dialect for MySQL. RETURNING clauses are not supported, so
+	// will be dropped from the built Query. An OFFSET clause without a LIMIT
+	// clause will have the maximum LIMIT added, since MySQL does not allow
+	// OFFSET on its own.
+	MySQL = &Dialect{
+		name:         "mysql",
+		placeholder:  Question,
+		maxParams:    65535,
+		quote:        [2]string{"`", "`"},
+		ignore:       "IGNORE",
+		duplicateKey: true,
+		rewrite:      mysqlRewrite,
+	}
+
+	// SQLite is the Dialect for SQLite 3.35.0 and later, which supports
+	// RETURNING clauses. Use SQLiteVersion for older versions of SQLite. An
+	// OFFSET clause without a LIMIT clause will have LIMIT -1 added, since
+	// SQLite does not allow OFFSET on its own.
+	SQLite = &Dialect{
+		name:        "sqlite",
+		placeholder: Question,
+		maxParams:   32766,
+		quote:       [2]string{`"`, `"`},
+		returning:   true,
+		ignore:      "OR IGNORE",
+		rewrite:     sqliteRewrite,
+	}
+
+	// SQLServer is the Dialect for SQL Server. LIMIT and OFFSET clauses are
+	// built as OFFSET ... FETCH, and if the Query has no ORDER BY clause then
+	// ORDER BY (SELECT NULL) is added, since SQL Server requires one.
+	// RETURNING clauses are not supported, so will be dropped from the built
+	// Query.
+	SQLServer = &Dialect{
+		name:        "sqlserver",
+		placeholder: AtP,
+		maxParams:   2100,
+		quote:       [2]string{"[", "]"},
+		rewrite:     sqlserverRewrite,
+	}
+
+	// Oracle is the Dialect for Oracle Database 12c and later. LIMIT and OFFSET
+	// clauses are built as OFFSET ... FETCH FIRST. Since Oracle does not have
+	// boolean literals, boolean arguments and literals are given as 1 and 0.
+	// RETURNING clauses are not supported, so will be dropped from the built
+	// Query.
+	Oracle = &Dialect{
+		name:        "oracle",
+		placeholder: Colon,
+		maxParams:   65535,
+		quote:       [2]string{`"`, `"`},
+		noBool:      true,
+		rewrite:     oracleRewrite,
+	}
+
+	// DefaultDialect is the Dialect used for a Query that has not been given
+	// one via WithDialect.
+	DefaultDialect = Postgres
+)
+
+// SQLiteVersion returns the Dialect for the given version of SQLite, as would
+// be returned from sqlite_version(). RETURNING clauses will be dropped from the
+// built Query for versions older than 3.35.0, since they are not supported.
+func SQLiteVersion(version string) *Dialect {
+	var v [3]int
+
+	for i, part := range strings.SplitN(version, ".", 3) {
+		v[i], _ = strconv.Atoi(part)
+	}
+
+	if v[0] > 3 || (v[0] == 3 && v[1] >= 35) {
+		return SQLite
+	}
+
+	d := *SQLite
+	d.version = version
+	d.returning = false
+	d.rewrite = sqliteRewriteNoReturning
+
+	// SQLite versions before 3.32.0 have a much lower limit on the number of
+	// parameters.
+	if v[0] < 3 || (v[0] == 3 && v[1] < 32) {
+		d.maxParams = 999
+	}
+	return &d
+}
+
+// QuoteIdents enables the automatic quoting of identifiers in the Query, see
+// Dialect.Quoted for the identifiers that are quoted.
+func QuoteIdents() Option {
+	return func(q Query) Query {
+		q.quote = true
+		return q
+	}
+}
+
+// WithDialect sets the Dialect the Query will be built for.
+func WithDialect(d *Dialect) Option {
+	return func(q Query) Query {
+		q.dialect = d
+		return q
+	}
+}
+
+// dialectOr returns the given Dialect, or the DefaultDialect if nil.
+func dialectOr(d *Dialect) *Dialect {
+	if d != nil {
+		return d
+	}
+	if DefaultDialect != nil {
+		return DefaultDialect
+	}
+	return Postgres
+}
+
+// buildExpr builds the given expression for the given Dialect. This is used
+// for the expressions that have no other expressions within them, such as
+// identifiers and literals, an expression that does is written via an
+// SQLWriter along with its arguments.
+func buildExpr(e Expr, d *Dialect) string {
+	if de, ok := e.(dialectExpr); ok {
+		return de.buildFor(d)
+	}
+
+	if se, ok := e.(SQLExpr); ok {
+		return buildSQL(se, d)
+	}
+	return e.Build()
+}
+
+// Name returns the name of the Dialect.
+func (d *Dialect) Name() string { return d.name }
+
+// Placeholder returns the placeholder style used by the Dialect.
+func (d *Dialect) Placeholder() Placeholder { return d.placeholder }
+
+// MaxParams returns the maximum number of parameters the Dialect allows in a
+// single query.
+func (d *Dialect) MaxParams() int { return d.maxParams }
+
+// Returning reports whether the Dialect supports RETURNING clauses.
+func (d *Dialect) Returning() bool { return d.returning }
+
+// Quote quotes the given identifier for the Dialect. Each part of a qualified
+// identifier, such as schema.table, is quoted separately, and any quote
+// characters within the identifier are escaped by doubling them. The * in a
+// qualified identifier is left unquoted.
+func (d *Dialect) Quote(ident string) string {
+	parts := strings.Split(ident, ".")
+
+	for i, part := range parts {
+		if part == "*" {
+			continue
+		}
+		parts[i] = d.quote[0] + strings.ReplaceAll(part, d.quote[1], d.quote[1]+d.quote[1]) + d.quote[1]
+	}
+	return strings.Join(parts, ".")
+}
+
+// dropReturning removes any RETURNING clauses from the Query.
+func dropReturning(q Query) Query {
+	clauses := make([]clause, 0, len(q.clauses))
+
+	for _, cl := range q.clauses {
+		if cl.kind() != _ReturningClause {
+			clauses = append(clauses, cl)
+		}
+	}
+
+	q.clauses = clauses
+	return q
+}
+
+// Quoted returns a copy of the Dialect that will automatically quote the
+// identifiers in a Query when it is built. This covers table names, column
+// names, and the columns given to clauses such as ORDER BY and RETURNING.
+// Qualified identifiers such as schema.table, and aliases such as "users u"
+// or "users AS u" are handled. Anything that does not look like an identifier,
+// such as a function call, is left as is, for example,
+//
+//     query.DefaultDialect = query.Postgres.Quoted()
+func (d *Dialect) Quoted() *Dialect {
+	quoted := *d
+	quoted.autoQuote = true
+	return &quoted
+}
+
+var identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// ident quotes the given identifier if the Dialect automatically quotes
+// identifiers.
+func (d *Dialect) ident(s string) string {
+	if !d.autoQuote {
+		return s
+	}
+
+	parts := strings.Fields(s)
+
+	switch len(parts) {
+	case 1:
+		if name, ok := d.quoteName(parts[0]); ok {
+			return name
+		}
+	case 2:
+		name, ok1 := d.quoteName(parts[0])
+		alias, ok2 := d.quoteName(parts[1])
+
+		if ok1 && ok2 {
+			return name + " " + alias
+		}
+	case 3:
+		if strings.EqualFold(parts[1], "AS") {
+			name, ok1 := d.quoteName(parts[0])
+			alias, ok2 := d.quoteName(parts[2])
+
+			if ok1 && ok2 {
+				return name + " " + parts[1] + " " + alias
+			}
+		}
+	}
+	return s
+}
+
+// idents quotes each of the given identifiers if the Dialect automatically
+// quotes identifiers.
+func (d *Dialect) idents(ss []string) []string {
+	if !d.autoQuote {
+		return ss
+	}
+
+	quoted := make([]string, 0, len(ss))
+
+	for _, s := range ss {
+		quoted = append(quoted, d.ident(s))
+	}
+	return quoted
+}
+
+// quoteName quotes each part of the given possibly qualified name. False is
+// returned if any part of the name is not an identifier.
+func (d *Dialect) quoteName(s string) (string, bool) {
+	parts := strings.Split(s, ".")
+
+	for i, part := range parts {
+		if part == "*" && i == len(parts)-1 {
+			continue
+		}
+
+		if strings.HasPrefix(part, d.quote[0]) && strings.HasSuffix(part, d.quote[1]) && len(part) > 1 {
+			continue
+		}
+
+		if !identPattern.MatchString(part) {
+			return "", false
+		}
+		parts[i] = d.quote[0] + part + d.quote[1]
+	}
+	return strings.Join(parts, "."), true
+}
+
+// limitOffset adds a LIMIT clause of n before the OFFSET clause of the Query,
+// if it has an OFFSET clause without a LIMIT clause, for the databases that do
+// not allow OFFSET on its own.
+func limitOffset(q Query, n int64) Query {
+	var limit, offset bool
+
+	for _, cl := range q.clauses {
+		switch cl.kind() {
+		case _LimitClause:
+			limit = true
+		case _OffsetClause:
+			offset = true
+		}
+	}
+
+	if offset && !limit {
+		q = q.insertBefore(limitClause(n), _OffsetClause)
+	}
+	return q
+}
+
+func mysqlRewrite(q Query) Query {
+	return limitOffset(dropReturning(q), 1<<63-1)
+}
+
+// sqliteRewrite adds LIMIT -1 to an OFFSET without a LIMIT, which SQLite takes
+// as no limit.
+func sqliteRewrite(q Query) Query {
+	return limitOffset(q, -1)
+}
+
+// sqliteRewriteNoReturning rewrites the Query in the same way as sqliteRewrite
+// for the versions of SQLite that do not support RETURNING clauses.
+func sqliteRewriteNoReturning(q Query) Query {
+	return sqliteRewrite(dropReturning(q))
+}
+
+// fetch replaces the LIMIT and OFFSET clauses in the Query with a single
+// OFFSET ... FETCH clause, placed where the first of them was. If first is
+// true then FETCH FIRST is used, and the OFFSET is omitted when zero.
+func fetch(q Query, first bool) (Query, bool) {
+	var (
+		c       fetchClause
+		found   bool
+		clauses []clause
+	)
+
+	c.first = first
+
+	at := -1
+
+	for _, cl := range q.clauses {
+		switch v := cl.(type) {
+		case limitClause:
+			c.limit = int64(v)
+			c.fetch = true
+		case offsetClause:
+			c.offset = int64(v)
+		default:
+			clauses = append(clauses, cl)
+			continue
+		}
+
+		if !found {
+			at = len(clauses)
+			found = true
+		}
+	}
+
+	if !found {
+		return q, false
+	}
+
+	q.clauses = append(clauses[:at:at], append([]clause{c}, clauses[at:]...)...)
+	return q, true
+}
+
+func sqlserverRewrite(q Query) Query {
+	q = dropReturning(q)
+
+	q, ok := fetch(q, false)
+
+	if !ok {
+		return q
+	}
+
+	for _, cl := range q.clauses {
+		if cl.kind() == _OrderClause {
+			return q
+		}
+	}
+
+	return q.insertBefore(orderClause{
+		cols: []string{"(SELECT NULL)"},
+	}, _FetchClause)
+}
+
+func oracleRewrite(q Query) Query {
+	q, _ = fetch(dropReturning(q), true)
+	return q
+}
+
+// args returns the given arguments converted for the Dialect.
+func (d *Dialect) args(args []interface{}) []interface{} {
+	if !d.noBool {
+		return args
+	}
+
+	conv := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		if b, ok := arg.(bool); ok {
+			arg = 0
+
+			if b {
+				arg = 1
+			}
+		}
+		conv = append(conv, arg)
+	}
+	return conv
+}
@@ -0,0 +1,149 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// Execer is the interface that wraps the context aware methods for executing
+// queries. This is satisfied by *sql.DB, *sql.Conn, and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+var (
+	_ Execer = (*sql.DB)(nil)
+	_ Execer = (*sql.Conn)(nil)
+	_ Execer = (*sql.Tx)(nil)
+)
+
+// Runner is the interface implemented by Query for building a query, and
+// executing it on a database. Code that executes queries can accept a Runner
+// rather than a Query, so a fake can be given in its place in tests, along
+// with a fake Execer for the database, for example,
+//
+//     type fakeRunner struct {
+//         query.Runner
+//     }
+//
+//     func (fakeRunner) ExecContext(ctx context.Context, db query.Execer) (sql.Result, error) {
+//         return nil, errors.New("connection reset")
+//     }
+type Runner interface {
+	Expr
+
+	// ExecContext executes the query on the given database.
+	ExecContext(ctx context.Context, db Execer) (sql.Result, error)
+
+	// QueryContext executes the query on the given database, returning the
+	// rows.
+	QueryContext(ctx context.Context, db Execer) (*sql.Rows, error)
+
+	// QueryRowContext executes the query on the given database, returning at
+	// most one row.
+	QueryRowContext(ctx context.Context, db Execer) *sql.Row
+}
+
+var _ Runner = Query{}
+
+// ExecContext builds up the query and executes it on the given database along
+// with its arguments, for example,
+//
+//     _, err := query.Delete("posts", query.Where("id", "=", query.Arg(id))).ExecContext(ctx, db)
+//
+// If the query cannot be built, such as for ErrTenant, then it is not executed
+// and the error is returned.
+func (q Query) ExecContext(ctx context.Context, db Execer) (sql.Result, error) {
+	s, args, err := q.compile()
+
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, s, args...)
+}
+
+// QueryContext builds up the query and executes it on the given database along
+// with its arguments, returning the rows. If the query cannot be built then it
+// is not executed and the error is returned.
+func (q Query) QueryContext(ctx context.Context, db Execer) (*sql.Rows, error) {
+	s, args, err := q.compile()
+
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, s, args...)
+}
+
+// QueryRowContext builds up the query and executes it on the given database
+// along with its arguments, returning at most one row. If the query cannot be
+// built then it is not executed, and the error is returned from the Scan of
+// the row.
+func (q Query) QueryRowContext(ctx context.Context, db Execer) *sql.Row {
+	s, args, err := q.compile()
+
+	if err != nil {
+		return db.QueryRowContext(ctx, s, errArg{err: err})
+	}
+	return db.QueryRowContext(ctx, s, args...)
+}
+
+// errArg is an argument that fails to be converted for the driver with the
+// error it wraps. This is how QueryRowContext returns an error via a *sql.Row,
+// as the arguments of a query are converted before it is executed.
+type errArg struct {
+	err error
+}
+
+func (a errArg) Value() (driver.Value, error) { return nil, a.err }
+
+// TxBeginner is the interface that wraps the BeginTx method. This is satisfied
+// by *sql.DB and *sql.Conn.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+var (
+	_ TxBeginner = (*sql.DB)(nil)
+	_ TxBeginner = (*sql.Conn)(nil)
+)
+
+// WithTx begins a transaction on the given database, and calls the given
+// function with the transaction. If the function returns an error, or panics,
+// then the transaction is rolled back, otherwise it is committed, for example,
+//
+//     err := query.WithTx(ctx, db, func(tx query.Execer) error {
+//         if _, err := debit.ExecContext(ctx, tx); err != nil {
+//             return err
+//         }
+//
+//         _, err := credit.ExecContext(ctx, tx)
+//         return err
+//     })
+//
+// The error returned from the function is returned as is, so it can be checked
+// via errors.Is.
+func WithTx(ctx context.Context, db TxBeginner, fn func(tx Execer) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			tx.Rollback()
+			panic(v)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
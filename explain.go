@@ -0,0 +1,126 @@
+package query
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// explainExpr is the expression for the options and the Query being explained
+// in an EXPLAIN statement.
+type explainExpr struct {
+	opts []string
+	q    Query
+}
+
+// ExplainOption is the type for the first-class functions that set the
+// options of an EXPLAIN statement.
+type ExplainOption func(opts []string) []string
+
+// Plan is a single node in the plan of a query, as decoded from the output of
+// an EXPLAIN statement with the JSON format. The actual fields are only set
+// when the statement was given the Analyze option.
+type Plan struct {
+	NodeType           string  `json:"Node Type"`
+	ParentRelationship string  `json:"Parent Relationship"`
+	RelationName       string  `json:"Relation Name"`
+	Schema             string  `json:"Schema"`
+	Alias              string  `json:"Alias"`
+	IndexName          string  `json:"Index Name"`
+	IndexCond          string  `json:"Index Cond"`
+	Filter             string  `json:"Filter"`
+	JoinType           string  `json:"Join Type"`
+	StartupCost        float64 `json:"Startup Cost"`
+	TotalCost          float64 `json:"Total Cost"`
+	PlanRows           float64 `json:"Plan Rows"`
+	PlanWidth          int     `json:"Plan Width"`
+	ActualStartupTime  float64 `json:"Actual Startup Time"`
+	ActualTotalTime    float64 `json:"Actual Total Time"`
+	ActualRows         float64 `json:"Actual Rows"`
+	ActualLoops        float64 `json:"Actual Loops"`
+	RowsRemoved        float64 `json:"Rows Removed by Filter"`
+	Plans              []Plan  `json:"Plans"`
+}
+
+// Explained is the output of an EXPLAIN statement with the JSON format. The
+// timings are in milliseconds, and the execution time is only set when the
+// statement was given the Analyze option.
+type Explained struct {
+	Plan          Plan    `json:"Plan"`
+	PlanningTime  float64 `json:"Planning Time"`
+	ExecutionTime float64 `json:"Execution Time"`
+}
+
+// Explain returns an EXPLAIN statement for the given Query, applying the given
+// options, for example,
+//
+//     q := query.Explain(
+//         query.Select(query.Columns("*"), query.From("posts"), query.Where("user_id", "=", query.Arg(10))),
+//         query.Analyze(),
+//         query.FormatJSON(),
+//     )
+//
+// would result in the following query being built,
+//
+//     EXPLAIN (ANALYZE, FORMAT JSON) SELECT * FROM posts WHERE (user_id = $1)
+//
+// The output of the statement with the JSON format can be decoded via
+// DecodePlan.
+func Explain(q Query, opts ...ExplainOption) Query {
+	e := explainExpr{q: q}
+
+	for _, opt := range opts {
+		e.opts = opt(e.opts)
+	}
+
+	return Query{
+		stmt:    _Explain,
+		exprs:   []Expr{e},
+		dialect: q.dialect,
+	}
+}
+
+func explainOpt(opt string) ExplainOption {
+	return func(opts []string) []string {
+		return append(opts, opt)
+	}
+}
+
+// Analyze executes the query being explained, so the actual run times are
+// included in the plan.
+func Analyze() ExplainOption { return explainOpt("ANALYZE") }
+
+// Verbose includes additional information in the plan, such as the output
+// columns of each node.
+func Verbose() ExplainOption { return explainOpt("VERBOSE") }
+
+// Buffers includes the buffer usage in the plan. This is only used along with
+// Analyze.
+func Buffers() ExplainOption { return explainOpt("BUFFERS") }
+
+// FormatJSON outputs the plan as JSON, which can be decoded via DecodePlan.
+func FormatJSON() ExplainOption { return explainOpt("FORMAT JSON") }
+
+// DecodePlan decodes the given output of an EXPLAIN statement with the JSON
+// format.
+func DecodePlan(b []byte) (Explained, error) {
+	var explained []Explained
+
+	if err := json.Unmarshal(b, &explained); err != nil {
+		return Explained{}, err
+	}
+
+	if len(explained) == 0 {
+		return Explained{}, nil
+	}
+	return explained[0], nil
+}
+
+func (e explainExpr) Args() []interface{} { return e.q.Args() }
+func (e explainExpr) Build() string       { return buildSQL(e, nil) }
+
+func (e explainExpr) WriteSQL(w *SQLWriter) {
+	if len(e.opts) > 0 {
+		w.WriteString("(" + strings.Join(e.opts, ", ") + ") ")
+	}
+	w.WriteExpr(e.q)
+}
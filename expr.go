@@ -1,6 +1,7 @@
 package query
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 )
@@ -19,6 +20,8 @@ type listExpr struct {
 
 type identExpr string
 
+type quoteExpr string
+
 type argExpr struct {
 	val interface{}
 }
@@ -27,6 +30,19 @@ type litExpr struct {
 	val interface{}
 }
 
+// subqueryExpr is a Query used as an expression within another Query, this is
+// wrapped in parentheses when built.
+type subqueryExpr struct {
+	q Query
+}
+
+// rawExpr is a raw piece of SQL using ? as the placeholder for the arguments
+// it is given.
+type rawExpr struct {
+	sql  string
+	args []interface{}
+}
+
 // Expr is an expression that exists within the Query being built. This would
 // typically be an identifier, literal, argument, function call, or list
 // values in queries.
@@ -41,9 +57,12 @@ type Expr interface {
 var (
 	_ Expr = (*listExpr)(nil)
 	_ Expr = (*identExpr)(nil)
+	_ Expr = (*quoteExpr)(nil)
 	_ Expr = (*argExpr)(nil)
 	_ Expr = (*litExpr)(nil)
 	_ Expr = (*callExpr)(nil)
+	_ Expr = (*rawExpr)(nil)
+	_ Expr = (*subqueryExpr)(nil)
 )
 
 // Columns returns a list expression of the given column names. This will not
@@ -97,6 +116,11 @@ func List(vals ...interface{}) listExpr {
 // this will simply use the initial string that was given.
 func Ident(s string) identExpr { return identExpr(s) }
 
+// Quote returns a quoted identifier expression for the given string. When
+// built this will quote the identifier for the Dialect the Query is being
+// built for, for example "order" for PostgreSQL or `order` for MySQL.
+func Quote(s string) quoteExpr { return quoteExpr(s) }
+
 // Arg returns an argument expression for the given value. When built this will
 // use ? as the placeholder for the argument value.
 func Arg(val interface{}) argExpr {
@@ -105,6 +129,24 @@ func Arg(val interface{}) argExpr {
 	}
 }
 
+// Named returns a named argument expression for the given name and value. When
+// built this will use ? as the placeholder for the argument, unless the Query
+// is built with either the AtName or ColonName placeholder styles, in which
+// case the name will be used, for example,
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Named("user_id", 10)),
+//     )
+//
+//     db.Query(q.BuildWith(query.AtName), q.NamedArgs()...)
+func Named(name string, val interface{}) argExpr {
+	return argExpr{
+		val: sql.Named(name, val),
+	}
+}
+
 // Lit returns a literal expression for the given value. This will place the
 // literal value into the built up expression string itself, and not use the ?
 // placeholder. For example using Lit like so,
@@ -120,10 +162,26 @@ func Lit(val interface{}) litExpr {
 	}
 }
 
+// Raw returns a raw expression for the given SQL and arguments. The SQL should
+// use ? as the placeholder for each of the given arguments, for example,
+//
+//     query.Raw("count + ?", 1)
+//
+// The SQL is placed into the built up query as is, so it should never contain
+// any user input.
+func Raw(sql string, args ...interface{}) rawExpr {
+	return rawExpr{
+		sql:  sql,
+		args: args,
+	}
+}
+
 func (e listExpr) Args() []interface{} { return e.args }
 
-func (e listExpr) Build() string {
-	items := strings.Join(e.items, ", ")
+func (e listExpr) Build() string { return e.buildFor(dialectOr(nil)) }
+
+func (e listExpr) buildFor(d *Dialect) string {
+	items := strings.Join(d.idents(e.items), ", ")
 
 	if e.wrap {
 		return "(" + items + ")"
@@ -134,12 +192,35 @@ func (e listExpr) Build() string {
 func (e identExpr) Args() []interface{} { return nil }
 func (e identExpr) Build() string       { return string(e) }
 
+func (e identExpr) buildFor(d *Dialect) string { return d.ident(string(e)) }
+
+func (e quoteExpr) Args() []interface{}        { return nil }
+func (e quoteExpr) Build() string              { return e.buildFor(dialectOr(nil)) }
+func (e quoteExpr) buildFor(d *Dialect) string { return d.Quote(string(e)) }
+
 func (e argExpr) Args() []interface{} { return []interface{}{e.val} }
 func (e argExpr) Build() string       { return "?" }
 
 func (e litExpr) Args() []interface{} { return nil }
 func (e litExpr) Build() string       { return fmt.Sprintf("%v", e.val) }
 
+func (e litExpr) buildFor(d *Dialect) string {
+	if b, ok := e.val.(bool); ok && d.noBool {
+		if b {
+			return "1"
+		}
+		return "0"
+	}
+	return e.Build()
+}
+
+func (e rawExpr) Args() []interface{} { return e.args }
+func (e rawExpr) Build() string       { return e.sql }
+
+func (e subqueryExpr) Args() []interface{}   { return e.q.Args() }
+func (e subqueryExpr) Build() string         { return "(" + e.q.buildInitial() + ")" }
+func (e subqueryExpr) WriteSQL(w *SQLWriter) { w.WriteSubquery(e.q) }
+
 func (e callExpr) Args() []interface{} {
 	vals := make([]interface{}, 0)
 
@@ -157,3 +238,10 @@ func (e callExpr) Build() string {
 	}
 	return e.name + "(" + strings.Join(args, ", ") + ")"
 }
+
+func (e callExpr) WriteSQL(w *SQLWriter) {
+	w.WriteString(e.name)
+	w.WriteByte('(')
+	w.WriteExprs(", ", e.args...)
+	w.WriteByte(')')
+}
@@ -0,0 +1,235 @@
+// Package filter provides a way of translating the query string of a URL into
+// a set of query.Option functions. Only the columns that have been explicitly
+// allowed can be filtered and sorted on.
+//
+// Filters are given as a parameter for the column, with an optional operator
+// wrapped in square brackets, for example,
+//
+//     status=open&created_at[gte]=2024-01-01&sort=-created_at&limit=50
+//
+// would result in the following query options,
+//
+//     query.Where("status", "=", query.Arg("open")),
+//     query.Where("created_at", ">=", query.Arg("2024-01-01")),
+//     query.OrderDesc("created_at"),
+//     query.Limit(50),
+//
+// The supported operators are eq, ne, gt, gte, lt, lte, like, ilike, in, and
+// null. The in operator takes a comma separated list of values, and the null
+// operator takes either true or false.
+package filter
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrewpillar/query"
+)
+
+// Filter is the configuration for the filters that can be parsed from a URL
+// query string.
+type Filter struct {
+	// Columns maps the name of a parameter that can be filtered on to the
+	// column in the query.
+	Columns map[string]string
+
+	// Sort maps the name of a parameter that can be sorted on to the column
+	// in the query.
+	Sort map[string]string
+
+	// MaxLimit is the maximum value that can be given for the limit, and the
+	// limit used when none is given. If zero then no maximum is enforced.
+	MaxLimit int64
+}
+
+// Error records the parameter that caused a filter to fail parsing.
+type Error struct {
+	Param string
+	Err   error
+}
+
+// ErrOperator is returned when an unknown operator is given for a filter.
+var ErrOperator = errors.New("unknown operator")
+
+var ops = map[string]query.Op{
+	"eq":    query.OpEq,
+	"ne":    query.OpNe,
+	"gt":    query.OpGt,
+	"gte":   query.OpGe,
+	"lt":    query.OpLt,
+	"lte":   query.OpLe,
+	"like":  query.OpLike,
+	"ilike": query.OpILike,
+}
+
+func (e *Error) Error() string { return "filter: " + e.Param + ": " + e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Parse parses the given URL values into a single query.Option. Parameters
+// that are not an allowed column, or are not one of sort, limit, or offset are
+// ignored, and so are the columns given to sort that are not allowed. If no
+// limit is given then MaxLimit is used, if set. The parameters are parsed in
+// sorted order so the resulting query is deterministic.
+func (f Filter) Parse(vals url.Values) (query.Option, error) {
+	keys := make([]string, 0, len(vals))
+
+	for key := range vals {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	opts := make([]query.Option, 0, len(keys))
+
+	var (
+		order         []query.Option
+		limit, offset query.Option
+	)
+
+	for _, key := range keys {
+		switch key {
+		case "sort":
+			for _, v := range vals[key] {
+				order = append(order, f.parseSort(v)...)
+			}
+			continue
+		case "limit":
+			n, err := f.parseInt(vals.Get(key))
+
+			if err != nil {
+				return nil, &Error{Param: key, Err: err}
+			}
+
+			if f.MaxLimit > 0 && n > f.MaxLimit {
+				n = f.MaxLimit
+			}
+			limit = query.Limit(n)
+			continue
+		case "offset":
+			n, err := f.parseInt(vals.Get(key))
+
+			if err != nil {
+				return nil, &Error{Param: key, Err: err}
+			}
+			offset = query.Offset(n)
+			continue
+		}
+
+		name, op := key, "eq"
+
+		if i := strings.Index(key, "["); i > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:i], key[i+1:len(key)-1]
+		}
+
+		col, ok := f.Columns[name]
+
+		if !ok {
+			continue
+		}
+
+		for _, v := range vals[key] {
+			opt, err := where(col, op, v)
+
+			if err != nil {
+				return nil, &Error{Param: key, Err: err}
+			}
+			opts = append(opts, opt)
+		}
+	}
+
+	// Make sure ORDER BY, LIMIT, and OFFSET come after the WHERE clauses, since
+	// the clauses are built in the order they are given.
+	opts = append(opts, order...)
+
+	if limit == nil && f.MaxLimit > 0 {
+		limit = query.Limit(f.MaxLimit)
+	}
+
+	if limit != nil {
+		opts = append(opts, limit)
+	}
+	if offset != nil {
+		opts = append(opts, offset)
+	}
+	return query.Options(opts...), nil
+}
+
+func (f Filter) parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 0 {
+		return 0, errors.New("negative value")
+	}
+	return n, nil
+}
+
+// parseSort parses a comma separated list of columns to sort on, a column
+// prefixed with - is sorted in descending order. Columns that are not allowed
+// are skipped.
+func (f Filter) parseSort(s string) []query.Option {
+	opts := make([]query.Option, 0)
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		col, ok := f.Sort[name]
+
+		if !ok {
+			continue
+		}
+
+		if desc {
+			opts = append(opts, query.OrderDesc(col))
+			continue
+		}
+		opts = append(opts, query.OrderAsc(col))
+	}
+	return opts
+}
+
+func where(col, op, val string) (query.Option, error) {
+	switch op {
+	case "in":
+		items := strings.Split(val, ",")
+		args := make([]interface{}, 0, len(items))
+
+		for _, item := range items {
+			args = append(args, item)
+		}
+		return query.WhereOp(col, query.OpIn, query.List(args...)), nil
+	case "null":
+		null, err := strconv.ParseBool(val)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if null {
+			return query.WhereOp(col, query.OpIs, query.Lit("NULL")), nil
+		}
+		return query.WhereOp(col, query.OpIsNot, query.Lit("NULL")), nil
+	}
+
+	sqlop, ok := ops[op]
+
+	if !ok {
+		return nil, ErrOperator
+	}
+	return query.WhereOp(col, sqlop, query.Arg(val)), nil
+}
@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/andrewpillar/query"
+)
+
+func Test_Parse(t *testing.T) {
+	f := Filter{
+		Columns: map[string]string{
+			"status":     "status",
+			"created_at": "created_at",
+			"tag":        "tags.name",
+			"deleted":    "deleted_at",
+		},
+		Sort: map[string]string{
+			"created_at": "created_at",
+			"title":      "title",
+		},
+		MaxLimit: 100,
+	}
+
+	tests := []struct {
+		qs       string
+		expected string
+		args     int
+	}{
+		{
+			"status=open&created_at[gte]=2024-01-01&sort=-created_at&limit=50",
+			"SELECT * FROM issues WHERE (created_at >= $1 AND status = $2) ORDER BY created_at DESC LIMIT 50",
+			2,
+		},
+		{
+			"tag[in]=a,b&deleted[null]=true&sort=title,-created_at&limit=500&offset=10&page=2",
+			"SELECT * FROM issues WHERE (deleted_at IS NULL AND tags.name IN ($1, $2)) ORDER BY title ASC, created_at DESC LIMIT 100 OFFSET 10",
+			2,
+		},
+		{
+			"secret=1&sort=-password,title",
+			"SELECT * FROM issues ORDER BY title ASC LIMIT 100",
+			0,
+		},
+	}
+
+	for i, test := range tests {
+		vals, err := url.ParseQuery(test.qs)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opt, err := f.Parse(vals)
+
+		if err != nil {
+			t.Fatalf("tests[%d]: %s\n", i, err)
+		}
+
+		q := query.Select(query.Columns("*"), query.From("issues"), opt)
+
+		if built := q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if n := len(q.Args()); n != test.args {
+			t.Errorf("tests[%d]: expected %d args, got %d\n", i, test.args, n)
+		}
+	}
+}
+
+func Test_ParseErrors(t *testing.T) {
+	f := Filter{
+		Columns: map[string]string{"status": "status"},
+		Sort:    map[string]string{"title": "title"},
+	}
+
+	tests := []struct {
+		qs  string
+		err error
+	}{
+		{"status[drop]=1", ErrOperator},
+	}
+
+	for i, test := range tests {
+		vals, _ := url.ParseQuery(test.qs)
+
+		if _, err := f.Parse(vals); !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %q, got %v\n", i, test.err, err)
+		}
+	}
+
+	vals, _ := url.ParseQuery("limit=-1")
+
+	if _, err := f.Parse(vals); err == nil {
+		t.Errorf("expected error for negative limit\n")
+	}
+}
@@ -0,0 +1,327 @@
+package query
+
+import (
+	"database/sql"
+	"hash/fnv"
+	"strconv"
+	"sync"
+)
+
+// Cache is a cache of built queries, keyed by the shape of each Query, as used
+// for its Fingerprint. This allows for queries of the same shape to only be
+// built once, for example,
+//
+//     var cache query.Cache
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(id)),
+//     )
+//
+//     rows, err := db.Query(cache.Build(q), q.Args()...)
+//
+// Queries with a different number of items in a list, such as for WhereIn,
+// have a different shape, so each is cached separately. Once the cache holds
+// Max queries, a random query is evicted for each new query that is added. The
+// zero value of a Cache is ready to use, and it is safe for concurrent use.
+type Cache struct {
+	// Max is the maximum number of queries held in the cache. If this is zero
+	// then DefaultCacheSize is used.
+	Max int
+
+	mu      sync.RWMutex
+	queries map[string]string
+}
+
+// DefaultCacheSize is the maximum number of queries held in a Cache that has
+// no Max set.
+var DefaultCacheSize = 1024
+
+// shape is the buffer the shape of a Query is written to before it is hashed.
+// If normalize is set then the values that vary between executions of the same
+// query, such as the number of items in a list, are left out of the shape. If
+// any Query in the shape has an error recorded then errs is set, since these
+// can depend on the values of the Query, and not just its shape.
+type shape struct {
+	buf       []byte
+	normalize bool
+	errs      bool
+}
+
+// Fingerprint returns a hash of the shape of the Query. Queries with the same
+// statement, table, expressions, and clauses will have the same fingerprint,
+// even if the values of their arguments differ. The only exception to this is
+// a Query that has Dedupe set, where the placeholders of the built query
+// depend on which arguments are repeated. The shape is taken from the Query as
+// it was given, after any deferred options and hooks, so the Query does not
+// need to be prepared for building to get its fingerprint.
+func (q Query) Fingerprint() uint64 { return q.fingerprint(false) }
+
+// NormalizedFingerprint returns a hash of the normalized shape of the Query.
+// This is the same as Fingerprint, only the values that would typically vary
+// between executions of the same query are normalized, these are,
+//
+//     the number of items in a list of arguments, such as for WHERE IN
+//     the number of rows in an INSERT with multiple VALUES clauses
+//     the values of LIMIT and OFFSET clauses
+//     numeric and boolean literals
+//
+// and the arguments that are repeated in a Query with Dedupe set. This is
+// meant for grouping queries in metrics and slow query reports, where a
+// fingerprint for each page of results would be too many. This should not be
+// used as the key when caching the built query, since queries with different
+// built SQL can have the same normalized fingerprint.
+func (q Query) NormalizedFingerprint() uint64 { return q.fingerprint(true) }
+
+func (q Query) fingerprint(normalize bool) uint64 {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	h := shape{
+		buf:       *buf,
+		normalize: normalize,
+	}
+	h.query(q, dialectOr(q.dialect))
+
+	*buf = h.buf
+
+	fp := fnv.New64a()
+	fp.Write(h.buf)
+	return fp.Sum64()
+}
+
+func (h *shape) str(s string) {
+	h.buf = append(h.buf, s...)
+	h.buf = append(h.buf, 0)
+}
+
+func (h *shape) int(i int64) {
+	h.buf = strconv.AppendInt(h.buf, i, 10)
+	h.buf = append(h.buf, 0)
+}
+
+func (h *shape) bool(b bool) {
+	h.buf = strconv.AppendBool(h.buf, b)
+	h.buf = append(h.buf, 0)
+}
+
+func (h *shape) strs(ss []string) {
+	h.int(int64(len(ss)))
+
+	for _, s := range ss {
+		h.str(s)
+	}
+}
+
+func (h *shape) query(q Query, d *Dialect) {
+	orig := q
+	q = applyHooks(q.finalize())
+
+	if len(q.errs) > 0 {
+		h.errs = true
+	}
+
+	h.str(d.name)
+	h.str(d.ignore)
+	h.int(int64(d.placeholder))
+	h.bool(d.autoQuote)
+	h.str(q.stmt.String())
+	h.str(q.table)
+	h.bool(q.quote)
+	h.bool(q.qualify)
+	h.int(int64(q.tenancy))
+
+	h.int(int64(len(q.exprs)))
+
+	for _, expr := range q.exprs {
+		h.expr(expr, d)
+	}
+
+	if !h.normalize {
+		h.int(int64(len(q.clauses)))
+	}
+
+	for i, cl := range q.clauses {
+		if h.normalize && i > 0 && cl.kind() == _ValuesClause && q.clauses[i-1].kind() == _ValuesClause {
+			continue
+		}
+
+		h.str(cl.kind().name())
+		h.expr(cl, d)
+	}
+
+	if q.dedupe && d.placeholder.numbered() && !h.normalize {
+		_, args, _ := orig.write(d)
+		_, index := dedupe(unnamed(args))
+
+		for _, i := range index {
+			h.int(i)
+		}
+	}
+}
+
+func (h *shape) expr(e Expr, d *Dialect) {
+	switch v := e.(type) {
+	case nil:
+		h.str("nil")
+	case argExpr:
+		h.str("arg")
+
+		if named, ok := v.val.(sql.NamedArg); ok {
+			h.str(named.Name)
+		}
+	case listExpr:
+		h.str("list")
+		h.bool(v.wrap)
+
+		if h.normalize && len(v.args) > 0 {
+			h.str("args")
+			return
+		}
+		h.strs(v.items)
+	case callExpr:
+		h.str("call")
+		h.str(v.name)
+		h.int(int64(len(v.args)))
+
+		for _, arg := range v.args {
+			h.expr(arg, d)
+		}
+	case rawExpr:
+		h.str("raw")
+		h.str(v.sql)
+	case joinExpr:
+		h.str("join")
+		h.str(v.sep)
+		h.int(int64(len(v.exprs)))
+
+		for _, expr := range v.exprs {
+			h.expr(expr, d)
+		}
+	case subqueryExpr:
+		h.str("subquery")
+		h.query(v.q, d)
+	case Query:
+		h.str("query")
+		h.query(v, d)
+	case conflictClause:
+		h.strs(v.cols)
+		h.strs(v.update)
+	case orderClause:
+		h.strs(v.cols)
+		h.str(v.dir)
+	case groupClause:
+		h.strs(v.cols)
+	case returningClause:
+		h.int(int64(len(v.exprs)))
+
+		for _, expr := range v.exprs {
+			h.expr(expr, d)
+		}
+	case setClause:
+		h.str(v.col)
+		h.expr(v.expr, d)
+	case unionClause:
+		h.query(v.q, d)
+	case fromQueryClause:
+		h.query(v.q, d)
+		h.str(v.alias)
+	case customClause:
+		h.expr(v.cl, d)
+	case kindExpr:
+		h.expr(v.expr, d)
+	case valuesClause:
+		h.strs(v.items)
+	case limitClause, offsetClause, fetchClause:
+		if !h.normalize {
+			h.str(buildExpr(e, d))
+		}
+	case litExpr:
+		if h.normalize {
+			if _, ok := v.val.(string); !ok {
+				h.str("lit")
+				return
+			}
+		}
+		h.str("sql")
+		h.str(buildExpr(e, d))
+	case whereClause:
+		h.str(v.conjunction)
+		h.str(v.op)
+		h.bool(v.filter)
+		h.expr(v.left, d)
+		h.expr(v.right, d)
+	default:
+		// Any other expression, such as a literal or an identifier, has no
+		// arguments in its built string, so it can be used as its shape.
+		h.str("sql")
+		h.str(buildExpr(e, d))
+	}
+}
+
+// Build returns the built query for the given Query. If a query with the same
+// shape has already been built then that is returned, otherwise the Query is
+// built and cached. A Query with an error recorded is always built, and never
+// cached.
+func (c *Cache) Build(q Query) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	h := shape{buf: *buf}
+	h.query(q, dialectOr(q.dialect))
+
+	*buf = h.buf
+
+	if h.errs {
+		return q.Build()
+	}
+
+	c.mu.RLock()
+	s, ok := c.queries[string(h.buf)]
+	c.mu.RUnlock()
+
+	if ok {
+		return s
+	}
+
+	s = q.Build()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.queries == nil {
+		c.queries = make(map[string]string)
+	}
+
+	max := c.Max
+
+	if max <= 0 {
+		max = DefaultCacheSize
+	}
+
+	if len(c.queries) >= max {
+		// Map iteration order is random, so this evicts a random query.
+		for key := range c.queries {
+			delete(c.queries, key)
+			break
+		}
+	}
+
+	c.queries[string(h.buf)] = s
+	return s
+}
+
+// Len returns the number of queries in the cache.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.queries)
+}
+
+// Reset removes all of the queries from the cache.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queries = nil
+}
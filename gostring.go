@@ -0,0 +1,332 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// String builds up the query in the same way as Build. This implements the
+// fmt.Stringer interface, so a Query can be given to fmt and loggers as is. If
+// the Query cannot be built then the error is returned in the form of,
+//
+//     <query error: query: missing tenant for SELECT on posts>
+func (q Query) String() string {
+	d := dialectOr(q.dialect)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b, _, _, err := q.appendWrite(*buf, d, d.placeholder, 1)
+	*buf = b
+
+	if err != nil {
+		return "<query error: " + err.Error() + ">"
+	}
+	return string(b)
+}
+
+// GoString returns the Go syntax for the calls that would build up the Query,
+// this implements the fmt.GoStringer interface for the %#v verb, for example,
+//
+//     query.Select(query.Columns("*"), query.From("posts"), query.Where("user_id", "=", query.Arg(10)))
+//
+// Deferred options, such as NotDeleted, are applied first, so the calls
+// returned are for the Query as it would be built. Expressions that cannot be
+// expressed via the calls they were made with are given via Raw.
+func (q Query) GoString() string {
+	q = q.finalize()
+
+	if len(q.clauses) == 1 {
+		if v, ok := q.clauses[0].(fromQueryClause); ok && v.alias == "count" {
+			return "query.CountOf(" + v.q.GoString() + ")"
+		}
+	}
+
+	opts := make([]string, 0, len(q.clauses)+3)
+
+	for _, cl := range q.clauses {
+		if s := clauseGoString(cl); s != "" {
+			opts = append(opts, s)
+		}
+	}
+
+	if s := dialectGoString(q.dialect); s != "" {
+		opts = append(opts, "query.WithDialect("+s+")")
+	}
+
+	if q.quote {
+		opts = append(opts, "query.QuoteIdents()")
+	}
+
+	if q.dedupe {
+		opts = append(opts, "query.Dedupe()")
+	}
+
+	if q.qualify {
+		opts = append(opts, "query.Qualify()")
+	}
+
+	var (
+		name string
+		args []string
+	)
+
+	switch q.stmt {
+	case _Delete:
+		name = "Delete"
+		args = append(args, fmt.Sprintf("%q", q.table))
+	case _Update:
+		name = "Update"
+		args = append(args, fmt.Sprintf("%q", q.table))
+	case _Insert:
+		name = "Insert"
+		args = append(args, fmt.Sprintf("%q", q.table), exprGoString(q.exprs[0]))
+	case _Select:
+		name = "Select"
+		args = append(args, exprGoString(q.exprs[0]))
+	case _SelectDistinct:
+		name = "SelectDistinct"
+		args = append(args, exprGoString(q.exprs[0]))
+	case _SelectDistinctOn:
+		name = "SelectDistinctOn"
+		cols := q.exprs[0].(listExpr).items
+		args = append(args, fmt.Sprintf("%#v", cols), exprGoString(q.exprs[1]))
+	case _Explain:
+		e := q.exprs[0].(explainExpr)
+		args = append(args, e.q.GoString())
+
+		for _, opt := range e.opts {
+			args = append(args, explainGoString(opt))
+		}
+		return "query.Explain(" + strings.Join(args, ", ") + ")"
+	case _Stmt:
+		if len(q.exprs) > 0 {
+			if v, ok := q.exprs[0].(joinExpr); ok {
+				args = append(args, fmt.Sprintf("%q", v.sep))
+
+				for _, expr := range v.exprs {
+					args = append(args, exprGoString(expr))
+				}
+				return "query.Append(" + strings.Join(append(args, opts...), ", ") + ")"
+			}
+		}
+
+		for _, cl := range q.clauses {
+			if u, ok := cl.(unionClause); ok {
+				args = append(args, u.q.GoString())
+			}
+		}
+		return "query.Union(" + strings.Join(args, ", ") + ")"
+	}
+
+	return "query." + name + "(" + strings.Join(append(args, opts...), ", ") + ")"
+}
+
+// goStrings returns the Go syntax for each of the given values, joined with a
+// comma.
+func goStrings(vals []interface{}) string {
+	items := make([]string, 0, len(vals))
+
+	for _, val := range vals {
+		items = append(items, fmt.Sprintf("%#v", val))
+	}
+	return strings.Join(items, ", ")
+}
+
+// quotedStrings returns each of the given strings quoted, joined with a comma.
+func quotedStrings(ss []string) string {
+	items := make([]string, 0, len(ss))
+
+	for _, s := range ss {
+		items = append(items, fmt.Sprintf("%q", s))
+	}
+	return strings.Join(items, ", ")
+}
+
+// rawGoString returns the Go syntax for a call to Raw for the given expression.
+func rawGoString(e Expr) string {
+	if args := e.Args(); len(args) > 0 {
+		return fmt.Sprintf("query.Raw(%q, %s)", e.Build(), goStrings(args))
+	}
+	return fmt.Sprintf("query.Raw(%q)", e.Build())
+}
+
+func exprGoString(e Expr) string {
+	switch v := e.(type) {
+	case Query:
+		return v.GoString()
+	case subqueryExpr:
+		return v.q.GoString()
+	case identExpr:
+		return fmt.Sprintf("query.Ident(%q)", string(v))
+	case quoteExpr:
+		return fmt.Sprintf("query.Quote(%q)", string(v))
+	case argExpr:
+		if named, ok := v.val.(sql.NamedArg); ok {
+			return fmt.Sprintf("query.Named(%q, %#v)", named.Name, named.Value)
+		}
+		return fmt.Sprintf("query.Arg(%#v)", v.val)
+	case litExpr:
+		return fmt.Sprintf("query.Lit(%#v)", v.val)
+	case rawExpr:
+		return rawGoString(v)
+	case listExpr:
+		if !v.wrap {
+			return "query.Columns(" + quotedStrings(v.items) + ")"
+		}
+
+		if len(v.args) == len(v.items) {
+			return "query.List(" + goStrings(v.args) + ")"
+		}
+	case callExpr:
+		cols := make([]string, 0, len(v.args))
+
+		for _, arg := range v.args {
+			lit, ok := arg.(litExpr)
+
+			if !ok {
+				break
+			}
+
+			if s, ok := lit.val.(string); ok {
+				cols = append(cols, s)
+			}
+		}
+
+		if len(cols) == len(v.args) {
+			switch {
+			case v.name == "COUNT":
+				return "query.Count(" + quotedStrings(cols) + ")"
+			case v.name == "SUM" && len(cols) == 1:
+				return "query.Sum(" + quotedStrings(cols) + ")"
+			}
+		}
+	}
+	return rawGoString(e)
+}
+
+func clauseGoString(cl clause) string {
+	switch v := cl.(type) {
+	case fromClause:
+		return fmt.Sprintf("query.From(%q)", v.table)
+	case limitClause:
+		return fmt.Sprintf("query.Limit(%d)", int64(v))
+	case offsetClause:
+		return fmt.Sprintf("query.Offset(%d)", int64(v))
+	case orderClause:
+		if v.dir == "DESC" {
+			return "query.OrderDesc(" + quotedStrings(v.cols) + ")"
+		}
+		return "query.OrderAsc(" + quotedStrings(v.cols) + ")"
+	case groupClause:
+		return "query.GroupBy(" + quotedStrings(v.cols) + ")"
+	case whereClause:
+		fn := "query.Where"
+
+		if v.conjunction == "OR" {
+			fn = "query.OrWhere"
+		}
+
+		if col, ok := v.left.(identExpr); ok {
+			return fmt.Sprintf("%s(%q, %q, %s)", fn, string(col), v.op, exprGoString(v.right))
+		}
+
+		if v.left == nil && v.op == "" {
+			return fn + "Expr(" + exprGoString(v.right) + ")"
+		}
+		return fn + "Expr(" + rawGoString(v) + ")"
+	case setClause:
+		return fmt.Sprintf("query.Set(%q, %s)", v.col, exprGoString(v.expr))
+	case returningClause:
+		cols := make([]string, 0, len(v.exprs))
+
+		for _, expr := range v.exprs {
+			if col, ok := expr.(identExpr); ok {
+				cols = append(cols, string(col))
+			}
+		}
+
+		if len(cols) == len(v.exprs) {
+			return "query.Returning(" + quotedStrings(cols) + ")"
+		}
+
+		exprs := make([]string, 0, len(v.exprs))
+
+		for _, expr := range v.exprs {
+			exprs = append(exprs, exprGoString(expr))
+		}
+		return "query.ReturningExpr(" + strings.Join(exprs, ", ") + ")"
+	case valuesClause:
+		vals := make([]string, 0, len(v.items))
+		n := 0
+
+		for _, item := range v.items {
+			if item != "?" {
+				vals = append(vals, "/* "+item+" */")
+				continue
+			}
+
+			if n < len(v.args) {
+				vals = append(vals, fmt.Sprintf("%#v", v.args[n]))
+				n++
+			}
+		}
+		return "query.Values(" + strings.Join(vals, ", ") + ")"
+	case conflictClause:
+		if len(v.update) == 0 {
+			return "query.OnConflictDoNothing(" + quotedStrings(v.cols) + ")"
+		}
+		return fmt.Sprintf("query.OnConflictUpdate(%#v, %s)", v.cols, quotedStrings(v.update))
+	case unionClause:
+		return ""
+	}
+	return fmt.Sprintf("/* %s %s */", cl.kind().name(), cl.Build())
+}
+
+func dialectGoString(d *Dialect) string {
+	if d == nil {
+		return ""
+	}
+
+	if d.autoQuote {
+		base := *d
+		base.autoQuote = false
+
+		if s := dialectGoString(&base); s != "" {
+			return s + ".Quoted()"
+		}
+		return ""
+	}
+
+	switch d.name {
+	case "postgres":
+		return "query.Postgres"
+	case "mysql":
+		return "query.MySQL"
+	case "sqlite":
+		if d.version != "" {
+			return fmt.Sprintf("query.SQLiteVersion(%q)", d.version)
+		}
+		return "query.SQLite"
+	case "sqlserver":
+		return "query.SQLServer"
+	case "oracle":
+		return "query.Oracle"
+	}
+	return ""
+}
+
+func explainGoString(opt string) string {
+	switch opt {
+	case "ANALYZE":
+		return "query.Analyze()"
+	case "VERBOSE":
+		return "query.Verbose()"
+	case "BUFFERS":
+		return "query.Buffers()"
+	case "FORMAT JSON":
+		return "query.FormatJSON()"
+	}
+	return fmt.Sprintf("/* %s */", opt)
+}
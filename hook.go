@@ -0,0 +1,96 @@
+package query
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// hooks holds the global hooks that are applied to every Query, the slice is
+// replaced whenever a hook is added so it can be loaded without locking.
+var (
+	hooksMu sync.Mutex
+	hooks   atomic.Value
+)
+
+// AddHook registers the given options as global hooks. A hook is applied to
+// every Query just before it is built, after any deferred options of the Query
+// itself, and in the order the hooks were added. A hook can rewrite the Query,
+// for example to enforce a maximum limit, or it can simply observe the Query
+// and return it as is, for example,
+//
+//     query.AddHook(func(q query.Query) query.Query {
+//         if q.Table() == "posts" {
+//             return query.Where("tenant_id", "=", query.Arg(tenantID))(q)
+//         }
+//         return q
+//     })
+//
+// Hooks are applied each time a Query is prepared for building, which happens
+// when the Query is built and when its arguments are collected, and for each
+// subquery. Hooks should therefore be cheap, and return the same Query for the
+// same input. This should typically be called during initialization, since
+// adding a hook affects every Query in the program.
+func AddHook(opts ...Option) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	prev := globalHooks()
+
+	next := make([]Option, 0, len(prev)+len(opts))
+	next = append(next, prev...)
+	next = append(next, opts...)
+
+	hooks.Store(next)
+}
+
+// ClearHooks removes all of the global hooks added via AddHook.
+func ClearHooks() {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	hooks.Store([]Option(nil))
+}
+
+// Hook returns an Option that applies the given options as hooks to the Query
+// just before it is built. Unlike the global hooks added via AddHook, these
+// only apply to the Query they are given to.
+func Hook(opts ...Option) Option {
+	return deferOpt(Options(opts...))
+}
+
+// Table returns the name of the table the Query operates on, without any alias.
+// For a SELECT query this is the table in the first FROM clause. This is
+// intended for hooks that only apply to certain tables.
+func (q Query) Table() string {
+	table := q.table
+
+	if table == "" {
+		for _, cl := range q.clauses {
+			if from, ok := cl.(fromClause); ok {
+				table = from.table
+				break
+			}
+		}
+	}
+
+	if parts := strings.Fields(table); len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+func globalHooks() []Option {
+	opts, _ := hooks.Load().([]Option)
+	return opts
+}
+
+// applyHooks applies the global hooks to the given Query.
+func applyHooks(q Query) Query {
+	opts := globalHooks()
+
+	if len(opts) == 0 {
+		return q
+	}
+	return q.apply(opts)
+}
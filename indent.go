@@ -0,0 +1,289 @@
+package query
+
+import "strings"
+
+// indentUnit is the string each level of a subquery is indented with.
+const indentUnit = "    "
+
+// indentKeywords are the keywords that begin a new line when SQL is indented.
+// Keywords of more than one word are matched by their first word.
+var indentKeywords = map[string]string{
+	"SELECT":    "",
+	"FROM":      "",
+	"WHERE":     "",
+	"GROUP":     "BY",
+	"HAVING":    "",
+	"WINDOW":    "",
+	"ORDER":     "BY",
+	"LIMIT":     "",
+	"OFFSET":    "",
+	"FETCH":     "",
+	"RETURNING": "",
+	"SET":       "",
+	"VALUES":    "",
+	"ON":        "CONFLICT",
+	"UNION":     "",
+	"INTERSECT": "",
+	"EXCEPT":    "",
+}
+
+// indentSkip is the set of keywords that, when they precede a keyword in
+// indentKeywords, stop a new line from being started. For example, the FROM
+// in IS DISTINCT FROM.
+var indentSkip = map[string]string{
+	"DISTINCT": "FROM",
+	"DELETE":   "FROM",
+	"UPDATE":   "SET",
+}
+
+// indentFrame is a pair of parentheses in the SQL being indented.
+type indentFrame struct {
+	// sub reports whether the parentheses are around a subquery, the SQL
+	// within these is indented.
+	sub bool
+
+	// words is the number of words seen within the parentheses.
+	words int
+}
+
+// BuildIndented builds the Query in the same way as Build, only the SQL is
+// split across multiple lines, and subqueries are indented. This is meant for
+// SQL that will be read, such as in logs, test fixtures, or when looking at
+// the plan of a query. For example,
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("id", "IN", query.Select(
+//             query.Columns("post_id"),
+//             query.From("likes"),
+//             query.Where("user_id", "=", query.Arg(10)),
+//         )),
+//         query.OrderDesc("created_at"),
+//     )
+//
+// would result in the following query being built,
+//
+//     SELECT *
+//     FROM posts
+//     WHERE (id IN (
+//         SELECT post_id
+//         FROM likes
+//         WHERE (user_id = $1)
+//     ))
+//     ORDER BY created_at DESC
+func (q Query) BuildIndented() string { return Indent(q.Build()) }
+
+// Indent splits the given SQL across multiple lines, and indents subqueries,
+// in the same way as BuildIndented. This can be used for SQL that was not
+// built via a Query. Quoted strings and identifiers are left as they are.
+func Indent(sql string) string {
+	keywords := indentKeywordsWith(registeredKeywords())
+
+	var (
+		stack []indentFrame
+		prev  string
+	)
+
+	buf := make([]byte, 0, len(sql)+len(sql)/8)
+
+	depth := func() int {
+		n := 0
+
+		for _, f := range stack {
+			if f.sub {
+				n++
+			}
+		}
+		return n
+	}
+
+	newline := func(n int) {
+		for len(buf) > 0 && buf[len(buf)-1] == ' ' {
+			buf = buf[:len(buf)-1]
+		}
+
+		buf = append(buf, '\n')
+
+		for i := 0; i < n; i++ {
+			buf = append(buf, indentUnit...)
+		}
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := strings.IndexByte(sql[i+1:], c)
+
+			if j < 0 {
+				return string(append(buf, sql[i:]...))
+			}
+
+			buf = append(buf, sql[i:i+j+2]...)
+			i += j + 2
+			continue
+		case c == '(':
+			// A subquery without a FROM, such as (SELECT NULL), is short enough
+			// to be left on a single line.
+			word := nextWord(sql[i+1:])
+			sub := word == "WITH" || word == "SELECT" && hasFrom(sql[i+1:closing(sql, i)])
+
+			stack = append(stack, indentFrame{sub: sub})
+			buf = append(buf, c)
+
+			if sub {
+				newline(depth())
+			}
+			prev = ""
+		case c == ')':
+			if len(stack) > 0 {
+				f := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				if f.sub {
+					newline(depth())
+				}
+			}
+			buf = append(buf, c)
+			prev = ""
+		case isWordByte(c):
+			j := i
+
+			for j < len(sql) && isWordByte(sql[j]) {
+				j++
+			}
+
+			word := sql[i:j]
+			upper := strings.ToUpper(word)
+
+			// Only the SQL at the top level, or within a subquery is split,
+			// so something like the FROM in EXTRACT(YEAR FROM t) is left as
+			// it is.
+			top := len(stack) == 0 || stack[len(stack)-1].sub
+
+			first := len(buf) == 0
+
+			if len(stack) > 0 {
+				first = stack[len(stack)-1].words == 0
+				stack[len(stack)-1].words++
+			}
+
+			if next, ok := keywords[upper]; ok && top && !first && indentSkip[prev] != upper {
+				if next == "" || strings.EqualFold(nextWord(sql[j:]), next) {
+					newline(depth())
+				}
+			}
+
+			buf = append(buf, word...)
+			prev = upper
+			i = j
+			continue
+		default:
+			buf = append(buf, c)
+		}
+		i++
+	}
+	return string(buf)
+}
+
+// indentKeywordsWith returns the keywords that begin a new line along with the
+// given keywords.
+func indentKeywordsWith(extra []string) map[string]string {
+	if len(extra) == 0 {
+		return indentKeywords
+	}
+
+	m := make(map[string]string, len(indentKeywords)+len(extra))
+
+	for k, v := range indentKeywords {
+		m[k] = v
+	}
+
+	for _, kw := range extra {
+		parts := strings.SplitN(kw, " ", 2)
+
+		if _, ok := m[parts[0]]; ok {
+			continue
+		}
+
+		next := ""
+
+		if len(parts) > 1 {
+			next = strings.Fields(parts[1])[0]
+		}
+		m[parts[0]] = next
+	}
+	return m
+}
+
+// registeredKeywords returns the keywords of the clauses registered via
+// RegisterClause.
+func registeredKeywords() []string {
+	customMu.RLock()
+	defer customMu.RUnlock()
+
+	kws := make([]string, 0, len(customDefs))
+
+	for _, def := range customDefs {
+		if def.keyword != "" {
+			kws = append(kws, def.keyword)
+		}
+	}
+	return kws
+}
+
+// closing returns the index of the parenthesis that closes the one at the given
+// index, or the length of the SQL if it is not closed.
+func closing(sql string, i int) int {
+	n := 0
+
+	for ; i < len(sql); i++ {
+		switch c := sql[i]; c {
+		case '\'', '"', '`':
+			j := strings.IndexByte(sql[i+1:], c)
+
+			if j < 0 {
+				return len(sql)
+			}
+			i += j + 1
+		case '(':
+			n++
+		case ')':
+			n--
+
+			if n == 0 {
+				return i
+			}
+		}
+	}
+	return len(sql)
+}
+
+// hasFrom reports whether the given SQL contains the FROM keyword.
+func hasFrom(sql string) bool {
+	for _, word := range strings.Fields(sql) {
+		if strings.EqualFold(word, "FROM") {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWord returns the next word in the given SQL in upper case, skipping any
+// leading whitespace.
+func nextWord(sql string) string {
+	sql = strings.TrimLeft(sql, " \t\n")
+
+	i := 0
+
+	for i < len(sql) && isWordByte(sql[i]) {
+		i++
+	}
+	return strings.ToUpper(sql[:i])
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
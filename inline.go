@@ -0,0 +1,101 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildInlined builds up the query with each of its arguments written inline
+// as a literal for its Dialect, in place of its placeholder, for example,
+//
+//     q := query.Insert(
+//         "posts",
+//         query.Columns("title", "tags", "created_at"),
+//         query.Values("It's here", []string{"go", "sql"}, createdAt),
+//     )
+//
+// would result in the following query being built,
+//
+//     INSERT INTO posts (title, tags, created_at) VALUES ('It''s here', ARRAY['go', 'sql'], '2021-03-14T15:09:26Z'::timestamptz)
+//
+// This is meant for tooling that produces standalone SQL, such as seed files,
+// or a case that can be run in psql to reproduce an issue. Unlike
+// DebugString, an error is returned if an argument cannot be written as a
+// literal, so the SQL can be run as is. Strings, numbers, booleans, []byte,
+// time.Time, and time.Duration are supported, along with a pointer to, or a
+// driver.Valuer that returns one of these. For PostgreSQL, []byte is written
+// as bytea, time.Time as timestamptz, time.Duration as interval, and slices
+// as arrays.
+//
+// This is never used when a Query is executed, which always sends its
+// arguments separately via Build and Args, and it should not be used for
+// executing queries with values from user input.
+func (q Query) BuildInlined() (string, error) {
+	if err := q.Err(); err != nil {
+		return "", err
+	}
+
+	d := dialectOr(q.dialect)
+
+	s, args, err := q.write(d)
+
+	if err != nil {
+		return "", err
+	}
+
+	s, n, err := inline(s, d.args(unnamed(args)), d.literal)
+
+	if err != nil {
+		return "", err
+	}
+
+	if n != len(args) {
+		return "", fmt.Errorf("query: cannot inline %d arguments for %d placeholders", len(args), n)
+	}
+	return s, nil
+}
+
+// inline writes each of the given arguments in place of its placeholder in
+// the given SQL via the given func. Placeholders within quoted strings and
+// identifiers are left as is, and so are any placeholders past the last of
+// the arguments. The number of placeholders in the SQL is returned.
+func inline(s string, args []interface{}, lit func(interface{}) (string, error)) (string, int, error) {
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	n := 0
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '"', '`':
+			j := strings.IndexByte(s[i+1:], c)
+
+			if j < 0 {
+				buf.WriteString(s[i:])
+				i = len(s)
+				continue
+			}
+
+			buf.WriteString(s[i : i+j+2])
+			i += j + 1
+		case '?':
+			if n >= len(args) {
+				buf.WriteByte(c)
+				n++
+				continue
+			}
+
+			l, err := lit(args[n])
+
+			if err != nil {
+				return "", n, err
+			}
+
+			buf.WriteString(l)
+			n++
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String(), n, nil
+}
@@ -0,0 +1,217 @@
+package query
+
+// Clause is a read-only view of a clause in a Query, as returned from
+// Query.Clauses. This allows for tooling to inspect the tables, columns, and
+// predicates of a Query, for example,
+//
+//     for _, cl := range q.Clauses() {
+//         if cl.Kind() == "WHERE" && cl.Column() == "tenant_id" {
+//             // The query is scoped to a tenant.
+//         }
+//     }
+type Clause struct {
+	cl clause
+}
+
+// Statement returns the keyword of the statement the Query is for, such as
+// SELECT or UPDATE. This is UNION for a Query returned from Union.
+func (q Query) Statement() string {
+	if q.stmt == _Stmt && len(q.clauses) > 0 {
+		return q.clauses[0].kind().name()
+	}
+	return q.stmt.String()
+}
+
+// Clauses returns the clauses of the Query in the order they will be built.
+// Deferred options and global hooks are applied first, so the clauses are
+// those of the Query as it would be built. Any rewriting of the Query for its
+// Dialect is not applied.
+func (q Query) Clauses() []Clause {
+	q = applyHooks(q.finalize()).sorted().merged()
+
+	clauses := make([]Clause, 0, len(q.clauses))
+
+	for _, cl := range q.clauses {
+		clauses = append(clauses, Clause{cl: cl})
+	}
+	return clauses
+}
+
+// Tables returns the tables the Query operates on, including the tables of any
+// subqueries. Each table is only returned once, without any alias, in the
+// order they appear in the Query.
+func (q Query) Tables() []string {
+	seen := make(map[string]struct{})
+	return q.appendTables(nil, seen)
+}
+
+func (q Query) appendTables(tables []string, seen map[string]struct{}) []string {
+	add := func(table string) {
+		if table == "" {
+			return
+		}
+
+		if _, ok := seen[table]; ok {
+			return
+		}
+
+		seen[table] = struct{}{}
+		tables = append(tables, table)
+	}
+
+	q = applyHooks(q.finalize())
+
+	add(Query{table: q.table}.Table())
+
+	for _, expr := range q.exprs {
+		for _, sub := range subqueries(expr) {
+			tables = sub.appendTables(tables, seen)
+		}
+	}
+
+	for _, cl := range q.clauses {
+		c := Clause{cl: cl}
+
+		add(c.Table())
+
+		for _, sub := range c.Subqueries() {
+			tables = sub.appendTables(tables, seen)
+		}
+	}
+	return tables
+}
+
+// subqueries returns the queries within the given expression.
+func subqueries(e Expr) []Query {
+	switch v := e.(type) {
+	case Query:
+		return []Query{v}
+	case subqueryExpr:
+		return []Query{v.q}
+	case explainExpr:
+		return []Query{v.q}
+	case callExpr:
+		var qq []Query
+
+		for _, arg := range v.args {
+			qq = append(qq, subqueries(arg)...)
+		}
+		return qq
+	case joinExpr:
+		var qq []Query
+
+		for _, expr := range v.exprs {
+			qq = append(qq, subqueries(expr)...)
+		}
+		return qq
+	case kindExpr:
+		return subqueries(v.expr)
+	}
+	return nil
+}
+
+// Kind returns the keyword of the clause, this will be one of FROM, LIMIT,
+// OFFSET, ORDER BY, UNION, VALUES, WHERE, RETURNING, SET, or ON CONFLICT, or
+// the keyword of a clause registered via RegisterClause.
+func (c Clause) Kind() string { return c.cl.kind().name() }
+
+// Table returns the table without any alias for a FROM clause, otherwise an
+// empty string.
+func (c Clause) Table() string {
+	if v, ok := c.cl.(fromClause); ok {
+		return Query{table: v.table}.Table()
+	}
+	return ""
+}
+
+// Column returns the column a WHERE or SET clause is for. This is empty for a
+// WHERE clause given an expression, such as via WhereExpr.
+func (c Clause) Column() string {
+	switch v := c.cl.(type) {
+	case whereClause:
+		if col, ok := v.left.(identExpr); ok {
+			return string(col)
+		}
+	case setClause:
+		return v.col
+	}
+	return ""
+}
+
+// Columns returns the columns of a GROUP BY, ORDER BY, RETURNING, or ON
+// CONFLICT clause, and the column of a WHERE or SET clause. Expressions in a RETURNING clause
+// that are not columns are not returned.
+func (c Clause) Columns() []string {
+	switch v := c.cl.(type) {
+	case orderClause:
+		return append([]string(nil), v.cols...)
+	case groupClause:
+		return append([]string(nil), v.cols...)
+	case conflictClause:
+		return append([]string(nil), v.cols...)
+	case returningClause:
+		var cols []string
+
+		for _, expr := range v.exprs {
+			if col, ok := expr.(identExpr); ok {
+				cols = append(cols, string(col))
+			}
+		}
+		return cols
+	}
+
+	if col := c.Column(); col != "" {
+		return []string{col}
+	}
+	return nil
+}
+
+// Op returns the operator of a WHERE clause, such as = or IN, otherwise an
+// empty string.
+func (c Clause) Op() string {
+	if v, ok := c.cl.(whereClause); ok {
+		return v.op
+	}
+	return ""
+}
+
+// Conjunction returns the conjunction of a WHERE clause, either AND or OR,
+// otherwise an empty string.
+func (c Clause) Conjunction() string {
+	if v, ok := c.cl.(whereClause); ok {
+		return v.conjunction
+	}
+	return ""
+}
+
+// Subqueries returns the queries within the clause, such as the subquery of a
+// WHERE IN clause, or the query of a UNION clause.
+func (c Clause) Subqueries() []Query {
+	switch v := c.cl.(type) {
+	case whereClause:
+		return append(subqueries(v.left), subqueries(v.right)...)
+	case setClause:
+		return subqueries(v.expr)
+	case unionClause:
+		return []Query{v.q}
+	case fromQueryClause:
+		return []Query{v.q}
+	case customClause:
+		return subqueries(v.cl)
+	case returningClause:
+		var qq []Query
+
+		for _, expr := range v.exprs {
+			qq = append(qq, subqueries(expr)...)
+		}
+		return qq
+	}
+	return nil
+}
+
+// Args returns the arguments of the clause.
+func (c Clause) Args() []interface{} { return c.cl.Args() }
+
+// Build returns the built clause, without its keyword, using ? as the
+// placeholder for its arguments.
+func (c Clause) Build() string { return c.cl.Build() }
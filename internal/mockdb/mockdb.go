@@ -0,0 +1,575 @@
+// Package mockdb provides a database/sql driver for testing the queries that
+// are sent to a database. Each call made against the database is matched in
+// order against the expectations set on the Mock, and the SQL of each
+// statement must match the expected SQL exactly.
+//
+//     db, mock, err := mockdb.New()
+//
+//     mock.ExpectQuery("SELECT * FROM posts WHERE (id = $1)").
+//         WithArgs(1).
+//         WillReturnRows(mockdb.NewRows([]string{"id"}).AddRow(1))
+//
+// This only implements what is needed to test the query packages, so that
+// they do not depend on a mocking library.
+package mockdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Mock records the calls expected to be made against a database, and checks
+// each call made against them in order.
+type Mock struct {
+	mu       sync.Mutex
+	expected []expectation
+	prepared []*ExpectedPrepare
+}
+
+type expectation interface {
+	String() string
+}
+
+// Rows is the set of rows returned by an expected query.
+type Rows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+// ExpectedQuery is a query expected to be made against the database.
+type ExpectedQuery struct {
+	query string
+	args  []interface{}
+	rows  *Rows
+	err   error
+}
+
+// ExpectedExec is a statement expected to be executed against the database.
+type ExpectedExec struct {
+	query  string
+	args   []interface{}
+	result driver.Result
+	err    error
+}
+
+// ExpectedPrepare is a statement expected to be prepared against the
+// database.
+type ExpectedPrepare struct {
+	mock         *Mock
+	query        string
+	err          error
+	mustBeClosed bool
+	closed       bool
+}
+
+// ExpectedBegin is a transaction expected to be started.
+type ExpectedBegin struct {
+	err error
+}
+
+// ExpectedCommit is a transaction expected to be committed.
+type ExpectedCommit struct {
+	err error
+}
+
+// ExpectedRollback is a transaction expected to be rolled back.
+type ExpectedRollback struct {
+	err error
+}
+
+type connector struct {
+	mock *Mock
+}
+
+type conn struct {
+	mock *Mock
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+	exp   *ExpectedPrepare
+}
+
+type tx struct {
+	conn *conn
+}
+
+type rows struct {
+	cols []string
+	rows [][]driver.Value
+	pos  int
+}
+
+type result struct {
+	id       int64
+	affected int64
+}
+
+var (
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.StmtQueryContext   = (*stmt)(nil)
+	_ driver.StmtExecContext    = (*stmt)(nil)
+)
+
+// New returns a database, and the Mock for setting the calls that are
+// expected to be made against it.
+func New() (*sql.DB, *Mock, error) {
+	m := &Mock{}
+	return sql.OpenDB(connector{mock: m}), m, nil
+}
+
+// NewRows returns an empty set of rows with the given columns.
+func NewRows(cols []string) *Rows {
+	return &Rows{cols: cols}
+}
+
+// AddRow adds a row of the given values to the set of rows.
+func (r *Rows) AddRow(vals ...interface{}) *Rows {
+	row := make([]driver.Value, len(vals))
+
+	for i, v := range vals {
+		row[i] = v
+	}
+
+	r.rows = append(r.rows, row)
+	return r
+}
+
+// NewResult returns the result of an executed statement with the given last
+// insert ID, and number of rows affected.
+func NewResult(id, affected int64) driver.Result {
+	return result{id: id, affected: affected}
+}
+
+func (r result) LastInsertId() (int64, error) { return r.id, nil }
+func (r result) RowsAffected() (int64, error) { return r.affected, nil }
+
+func (m *Mock) expect(e expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expected = append(m.expected, e)
+}
+
+// ExpectQuery expects the given query to be made next.
+func (m *Mock) ExpectQuery(query string) *ExpectedQuery {
+	e := &ExpectedQuery{query: query}
+	m.expect(e)
+	return e
+}
+
+// ExpectExec expects the given statement to be executed next.
+func (m *Mock) ExpectExec(query string) *ExpectedExec {
+	e := &ExpectedExec{query: query}
+	m.expect(e)
+	return e
+}
+
+// ExpectPrepare expects the given statement to be prepared next.
+func (m *Mock) ExpectPrepare(query string) *ExpectedPrepare {
+	e := &ExpectedPrepare{mock: m, query: query}
+	m.expect(e)
+
+	m.mu.Lock()
+	m.prepared = append(m.prepared, e)
+	m.mu.Unlock()
+	return e
+}
+
+// ExpectBegin expects a transaction to be started next.
+func (m *Mock) ExpectBegin() *ExpectedBegin {
+	e := &ExpectedBegin{}
+	m.expect(e)
+	return e
+}
+
+// ExpectCommit expects a transaction to be committed next.
+func (m *Mock) ExpectCommit() *ExpectedCommit {
+	e := &ExpectedCommit{}
+	m.expect(e)
+	return e
+}
+
+// ExpectRollback expects a transaction to be rolled back next.
+func (m *Mock) ExpectRollback() *ExpectedRollback {
+	e := &ExpectedRollback{}
+	m.expect(e)
+	return e
+}
+
+// ExpectationsWereMet returns an error if any of the expected calls were not
+// made, or if a prepared statement that was expected to be closed was not.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.expected) > 0 {
+		return fmt.Errorf("mockdb: expected %s was not made", m.expected[0])
+	}
+
+	for _, p := range m.prepared {
+		if p.mustBeClosed && !p.closed {
+			return fmt.Errorf("mockdb: expected statement %q to be closed", p.query)
+		}
+	}
+	return nil
+}
+
+// next removes the next expected call, and returns it.
+func (m *Mock) next(call string) (expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.expected) == 0 {
+		return nil, fmt.Errorf("mockdb: unexpected %s", call)
+	}
+
+	e := m.expected[0]
+	m.expected = m.expected[1:]
+	return e, nil
+}
+
+// WithArgs sets the arguments the query is expected to be made with.
+func (e *ExpectedQuery) WithArgs(args ...interface{}) *ExpectedQuery {
+	e.args = args
+	return e
+}
+
+// WillReturnRows sets the rows returned by the query.
+func (e *ExpectedQuery) WillReturnRows(r *Rows) *ExpectedQuery {
+	e.rows = r
+	return e
+}
+
+// WillReturnError sets the error returned by the query.
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedQuery) String() string { return fmt.Sprintf("query %q", e.query) }
+
+// WithArgs sets the arguments the statement is expected to be executed with.
+func (e *ExpectedExec) WithArgs(args ...interface{}) *ExpectedExec {
+	e.args = args
+	return e
+}
+
+// WillReturnResult sets the result returned by the statement.
+func (e *ExpectedExec) WillReturnResult(r driver.Result) *ExpectedExec {
+	e.result = r
+	return e
+}
+
+// WillReturnError sets the error returned by the statement.
+func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedExec) String() string { return fmt.Sprintf("exec %q", e.query) }
+
+// WillBeClosed expects the prepared statement to be closed.
+func (e *ExpectedPrepare) WillBeClosed() *ExpectedPrepare {
+	e.mustBeClosed = true
+	return e
+}
+
+// WillReturnError sets the error returned when preparing the statement.
+func (e *ExpectedPrepare) WillReturnError(err error) *ExpectedPrepare {
+	e.err = err
+	return e
+}
+
+// ExpectQuery expects the prepared statement to be queried next.
+func (e *ExpectedPrepare) ExpectQuery() *ExpectedQuery {
+	return e.mock.ExpectQuery(e.query)
+}
+
+// ExpectExec expects the prepared statement to be executed next.
+func (e *ExpectedPrepare) ExpectExec() *ExpectedExec {
+	return e.mock.ExpectExec(e.query)
+}
+
+func (e *ExpectedPrepare) String() string { return fmt.Sprintf("prepare %q", e.query) }
+
+// WillReturnError sets the error returned when starting the transaction.
+func (e *ExpectedBegin) WillReturnError(err error) *ExpectedBegin {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedBegin) String() string { return "begin" }
+
+// WillReturnError sets the error returned when committing the transaction.
+func (e *ExpectedCommit) WillReturnError(err error) *ExpectedCommit {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedCommit) String() string { return "commit" }
+
+// WillReturnError sets the error returned when rolling back the transaction.
+func (e *ExpectedRollback) WillReturnError(err error) *ExpectedRollback {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedRollback) String() string { return "rollback" }
+
+// value converts the given argument to a driver.Value if it can be, otherwise
+// it is passed through as is, so slices can be given as arguments.
+func value(v interface{}) interface{} {
+	if dv, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		return dv
+	}
+	return v
+}
+
+// matchArgs checks the given arguments against the expected arguments. If no
+// arguments were expected then any arguments match.
+func matchArgs(call string, expected []interface{}, args []driver.NamedValue) error {
+	if expected == nil {
+		return nil
+	}
+
+	if len(expected) != len(args) {
+		return fmt.Errorf("mockdb: %s expected %d arguments, got %d", call, len(expected), len(args))
+	}
+
+	for i, arg := range args {
+		if want := value(expected[i]); !reflect.DeepEqual(want, arg.Value) {
+			return fmt.Errorf("mockdb: %s expected argument %d to be %#v, got %#v", call, i, want, arg.Value)
+		}
+	}
+	return nil
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{mock: c.mock}, nil
+}
+
+func (c connector) Driver() driver.Driver { return c }
+
+func (c connector) Open(string) (driver.Conn, error) {
+	return &conn{mock: c.mock}, nil
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(driver.Valuer); ok {
+		if _, err := v.Value(); err != nil {
+			return err
+		}
+	}
+
+	nv.Value = value(nv.Value)
+	return nil
+}
+
+func (c *conn) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	call := fmt.Sprintf("query %q", query)
+
+	e, err := c.mock.next(call)
+
+	if err != nil {
+		return nil, err
+	}
+
+	q, ok := e.(*ExpectedQuery)
+
+	if !ok || q.query != query {
+		return nil, fmt.Errorf("mockdb: %s, expected %s", call, e)
+	}
+
+	if err := matchArgs(call, q.args, args); err != nil {
+		return nil, err
+	}
+
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	r := &rows{}
+
+	if q.rows != nil {
+		r.cols = q.rows.cols
+		r.rows = q.rows.rows
+	}
+	return r, nil
+}
+
+func (c *conn) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	call := fmt.Sprintf("exec %q", query)
+
+	e, err := c.mock.next(call)
+
+	if err != nil {
+		return nil, err
+	}
+
+	x, ok := e.(*ExpectedExec)
+
+	if !ok || x.query != query {
+		return nil, fmt.Errorf("mockdb: %s, expected %s", call, e)
+	}
+
+	if err := matchArgs(call, x.args, args); err != nil {
+		return nil, err
+	}
+
+	if x.err != nil {
+		return nil, x.err
+	}
+
+	if x.result == nil {
+		return result{}, nil
+	}
+	return x.result, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.query(query, args)
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.exec(query, args)
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	call := fmt.Sprintf("prepare %q", query)
+
+	e, err := c.mock.next(call)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := e.(*ExpectedPrepare)
+
+	if !ok || p.query != query {
+		return nil, fmt.Errorf("mockdb: %s, expected %s", call, e)
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &stmt{conn: c, query: query, exp: p}, nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	e, err := c.mock.next("begin")
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := e.(*ExpectedBegin)
+
+	if !ok {
+		return nil, fmt.Errorf("mockdb: begin, expected %s", e)
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	return tx{conn: c}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) Close() error { return nil }
+
+func (t tx) Commit() error {
+	e, err := t.conn.mock.next("commit")
+
+	if err != nil {
+		return err
+	}
+
+	c, ok := e.(*ExpectedCommit)
+
+	if !ok {
+		return fmt.Errorf("mockdb: commit, expected %s", e)
+	}
+	return c.err
+}
+
+func (t tx) Rollback() error {
+	e, err := t.conn.mock.next("rollback")
+
+	if err != nil {
+		return err
+	}
+
+	r, ok := e.(*ExpectedRollback)
+
+	if !ok {
+		return fmt.Errorf("mockdb: rollback, expected %s", e)
+	}
+	return r.err
+}
+
+func (s *stmt) Close() error {
+	s.conn.mock.mu.Lock()
+	defer s.conn.mock.mu.Unlock()
+
+	s.exp.closed = true
+	return nil
+}
+
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.query(s.query, args)
+}
+
+func (s *stmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.exec(s.query, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.query(s.query, named(args))
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.exec(s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nn := make([]driver.NamedValue, len(args))
+
+	for i, arg := range args {
+		nn[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return nn
+}
+
+func (r *rows) Columns() []string { return r.cols }
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package naming provides the mapping of struct field names to column names
+// that is shared by the query and queryscan packages.
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SnakeCase returns the given name of a field in snake case, for example,
+// UserID would become user_id, and HTTPStatus would become http_status.
+func SnakeCase(name string) string {
+	var buf strings.Builder
+
+	r := []rune(name)
+
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			if i > 0 {
+				prev := r[i-1]
+				next := i+1 < len(r) && unicode.IsLower(r[i+1])
+
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+					buf.WriteByte('_')
+				}
+			}
+			c = unicode.ToLower(c)
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
@@ -0,0 +1,180 @@
+package query
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// literalFunc writes the given value as a literal for a particular Dialect,
+// the given func is used for writing each element of the value, such as the
+// items of an array. If the value is not one the Dialect has its own literal
+// for then false is returned, and the value is written as by literal.
+type literalFunc func(v interface{}, elem func(interface{}) (string, error)) (string, bool, error)
+
+// literal returns the given value as a quoted SQL literal. Strings are quoted
+// with single quotes, using the escape string syntax if the string contains a
+// backslash. An error is returned for any type that cannot be written as a
+// literal.
+func literal(v interface{}) (string, error) { return formatLiteral(v, nil) }
+
+// literal returns the given value as a literal for the Dialect. This is the
+// same as literal, only a Dialect can write values of some types its own way,
+// such as PostgreSQL casting a time.Time to timestamptz, or writing a slice as
+// an array.
+func (d *Dialect) literal(v interface{}) (string, error) { return formatLiteral(v, d) }
+
+// formatLiteral returns the given value as a literal for the given Dialect,
+// which may be nil. If the value is a driver.Valuer then its value is used,
+// and a pointer is written as the value it points to, or as NULL if it is nil.
+func formatLiteral(v interface{}, d *Dialect) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "NULL", nil
+		}
+
+		val, err := valuer.Value()
+
+		if err != nil {
+			return "", err
+		}
+		v = val
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return formatLiteral(rv.Elem().Interface(), d)
+	}
+
+	if d != nil {
+		if b, ok := v.(bool); ok && d.noBool {
+			if b {
+				return "1", nil
+			}
+			return "0", nil
+		}
+
+		if d.lit != nil {
+			elem := func(v interface{}) (string, error) { return formatLiteral(v, d) }
+
+			if lit, ok, err := d.lit(v, elem); ok || err != nil {
+				return lit, err
+			}
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v), nil
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + `'`, nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case time.Duration:
+		return quoteString(strconv.FormatInt(v.Milliseconds(), 10) + "ms"), nil
+	case time.Time:
+		return quoteString(v.Format(time.RFC3339Nano)), nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.String:
+		return quoteString(rv.String()), nil
+	case reflect.Bool:
+		return formatLiteral(rv.Bool(), d)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return quoteString(strconv.FormatFloat(f, 'g', -1, 64)), nil
+		}
+		return strconv.FormatFloat(f, 'g', -1, rv.Type().Bits()), nil
+	}
+
+	// A fmt.Stringer is only used for types that are not of a basic kind, so
+	// a named integer with a String method, such as an enum, is written as the
+	// integer the driver would be given.
+	if str, ok := v.(fmt.Stringer); ok {
+		return quoteString(str.String()), nil
+	}
+	return "", fmt.Errorf("query: cannot use %T as literal", v)
+}
+
+// pgLiteral writes the PostgreSQL literals of the values whose type would
+// otherwise be inferred from the context of the literal, by casting them, and
+// writes slices as arrays.
+func pgLiteral(v interface{}, elem func(interface{}) (string, error)) (string, bool, error) {
+	switch v := v.(type) {
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + `'::bytea`, true, nil
+	case time.Time:
+		return quoteString(v.Format(time.RFC3339Nano)) + "::timestamptz", true, nil
+	case time.Duration:
+		return quoteString(strconv.FormatInt(v.Microseconds(), 10)+" microseconds") + "::interval", true, nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		switch f := rv.Float(); {
+		case math.IsNaN(f):
+			return "'NaN'::float8", true, nil
+		case math.IsInf(f, 1):
+			return "'Infinity'::float8", true, nil
+		case math.IsInf(f, -1):
+			return "'-Infinity'::float8", true, nil
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return "NULL", true, nil
+		}
+
+		if rv.Len() == 0 {
+			return "'{}'", true, nil
+		}
+
+		items := make([]string, 0, rv.Len())
+
+		for i := 0; i < rv.Len(); i++ {
+			lit, err := elem(rv.Index(i).Interface())
+
+			if err != nil {
+				return "", true, err
+			}
+			items = append(items, lit)
+		}
+		return "ARRAY[" + strings.Join(items, ", ") + "]", true, nil
+	}
+	return "", false, nil
+}
+
+// quoteString quotes the given string with single quotes, escaping any single
+// quotes within the string by doubling them. If the string contains a
+// backslash then the escape string syntax is used, with each backslash being
+// doubled.
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+
+	if strings.Contains(s, `\`) {
+		return `E'` + strings.ReplaceAll(s, `\`, `\\`) + `'`
+	}
+	return "'" + s + "'"
+}
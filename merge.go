@@ -0,0 +1,119 @@
+package query
+
+// merged returns the Query with the clauses of the same kind merged, so a kind
+// of clause given more than once builds valid SQL. For LIMIT, OFFSET, FETCH,
+// and ON CONFLICT only the last clause is kept. For SET only the last clause
+// for each column is kept. For GROUP BY a column that has already been grouped
+// by is dropped, for ORDER BY a column that has already been ordered by is
+// dropped, and for RETURNING an identifier that has already been returned is
+// dropped. The clauses of the other kinds are all kept, and are
+// conjoined when built, WHERE clauses with AND or OR, VALUES clauses as
+// multiple rows, and FROM clauses with a comma.
+func (q Query) merged() Query {
+	counts := make(map[clauseKind]int)
+	merge := false
+
+	for _, cl := range q.clauses {
+		kind := cl.kind()
+		counts[kind]++
+
+		switch kind {
+		case _LimitClause, _OffsetClause, _FetchClause, _ConflictClause, _SetClause, _GroupClause, _OrderClause, _ReturningClause:
+			if counts[kind] > 1 {
+				merge = true
+			}
+		}
+	}
+
+	if !merge {
+		return q
+	}
+
+	// The index of the last SET clause for each column.
+	sets := make(map[string]int)
+
+	for i, cl := range q.clauses {
+		if set, ok := cl.(setClause); ok {
+			sets[set.col] = i
+		}
+	}
+
+	var (
+		grouped  = make(map[string]struct{})
+		ordered  = make(map[string]struct{})
+		returned = make(map[string]struct{})
+		seen     = make(map[clauseKind]int)
+	)
+
+	clauses := make([]clause, 0, len(q.clauses))
+
+	for i, cl := range q.clauses {
+		kind := cl.kind()
+		seen[kind]++
+
+		switch v := cl.(type) {
+		case limitClause, offsetClause, fetchClause, conflictClause:
+			if seen[kind] < counts[kind] {
+				continue
+			}
+		case setClause:
+			if sets[v.col] != i {
+				continue
+			}
+		case groupClause:
+			cols := make([]string, 0, len(v.cols))
+
+			for _, col := range v.cols {
+				if _, ok := grouped[col]; ok {
+					continue
+				}
+				grouped[col] = struct{}{}
+				cols = append(cols, col)
+			}
+
+			if len(cols) == 0 {
+				continue
+			}
+			v.cols = cols
+			cl = v
+		case orderClause:
+			cols := make([]string, 0, len(v.cols))
+
+			for _, col := range v.cols {
+				if _, ok := ordered[col]; ok {
+					continue
+				}
+				ordered[col] = struct{}{}
+				cols = append(cols, col)
+			}
+
+			if len(cols) == 0 {
+				continue
+			}
+			v.cols = cols
+			cl = v
+		case returningClause:
+			exprs := make([]Expr, 0, len(v.exprs))
+
+			for _, e := range v.exprs {
+				if ident, ok := e.(identExpr); ok {
+					if _, ok := returned[string(ident)]; ok {
+						continue
+					}
+					returned[string(ident)] = struct{}{}
+				}
+				exprs = append(exprs, e)
+			}
+
+			if len(exprs) == 0 {
+				continue
+			}
+			v.exprs = exprs
+			cl = v
+		}
+		clauses = append(clauses, cl)
+	}
+
+	q.clauses = clauses
+	return q
+}
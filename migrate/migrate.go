@@ -0,0 +1,259 @@
+// Package migrate provides a way of running versioned migrations that are
+// built from queries and DDL statements. Each migration is run in its own
+// transaction, and the versions that have been applied are recorded in a
+// table, for example,
+//
+//     m := migrate.New("schema_migrations")
+//
+//     m.Register(1, "create users", []query.Expr{
+//         ddl.CreateTable(
+//             "users",
+//             ddl.Column("id", "bigserial", ddl.PrimaryKey()),
+//             ddl.Column("email", "text", ddl.NotNull(), ddl.Unique()),
+//         ),
+//     }, []query.Expr{
+//         query.Lit("DROP TABLE users"),
+//     })
+//
+//     if err := m.Up(ctx, db); err != nil {
+//         // Handle error.
+//     }
+//
+// An advisory lock is held whilst migrations are run, so multiple instances of
+// an application can run the migrations on startup without running the same
+// migration twice. This relies on PostgreSQL advisory locks.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/andrewpillar/query"
+	"github.com/andrewpillar/query/ddl"
+)
+
+// ErrIrreversible is returned when a migration that has no down steps is
+// rolled back.
+var ErrIrreversible = errors.New("migration is irreversible")
+
+// Migration is a single versioned migration. The up steps are executed in
+// order when the migration is applied, and the down steps are executed in
+// order when it is rolled back.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []query.Expr
+	Down    []query.Expr
+}
+
+// Migrator runs the migrations registered with it, recording the versions
+// that have been applied in its table.
+type Migrator struct {
+	table      string
+	lock       int64
+	migrations []Migration
+}
+
+// New returns a new Migrator that records the applied versions in the given
+// table. The table is created when the migrations are first run.
+func New(table string) *Migrator {
+	h := fnv.New64a()
+	h.Write([]byte("migrate:" + table))
+
+	return &Migrator{
+		table: table,
+		lock:  int64(h.Sum64()),
+	}
+}
+
+// Register registers a migration with the given version, name, and steps. The
+// migrations are run in the order of their version, regardless of the order
+// they are registered in. This panics if a migration with the same version
+// has already been registered.
+func (m *Migrator) Register(version int64, name string, up, down []query.Expr) {
+	i := sort.Search(len(m.migrations), func(i int) bool {
+		return m.migrations[i].Version >= version
+	})
+
+	if i < len(m.migrations) && m.migrations[i].Version == version {
+		panic(fmt.Sprintf("migrate: version %d already registered", version))
+	}
+
+	mig := Migration{
+		Version: version,
+		Name:    name,
+		Up:      up,
+		Down:    down,
+	}
+
+	m.migrations = append(m.migrations, Migration{})
+	copy(m.migrations[i+1:], m.migrations[i:])
+	m.migrations[i] = mig
+}
+
+// Migrations returns the registered migrations in the order of their version.
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+func (m *Migrator) createTable() ddl.Table {
+	return ddl.CreateTable(
+		m.table,
+		ddl.IfNotExists(),
+		ddl.Column("version", "bigint", ddl.PrimaryKey()),
+		ddl.Column("name", "text", ddl.NotNull()),
+		ddl.Column("applied_at", "timestamp", ddl.NotNull(), ddl.Default(query.Lit("NOW()"))),
+	)
+}
+
+func exec(ctx context.Context, db query.Execer, e query.Expr) error {
+	_, err := db.ExecContext(ctx, e.Build(), e.Args()...)
+	return err
+}
+
+// withLock calls the given function with a connection that holds the advisory
+// lock of the Migrator. The table of the Migrator is created first if it does
+// not exist.
+func (m *Migrator) withLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lock); err != nil {
+		return fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", m.lock)
+
+	if err := exec(ctx, conn, m.createTable()); err != nil {
+		return fmt.Errorf("migrate: create table %s: %w", m.table, err)
+	}
+	return fn(conn)
+}
+
+// applied returns the versions that have been applied.
+func (m *Migrator) applied(ctx context.Context, db query.Execer) (map[int64]struct{}, []int64, error) {
+	q := query.Select(query.Columns("version"), query.From(m.table), query.OrderAsc("version"))
+
+	rows, err := q.QueryContext(ctx, db)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	set := make(map[int64]struct{})
+	versions := make([]int64, 0)
+
+	for rows.Next() {
+		var v int64
+
+		if err := rows.Scan(&v); err != nil {
+			return nil, nil, err
+		}
+
+		set[v] = struct{}{}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return set, versions, nil
+}
+
+// Applied returns the versions of the migrations that have been applied, in
+// ascending order.
+func (m *Migrator) Applied(ctx context.Context, db *sql.DB) ([]int64, error) {
+	var versions []int64
+
+	err := m.withLock(ctx, db, func(conn *sql.Conn) error {
+		var err error
+		_, versions, err = m.applied(ctx, conn)
+		return err
+	})
+	return versions, err
+}
+
+// run runs the given steps of a migration in a transaction, along with the
+// given query for recording the migration.
+func run(ctx context.Context, conn *sql.Conn, steps []query.Expr, record query.Query) error {
+	return query.WithTx(ctx, conn, func(tx query.Execer) error {
+		for i, step := range steps {
+			if err := exec(ctx, tx, step); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		return exec(ctx, tx, record)
+	})
+}
+
+// Up applies each of the migrations that have not been applied, in the order
+// of their version. Each migration is applied in its own transaction, so if a
+// migration fails, then the migrations before it remain applied.
+func (m *Migrator) Up(ctx context.Context, db *sql.DB) error {
+	return m.withLock(ctx, db, func(conn *sql.Conn) error {
+		set, _, err := m.applied(ctx, conn)
+
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := set[mig.Version]; ok {
+				continue
+			}
+
+			record := query.Insert(m.table, query.Columns("version", "name"), query.Values(mig.Version, mig.Name))
+
+			if err := run(ctx, conn, mig.Up, record); err != nil {
+				return fmt.Errorf("migrate: up %d %s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the last n migrations that have been applied, in reverse
+// order of their version. ErrIrreversible is returned for a migration that
+// has no down steps, and for a version that has been applied but is not
+// registered.
+func (m *Migrator) Down(ctx context.Context, db *sql.DB, n int) error {
+	byVersion := make(map[int64]Migration, len(m.migrations))
+
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	return m.withLock(ctx, db, func(conn *sql.Conn) error {
+		_, versions, err := m.applied(ctx, conn)
+
+		if err != nil {
+			return err
+		}
+
+		for i := len(versions) - 1; i >= 0 && n > 0; i, n = i-1, n-1 {
+			mig, ok := byVersion[versions[i]]
+
+			if !ok || len(mig.Down) == 0 {
+				return fmt.Errorf("migrate: down %d: %w", versions[i], ErrIrreversible)
+			}
+
+			record := query.Delete(m.table, query.Where("version", "=", query.Arg(mig.Version)))
+
+			if err := run(ctx, conn, mig.Down, record); err != nil {
+				return fmt.Errorf("migrate: down %d %s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andrewpillar/query"
+	"github.com/andrewpillar/query/ddl"
+	"github.com/andrewpillar/query/internal/mockdb"
+)
+
+const createTable = "CREATE TABLE IF NOT EXISTS schema_migrations (version bigint PRIMARY KEY, name text NOT NULL, applied_at timestamp NOT NULL DEFAULT NOW())"
+
+func migrator() *Migrator {
+	m := New("schema_migrations")
+
+	m.Register(2, "add posts", []query.Expr{
+		ddl.CreateTable("posts", ddl.Column("id", "bigserial", ddl.PrimaryKey())),
+	}, []query.Expr{
+		query.Lit("DROP TABLE posts"),
+	})
+
+	m.Register(1, "add users", []query.Expr{
+		ddl.CreateTable("users", ddl.Column("id", "bigserial", ddl.PrimaryKey())),
+	}, nil)
+	return m
+}
+
+func Test_Register(t *testing.T) {
+	m := migrator()
+
+	migs := m.Migrations()
+
+	if len(migs) != 2 {
+		t.Fatalf("unexpected number of migrations, expected=%d, got=%d\n", 2, len(migs))
+	}
+
+	for i, mig := range migs {
+		if mig.Version != int64(i+1) {
+			t.Errorf("migrations[%d] - unexpected version, expected=%d, got=%d\n", i, i+1, mig.Version)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Register to panic on duplicate version\n")
+		}
+	}()
+	m.Register(1, "add users again", nil, nil)
+}
+
+func Test_Up(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	m := migrator()
+
+	mock.ExpectExec("SELECT pg_advisory_lock($1)").WithArgs(m.lock).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectExec(createTable).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM schema_migrations ORDER BY version ASC").
+		WillReturnRows(mockdb.NewRows([]string{"version"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE posts (id bigserial PRIMARY KEY)").WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)").
+		WithArgs(int64(2), "add posts").
+		WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock($1)").WithArgs(m.lock).WillReturnResult(mockdb.NewResult(0, 0))
+
+	if err := m.Up(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_UpRollback(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	m := migrator()
+
+	stepErr := errors.New("relation already exists")
+
+	mock.ExpectExec("SELECT pg_advisory_lock($1)").WithArgs(m.lock).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectExec(createTable).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM schema_migrations ORDER BY version ASC").
+		WillReturnRows(mockdb.NewRows([]string{"version"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE users (id bigserial PRIMARY KEY)").WillReturnError(stepErr)
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT pg_advisory_unlock($1)").WithArgs(m.lock).WillReturnResult(mockdb.NewResult(0, 0))
+
+	if err := m.Up(context.Background(), db); !errors.Is(err, stepErr) {
+		t.Fatalf("unexpected error, expected=%q, got=%v\n", stepErr, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Down(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	m := migrator()
+
+	mock.ExpectExec("SELECT pg_advisory_lock($1)").WithArgs(m.lock).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectExec(createTable).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM schema_migrations ORDER BY version ASC").
+		WillReturnRows(mockdb.NewRows([]string{"version"}).AddRow(1).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE posts").WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations WHERE (version = $1)").
+		WithArgs(int64(2)).
+		WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock($1)").WithArgs(m.lock).WillReturnResult(mockdb.NewResult(0, 0))
+
+	if err := m.Down(context.Background(), db, 2); !errors.Is(err, ErrIrreversible) {
+		t.Fatalf("unexpected error, expected=%q, got=%v\n", ErrIrreversible, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
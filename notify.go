@@ -0,0 +1,81 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxChannel is the maximum length of a channel name in PostgreSQL, this is
+// the same as the maximum length of an identifier.
+const maxChannel = 63
+
+// channel returns the given channel name quoted, so the case of the channel
+// name is kept, matching the channel name given to pg_notify. An error is
+// returned if the channel name is empty, too long, or contains a NUL byte.
+func channel(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("query: %w: empty name", ErrChannel)
+	}
+
+	if len(name) > maxChannel {
+		return "", fmt.Errorf("query: %w: %q is longer than %d bytes", ErrChannel, name, maxChannel)
+	}
+
+	if strings.IndexByte(name, 0) >= 0 {
+		return "", fmt.Errorf("query: %w: %q contains NUL byte", ErrChannel, name)
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
+}
+
+// Listen builds up a LISTEN statement for the given channel, for example,
+//
+//     LISTEN "jobs"
+//
+// The channel name is quoted, so it is case-sensitive.
+func Listen(name string) (string, error) {
+	ch, err := channel(name)
+
+	if err != nil {
+		return "", err
+	}
+	return "LISTEN " + ch, nil
+}
+
+// Unlisten builds up an UNLISTEN statement for the given channel, for example,
+//
+//     UNLISTEN "jobs"
+//
+// If the channel name is * then an UNLISTEN statement for all channels is
+// built.
+func Unlisten(name string) (string, error) {
+	if name == "*" {
+		return "UNLISTEN *", nil
+	}
+
+	ch, err := channel(name)
+
+	if err != nil {
+		return "", err
+	}
+	return "UNLISTEN " + ch, nil
+}
+
+// Notify returns a Query that sends a notification on the given channel with
+// the given payload. The NOTIFY statement does not support parameters, so the
+// Query calls pg_notify instead, for example,
+//
+//     q, err := query.Notify("jobs", query.Arg(`{"id":10}`))
+//
+// would result in the following query being built,
+//
+//     SELECT pg_notify($1, $2)
+func Notify(name string, payload Expr) (Query, error) {
+	if _, err := channel(name); err != nil {
+		return Query{}, err
+	}
+
+	return Select(callExpr{
+		name: "pg_notify",
+		args: []Expr{Arg(name), payload},
+	}), nil
+}
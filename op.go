@@ -0,0 +1,62 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is an operator used in a WHERE clause, given via WhereOp and OrWhereOp,
+// for example,
+//
+//     query.WhereOp("title", query.OpLike, query.Arg("%foo%"))
+//     query.Where("title", "LIKE", query.Arg("%foo%"))
+//
+// would both result in the same clause being built. An operator that is not
+// known, such as "=<", results in an error from Err, which fails the build of
+// the query if Strict is set.
+type Op string
+
+const (
+	OpEq                Op = "="
+	OpNe                Op = "!="
+	OpLt                Op = "<"
+	OpLe                Op = "<="
+	OpGt                Op = ">"
+	OpGe                Op = ">="
+	OpIn                Op = "IN"
+	OpNotIn             Op = "NOT IN"
+	OpIs                Op = "IS"
+	OpIsNot             Op = "IS NOT"
+	OpLike              Op = "LIKE"
+	OpNotLike           Op = "NOT LIKE"
+	OpILike             Op = "ILIKE"
+	OpNotILike          Op = "NOT ILIKE"
+	OpSimilarTo         Op = "SIMILAR TO"
+	OpNotSimilarTo      Op = "NOT SIMILAR TO"
+	OpIsDistinctFrom    Op = "IS DISTINCT FROM"
+	OpIsNotDistinctFrom Op = "IS NOT DISTINCT FROM"
+	OpMatch             Op = "~"
+	OpIMatch            Op = "~*"
+	OpNotMatch          Op = "!~"
+	OpNotIMatch         Op = "!~*"
+	OpContains          Op = "@>"
+	OpContainedBy       Op = "<@"
+	OpOverlaps          Op = "&&"
+	OpEqAny             Op = "= ANY"
+	OpNeAll             Op = "!= ALL"
+)
+
+// Valid reports whether the operator is known. The case of the operator, and
+// any surrounding whitespace is ignored.
+func (o Op) Valid() bool {
+	_, ok := operators[strings.ToUpper(strings.TrimSpace(string(o)))]
+	return ok
+}
+
+// checkOp records an error on the Query if the given operator is not known.
+func (q Query) checkOp(op Op) Query {
+	if !op.Valid() {
+		q.errs = append(q.errs, fmt.Errorf("query: %w %q", ErrOperator, string(op)))
+	}
+	return q
+}
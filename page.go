@@ -0,0 +1,117 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+)
+
+var (
+	// DefaultPerPage is the number of rows on a page used by Paginate when the
+	// given number is less than 1.
+	DefaultPerPage = 25
+
+	// MaxPerPage is the maximum number of rows on a page used by Paginate,
+	// anything more than this is capped.
+	MaxPerPage = 100
+)
+
+// Paginate appends a LIMIT and OFFSET clause to the Query for the given page,
+// with the given number of rows on each page. Pages start at 1, so a page less
+// than 1 is treated as the first page. If the number of rows on a page is less
+// than 1 then DefaultPerPage is used, and if it is more than MaxPerPage then
+// MaxPerPage is used. This means the values from a request can be given as
+// they are, for example,
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.OrderDesc("created_at"),
+//         query.Paginate(3, 25),
+//     )
+//
+// would result in the following query being built,
+//
+//     SELECT * FROM posts ORDER BY created_at DESC LIMIT 25 OFFSET 50
+func Paginate(page, perPage int) Option {
+	if page < 1 {
+		page = 1
+	}
+
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	}
+
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	n := int64(perPage)
+
+	return func(q Query) Query {
+		q = Limit(n)(q)
+		return Offset(int64(page-1) * n)(q)
+	}
+}
+
+// Page executes the given SELECT query on the given database, scanning each of
+// the returned rows into a T via queryscan in the same way as All, then
+// executes the CountOf the query for the total number of rows across all
+// pages. This is the typical use of Paginate for a list endpoint, for example,
+//
+//     posts, total, err := query.Page[Post](ctx, db, query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.OrderDesc("created_at"),
+//         query.Paginate(page, perPage),
+//     ))
+//
+// If the database is a TxBeginner, such as *sql.DB, then both queries are
+// executed in a single read only transaction with the repeatable read
+// isolation level, so the total is consistent with the page of rows. If the
+// database is already a transaction, such as *sql.Tx, then both queries are
+// executed in that transaction.
+func Page[T any](ctx context.Context, db Execer, q Query) ([]T, int64, error) {
+	b, ok := db.(TxBeginner)
+
+	if !ok {
+		return page[T](ctx, db, q)
+	}
+
+	tx, err := b.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, total, err := page[T](ctx, tx, q)
+
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// page executes the given query and its CountOf on the given database, one
+// after the other.
+func page[T any](ctx context.Context, db Execer, q Query) ([]T, int64, error) {
+	items, err := All[T](ctx, db, q)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+
+	if err := CountOf(q).QueryRowContext(ctx, db).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
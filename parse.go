@@ -0,0 +1,1240 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type tokenKind uint
+
+const (
+	_EOF tokenKind = iota
+	_Word
+	_String
+	_Number
+	_Param
+	_Op
+	_LParen
+	_RParen
+	_Comma
+	_Semi
+)
+
+// token is a single token of the SQL being parsed, along with its position in
+// the SQL.
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+	end  int
+}
+
+// parser parses the SQL given to Parse into a Query.
+type parser struct {
+	sql  string
+	toks []token
+	i    int
+	args []interface{}
+
+	// next is the index of the argument for the next ? placeholder.
+	next int
+}
+
+// Parse parses the given SQL into a Query, using the given arguments for the
+// placeholders in the SQL. The placeholders can either be numbered, such as
+// $1, or be ?, but the ? operator cannot be used with ? placeholders. This
+// allows for existing SQL to be moved over to a Query, so it can be modified
+// with options and hooks, for example,
+//
+//     q, err := query.Parse("SELECT * FROM posts WHERE user_id = $1 ORDER BY created_at DESC", 10)
+//
+//     if err != nil {
+//         // Handle error.
+//     }
+//
+//     q = query.Options(query.Where("published", "=", query.Arg(true)), query.Limit(25))(q)
+//
+// Only a subset of SQL is supported, this is SELECT, INSERT, UPDATE, and
+// DELETE statements made up of the clauses that can be built via a Query.
+// Predicates in a WHERE clause are parsed into a column, operator, and value
+// where possible, with subqueries parsed into a Query, otherwise they are kept
+// as raw SQL. String literals are kept as they are, and a ? within one is not
+// taken as a placeholder. ErrSyntax is returned if the SQL cannot be parsed,
+// and ErrUnsupported is returned if the SQL uses something that is not
+// supported, such as HAVING, or a WITH query.
+func Parse(sql string, args ...interface{}) (Query, error) {
+	toks, err := lex(sql)
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	p := parser{
+		sql:  sql,
+		toks: toks,
+		args: args,
+	}
+
+	q, err := p.parseQuery()
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	p.accept(";")
+
+	if tok := p.peek(); tok.kind != _EOF {
+		return Query{}, p.unexpected(tok)
+	}
+	return q, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// opChars are the characters that make up an operator.
+const opChars = "=<>!~*@&|+-/%^#:"
+
+// lex splits the given SQL into tokens.
+func lex(sql string) ([]token, error) {
+	toks := make([]token, 0, len(sql)/4)
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			j := strings.IndexByte(sql[i:], '\n')
+
+			if j < 0 {
+				j = len(sql) - i
+			}
+			i += j
+		case strings.HasPrefix(sql[i:], "/*"):
+			j := strings.Index(sql[i+2:], "*/")
+
+			if j < 0 {
+				return nil, fmt.Errorf("query: %w at position %d: unterminated comment", ErrSyntax, i)
+			}
+			i += j + 4
+		case c == '\'':
+			j, err := lexQuoted(sql, i)
+
+			if err != nil {
+				return nil, err
+			}
+
+			toks = append(toks, token{kind: _String, val: sql[i:j], pos: i, end: j})
+			i = j
+		case c == '"' || c == '`' || isIdentStart(c):
+			j, err := lexIdent(sql, i)
+
+			if err != nil {
+				return nil, err
+			}
+
+			toks = append(toks, token{kind: _Word, val: sql[i:j], pos: i, end: j})
+			i = j
+		case isDigit(c):
+			j := i
+
+			for j < len(sql) && (isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+
+			toks = append(toks, token{kind: _Number, val: sql[i:j], pos: i, end: j})
+			i = j
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			j := i + 1
+
+			for j < len(sql) && isDigit(sql[j]) {
+				j++
+			}
+
+			toks = append(toks, token{kind: _Param, val: sql[i:j], pos: i, end: j})
+			i = j
+		case c == '?':
+			if i+1 < len(sql) && (sql[i+1] == '|' || sql[i+1] == '&') {
+				toks = append(toks, token{kind: _Op, val: sql[i : i+2], pos: i, end: i + 2})
+				i += 2
+				continue
+			}
+
+			toks = append(toks, token{kind: _Param, val: "?", pos: i, end: i + 1})
+			i++
+		case c == '(':
+			toks = append(toks, token{kind: _LParen, val: "(", pos: i, end: i + 1})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: _RParen, val: ")", pos: i, end: i + 1})
+			i++
+		case c == ',':
+			toks = append(toks, token{kind: _Comma, val: ",", pos: i, end: i + 1})
+			i++
+		case c == ';':
+			toks = append(toks, token{kind: _Semi, val: ";", pos: i, end: i + 1})
+			i++
+		case strings.IndexByte(opChars, c) >= 0:
+			j := i
+
+			for j < len(sql) && strings.IndexByte(opChars, sql[j]) >= 0 {
+				j++
+			}
+
+			toks = append(toks, token{kind: _Op, val: sql[i:j], pos: i, end: j})
+			i = j
+		default:
+			return nil, fmt.Errorf("query: %w at position %d: unexpected %q", ErrSyntax, i, c)
+		}
+	}
+	return append(toks, token{kind: _EOF, pos: len(sql), end: len(sql)}), nil
+}
+
+// lexQuoted returns the end of the string or quoted identifier at the given
+// position. A quote within the string is escaped by doubling it.
+func lexQuoted(sql string, i int) (int, error) {
+	quote := sql[i]
+
+	for j := i + 1; j < len(sql); j++ {
+		if sql[j] != quote {
+			continue
+		}
+
+		if j+1 < len(sql) && sql[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1, nil
+	}
+	return 0, fmt.Errorf("query: %w at position %d: unterminated %c", ErrSyntax, i, quote)
+}
+
+// lexIdent returns the end of the identifier at the given position. Each part
+// of a qualified identifier is included, such as schema.table, or p.*.
+func lexIdent(sql string, i int) (int, error) {
+	for {
+		switch c := sql[i]; {
+		case c == '"' || c == '`':
+			j, err := lexQuoted(sql, i)
+
+			if err != nil {
+				return 0, err
+			}
+			i = j
+		case c == '*':
+			return i + 1, nil
+		default:
+			for i < len(sql) && (isIdentStart(sql[i]) || isDigit(sql[i]) || sql[i] == '$') {
+				i++
+			}
+		}
+
+		if i+1 >= len(sql) || sql[i] != '.' {
+			return i, nil
+		}
+
+		if c := sql[i+1]; !isIdentStart(c) && c != '"' && c != '`' && c != '*' {
+			return i, nil
+		}
+		i++
+	}
+}
+
+// peek returns the current token.
+func (p *parser) peek() token { return p.toks[p.i] }
+
+// is reports whether the tokens from the given index match the given words,
+// ignoring case.
+func (p *parser) is(i int, words ...string) bool {
+	for j, word := range words {
+		if i+j >= len(p.toks) {
+			return false
+		}
+
+		tok := p.toks[i+j]
+
+		switch tok.kind {
+		case _Word, _Op, _LParen, _RParen, _Comma, _Semi:
+			if !strings.EqualFold(tok.val, word) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// accept advances past the given words if they are the current tokens.
+func (p *parser) accept(words ...string) bool {
+	if !p.is(p.i, words...) {
+		return false
+	}
+	p.i += len(words)
+	return true
+}
+
+// expect advances past the given words, returning ErrSyntax if they are not
+// the current tokens.
+func (p *parser) expect(words ...string) error {
+	if !p.accept(words...) {
+		return fmt.Errorf("query: %w at position %d: expected %s", ErrSyntax, p.peek().pos, strings.Join(words, " "))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == _EOF {
+		return fmt.Errorf("query: %w: unexpected end of SQL", ErrSyntax)
+	}
+	return fmt.Errorf("query: %w at position %d: unexpected %q", ErrSyntax, tok.pos, tok.val)
+}
+
+// unsupported returns ErrUnsupported for the current tokens.
+func (p *parser) unsupported(what string) error {
+	return fmt.Errorf("query: %w at position %d: %s", ErrUnsupported, p.peek().pos, what)
+}
+
+// stops are the keywords that end each clause.
+var stops = [][]string{
+	{"FROM"},
+	{"WHERE"},
+	{"GROUP", "BY"},
+	{"HAVING"},
+	{"WINDOW"},
+	{"ORDER", "BY"},
+	{"LIMIT"},
+	{"OFFSET"},
+	{"FETCH"},
+	{"UNION"},
+	{"INTERSECT"},
+	{"EXCEPT"},
+	{"RETURNING"},
+	{"ON", "CONFLICT"},
+}
+
+// scan advances to the end of the current clause, this is the next keyword in
+// stops, or the end of the SQL, that is not within parentheses. The start and
+// end of the tokens of the clause are returned.
+func (p *parser) scan() (int, int) {
+	start := p.i
+	depth := 0
+
+	for ; ; p.i++ {
+		tok := p.peek()
+
+		switch tok.kind {
+		case _EOF, _Semi:
+			return start, p.i
+		case _LParen:
+			depth++
+		case _RParen:
+			if depth == 0 {
+				return start, p.i
+			}
+			depth--
+		case _Word:
+			if depth > 0 {
+				continue
+			}
+
+			// The FROM of IS DISTINCT FROM does not start a clause.
+			if p.i > start && p.is(p.i-1, "DISTINCT", "FROM") {
+				continue
+			}
+
+			for _, stop := range stops {
+				if p.is(p.i, stop...) {
+					return start, p.i
+				}
+			}
+		}
+	}
+}
+
+// split splits the given tokens on each comma that is not within parentheses.
+func (p *parser) split(start, end int) [][2]int {
+	var (
+		parts [][2]int
+		depth int
+	)
+
+	from := start
+
+	for i := start; i < end; i++ {
+		switch p.toks[i].kind {
+		case _LParen:
+			depth++
+		case _RParen:
+			depth--
+		case _Comma:
+			if depth == 0 {
+				parts = append(parts, [2]int{from, i})
+				from = i + 1
+			}
+		}
+	}
+	return append(parts, [2]int{from, end})
+}
+
+// closes reports whether the parenthesis at the start of the given tokens is
+// closed by the last token.
+func (p *parser) closes(start, end int) bool {
+	if end-start < 2 || p.toks[start].kind != _LParen || p.toks[end-1].kind != _RParen {
+		return false
+	}
+
+	depth := 0
+
+	for i := start; i < end; i++ {
+		switch p.toks[i].kind {
+		case _LParen:
+			depth++
+		case _RParen:
+			depth--
+
+			if depth == 0 {
+				return i == end-1
+			}
+		}
+	}
+	return false
+}
+
+// arg returns the argument for the given placeholder.
+func (p *parser) arg(tok token) (interface{}, error) {
+	i := p.next
+
+	if tok.val == "?" {
+		p.next++
+	} else {
+		n, err := strconv.Atoi(tok.val[1:])
+
+		if err != nil {
+			return nil, fmt.Errorf("query: %w at position %d: invalid placeholder %s", ErrSyntax, tok.pos, tok.val)
+		}
+		i = n - 1
+	}
+
+	if i < 0 || i >= len(p.args) {
+		return nil, fmt.Errorf("query: %w at position %d: no argument for placeholder %s", ErrSyntax, tok.pos, tok.val)
+	}
+	return p.args[i], nil
+}
+
+// raw returns the given tokens as a raw expression, the placeholders are
+// replaced with ? and their arguments collected.
+func (p *parser) raw(start, end int) (rawExpr, error) {
+	if start >= end {
+		return rawExpr{}, p.unexpected(p.toks[end])
+	}
+
+	var (
+		buf  strings.Builder
+		args []interface{}
+	)
+
+	last := p.toks[start].pos
+
+	for _, tok := range p.toks[start:end] {
+		if tok.kind != _Param {
+			continue
+		}
+
+		arg, err := p.arg(tok)
+
+		if err != nil {
+			return rawExpr{}, err
+		}
+
+		buf.WriteString(p.sql[last:tok.pos])
+		buf.WriteByte('?')
+
+		args = append(args, arg)
+		last = tok.end
+	}
+
+	buf.WriteString(p.sql[last:p.toks[end-1].end])
+
+	return rawExpr{
+		sql:  buf.String(),
+		args: args,
+	}, nil
+}
+
+// text returns the SQL of the given tokens, returning ErrUnsupported if they
+// contain a placeholder.
+func (p *parser) text(start, end int) (string, error) {
+	if start >= end {
+		return "", p.unexpected(p.toks[end])
+	}
+
+	for _, tok := range p.toks[start:end] {
+		if tok.kind == _Param {
+			return "", fmt.Errorf("query: %w at position %d: placeholder in %s", ErrUnsupported, tok.pos, p.sql[p.toks[start].pos:p.toks[end-1].end])
+		}
+	}
+	return p.sql[p.toks[start].pos:p.toks[end-1].end], nil
+}
+
+// idents returns the identifiers in the given tokens if they are a list of
+// identifiers.
+func (p *parser) idents(start, end int) ([]string, bool) {
+	var cols []string
+
+	for _, part := range p.split(start, end) {
+		if part[1]-part[0] != 1 {
+			return nil, false
+		}
+
+		tok := p.toks[part[0]]
+
+		if tok.kind != _Word && tok.val != "*" {
+			return nil, false
+		}
+		cols = append(cols, tok.val)
+	}
+	return cols, true
+}
+
+// ident parses a single identifier, such as a table name.
+func (p *parser) ident() (string, error) {
+	tok := p.peek()
+
+	if tok.kind != _Word {
+		return "", p.unexpected(tok)
+	}
+
+	p.i++
+	return tok.val, nil
+}
+
+// parenIdents parses a list of identifiers wrapped in parentheses.
+func (p *parser) parenIdents() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	start, end := p.scan()
+	cols, ok := p.idents(start, end)
+
+	if !ok {
+		return nil, p.unsupported("expected list of columns")
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return cols, nil
+}
+
+// parseQuery parses a SELECT, INSERT, UPDATE, or DELETE statement.
+func (p *parser) parseQuery() (Query, error) {
+	switch {
+	case p.is(p.i, "SELECT"):
+		return p.parseUnion()
+	case p.is(p.i, "INSERT"):
+		return p.parseInsert()
+	case p.is(p.i, "UPDATE"):
+		return p.parseUpdate()
+	case p.is(p.i, "DELETE"):
+		return p.parseDelete()
+	case p.is(p.i, "WITH"):
+		return Query{}, p.unsupported("WITH")
+	}
+	return Query{}, p.unexpected(p.peek())
+}
+
+// parseUnion parses a SELECT statement, along with any other SELECT statements
+// it is in a UNION with.
+func (p *parser) parseUnion() (Query, error) {
+	q, err := p.parseSelect()
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	if !p.is(p.i, "UNION") {
+		return q, nil
+	}
+
+	qq := []Query{q}
+
+	for p.accept("UNION") {
+		if p.is(p.i, "ALL") {
+			return Query{}, p.unsupported("UNION ALL")
+		}
+
+		q, err := p.parseSelect()
+
+		if err != nil {
+			return Query{}, err
+		}
+		qq = append(qq, q)
+	}
+	return Union(qq...), nil
+}
+
+func (p *parser) parseSelect() (Query, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return Query{}, err
+	}
+
+	var (
+		stmt = _Select
+		on   []string
+	)
+
+	if p.accept("DISTINCT") {
+		stmt = _SelectDistinct
+
+		if p.accept("ON") {
+			cols, err := p.parenIdents()
+
+			if err != nil {
+				return Query{}, err
+			}
+
+			stmt = _SelectDistinctOn
+			on = cols
+		}
+	}
+
+	start, end := p.scan()
+
+	var expr Expr
+
+	if cols, ok := p.idents(start, end); ok {
+		expr = Columns(cols...)
+	} else {
+		raw, err := p.raw(start, end)
+
+		if err != nil {
+			return Query{}, err
+		}
+		expr = raw
+	}
+
+	var opts []Option
+
+	if p.accept("FROM") {
+		table, err := p.text(p.scan())
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, From(table))
+	}
+
+	if p.is(p.i, "WHERE") {
+		opt, err := p.parseWhere()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+
+	if p.accept("GROUP", "BY") {
+		start, end := p.scan()
+
+		var cols []string
+
+		for _, part := range p.split(start, end) {
+			col, err := p.text(part[0], part[1])
+
+			if err != nil {
+				return Query{}, err
+			}
+			cols = append(cols, col)
+		}
+		opts = append(opts, GroupBy(cols...))
+	}
+
+	for _, kw := range []string{"HAVING", "WINDOW"} {
+		if p.is(p.i, kw) {
+			return Query{}, p.unsupported(kw)
+		}
+	}
+
+	if p.accept("ORDER", "BY") {
+		opt, err := p.parseOrder()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+
+	if p.accept("LIMIT") {
+		n, err := p.parseInt()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, Limit(n))
+	}
+
+	if p.accept("OFFSET") {
+		n, err := p.parseInt()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, Offset(n))
+	}
+
+	for _, kw := range []string{"FETCH", "INTERSECT", "EXCEPT"} {
+		if p.is(p.i, kw) {
+			return Query{}, p.unsupported(kw)
+		}
+	}
+
+	switch stmt {
+	case _SelectDistinct:
+		return SelectDistinct(expr, opts...), nil
+	case _SelectDistinctOn:
+		return SelectDistinctOn(on, expr, opts...), nil
+	}
+	return Select(expr, opts...), nil
+}
+
+func (p *parser) parseInsert() (Query, error) {
+	if err := p.expect("INSERT", "INTO"); err != nil {
+		return Query{}, err
+	}
+
+	table, err := p.ident()
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	if !p.is(p.i, "(") {
+		return Query{}, p.unsupported("INSERT without columns")
+	}
+
+	cols, err := p.parenIdents()
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	if !p.accept("VALUES") {
+		return Query{}, p.unsupported("INSERT without VALUES")
+	}
+
+	var opts []Option
+
+	for {
+		if err := p.expect("("); err != nil {
+			return Query{}, err
+		}
+
+		v, err := p.parseValues()
+
+		if err != nil {
+			return Query{}, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return Query{}, err
+		}
+
+		opts = append(opts, func(q Query) Query {
+			q.clauses = append(q.clauses, v)
+			return q
+		})
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.accept("ON", "CONFLICT") {
+		opt, err := p.parseConflict()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+
+	if p.accept("RETURNING") {
+		opt, err := p.parseReturning()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+	return Insert(table, Columns(cols...), opts...), nil
+}
+
+// parseValues parses the values for a single row of a VALUES clause.
+func (p *parser) parseValues() (valuesClause, error) {
+	var v valuesClause
+
+	for _, part := range p.split(p.scan()) {
+		raw, err := p.raw(part[0], part[1])
+
+		if err != nil {
+			return valuesClause{}, err
+		}
+
+		v.items = append(v.items, raw.sql)
+		v.args = append(v.args, raw.args...)
+	}
+	return v, nil
+}
+
+// parseConflict parses the columns and action of an ON CONFLICT clause. Only
+// DO NOTHING, and DO UPDATE SET for the excluded values of columns are
+// supported.
+func (p *parser) parseConflict() (Option, error) {
+	cols, err := p.parenIdents()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("DO", "NOTHING") {
+		return OnConflictDoNothing(cols...), nil
+	}
+
+	if err := p.expect("DO", "UPDATE", "SET"); err != nil {
+		return nil, err
+	}
+
+	var update []string
+
+	for _, part := range p.split(p.scan()) {
+		start, end := part[0], part[1]
+
+		if end-start != 3 || !p.is(start+1, "=") {
+			return nil, p.unsupported("ON CONFLICT DO UPDATE without EXCLUDED")
+		}
+
+		col := p.toks[start].val
+
+		if !strings.EqualFold(p.toks[start+2].val, "EXCLUDED."+col) {
+			return nil, p.unsupported("ON CONFLICT DO UPDATE without EXCLUDED")
+		}
+		update = append(update, col)
+	}
+	return OnConflictUpdate(cols, update...), nil
+}
+
+// parseReturning parses the columns or expressions of a RETURNING clause.
+func (p *parser) parseReturning() (Option, error) {
+	start, end := p.scan()
+
+	if cols, ok := p.idents(start, end); ok {
+		return Returning(cols...), nil
+	}
+
+	var exprs []Expr
+
+	for _, part := range p.split(start, end) {
+		raw, err := p.raw(part[0], part[1])
+
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, raw)
+	}
+	return ReturningExpr(exprs...), nil
+}
+
+func (p *parser) parseUpdate() (Query, error) {
+	if err := p.expect("UPDATE"); err != nil {
+		return Query{}, err
+	}
+
+	table, err := p.ident()
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	if err := p.expect("SET"); err != nil {
+		return Query{}, err
+	}
+
+	var opts []Option
+
+	for _, part := range p.split(p.scan()) {
+		start, end := part[0], part[1]
+
+		if end-start < 3 || p.toks[start].kind != _Word || !p.is(start+1, "=") {
+			return Query{}, fmt.Errorf("query: %w at position %d: expected column = value", ErrSyntax, p.toks[start].pos)
+		}
+
+		expr, err := p.parseValue(start+2, end)
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, Set(p.toks[start].val, expr))
+	}
+
+	if p.accept("FROM") {
+		from, err := p.text(p.scan())
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, From(from))
+	}
+
+	if p.is(p.i, "WHERE") {
+		opt, err := p.parseWhere()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+
+	if p.accept("RETURNING") {
+		opt, err := p.parseReturning()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+	return Update(table, opts...), nil
+}
+
+func (p *parser) parseDelete() (Query, error) {
+	if err := p.expect("DELETE", "FROM"); err != nil {
+		return Query{}, err
+	}
+
+	table, err := p.ident()
+
+	if err != nil {
+		return Query{}, err
+	}
+
+	if p.is(p.i, "USING") {
+		return Query{}, p.unsupported("USING")
+	}
+
+	var opts []Option
+
+	if p.is(p.i, "WHERE") {
+		opt, err := p.parseWhere()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+
+	if p.accept("RETURNING") {
+		opt, err := p.parseReturning()
+
+		if err != nil {
+			return Query{}, err
+		}
+		opts = append(opts, opt)
+	}
+	return Delete(table, opts...), nil
+}
+
+// parseWhere parses the predicates of a WHERE clause. If the predicates are
+// conjoined with both AND and OR then they are kept as a single expression, so
+// the precedence of the operators is not changed.
+func (p *parser) parseWhere() (Option, error) {
+	if err := p.expect("WHERE"); err != nil {
+		return nil, err
+	}
+
+	start, end := p.scan()
+
+	type pred struct {
+		conj       string
+		start, end int
+	}
+
+	var (
+		preds   []pred
+		depth   int
+		between bool
+		and, or bool
+	)
+
+	from := start
+	conj := "AND"
+
+	for i := start; i < end; i++ {
+		tok := p.toks[i]
+
+		switch tok.kind {
+		case _LParen:
+			depth++
+		case _RParen:
+			depth--
+		case _Word:
+			if depth > 0 {
+				continue
+			}
+
+			switch strings.ToUpper(tok.val) {
+			case "BETWEEN":
+				between = true
+			case "AND":
+				// The AND of a BETWEEN is part of the predicate.
+				if between {
+					between = false
+					continue
+				}
+
+				and = true
+				preds = append(preds, pred{conj: conj, start: from, end: i})
+				from = i + 1
+				conj = "AND"
+			case "OR":
+				or = true
+				preds = append(preds, pred{conj: conj, start: from, end: i})
+				from = i + 1
+				conj = "OR"
+			}
+		}
+	}
+
+	preds = append(preds, pred{conj: conj, start: from, end: end})
+
+	if and && or {
+		raw, err := p.raw(start, end)
+
+		if err != nil {
+			return nil, err
+		}
+		return WhereExpr(raw), nil
+	}
+
+	opts := make([]Option, 0, len(preds))
+
+	for _, pred := range preds {
+		opt, err := p.parsePred(pred.conj, pred.start, pred.end)
+
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	return Options(opts...), nil
+}
+
+// parsePred parses a single predicate of a WHERE clause into a column,
+// operator, and value. If this cannot be done then the predicate is kept as a
+// raw expression.
+func (p *parser) parsePred(conj string, start, end int) (Option, error) {
+	if col := p.toks[start]; col.kind == _Word && !p.is(start, "NOT") && end-start >= 3 {
+		if op, i := p.parseOp(start+1, end); op != "" && i < end {
+			expr, err := p.parseValue(i, end)
+
+			if err != nil {
+				return nil, err
+			}
+			return realWhere(conj, Ident(col.val), op, expr), nil
+		}
+	}
+
+	raw, err := p.raw(start, end)
+
+	if err != nil {
+		return nil, err
+	}
+	return realWhere(conj, nil, "", raw), nil
+}
+
+// parseOp returns the operator at the given position, and the position of
+// the token after it, if it is one of the known operators.
+func (p *parser) parseOp(i, end int) (string, int) {
+	tok := p.toks[i]
+
+	if tok.kind == _Op {
+		op := tok.val
+
+		if i+2 < end && p.toks[i+1].kind == _Word && p.toks[i+2].kind == _LParen {
+			quant := op + " " + strings.ToUpper(p.toks[i+1].val)
+
+			if _, ok := operators[quant]; ok {
+				return quant, i + 2
+			}
+		}
+
+		if _, ok := operators[op]; ok {
+			return op, i + 1
+		}
+		return "", i
+	}
+
+	// Take the longest run of words that is a known operator, such as
+	// IS NOT DISTINCT FROM.
+	for n := 4; n > 0; n-- {
+		if i+n >= end {
+			continue
+		}
+
+		words := make([]string, 0, n)
+
+		for _, tok := range p.toks[i : i+n] {
+			if tok.kind != _Word {
+				break
+			}
+			words = append(words, strings.ToUpper(tok.val))
+		}
+
+		if len(words) != n {
+			continue
+		}
+
+		op := strings.Join(words, " ")
+
+		if _, ok := operators[op]; ok {
+			return op, i + n
+		}
+	}
+	return "", i
+}
+
+// parseValue parses the value of a predicate or SET clause. A single
+// placeholder is an argument, a subquery is parsed into a Query, and a list of
+// placeholders is a list of arguments. Anything else is kept as a raw
+// expression.
+func (p *parser) parseValue(start, end int) (Expr, error) {
+	if end-start == 1 && p.toks[start].kind == _Param {
+		arg, err := p.arg(p.toks[start])
+
+		if err != nil {
+			return nil, err
+		}
+		return Arg(arg), nil
+	}
+
+	if p.closes(start, end) {
+		if p.is(start+1, "SELECT") {
+			i := p.i
+			p.i = start + 1
+
+			q, err := p.parseUnion()
+
+			if err != nil {
+				return nil, err
+			}
+
+			if p.i != end-1 {
+				return nil, p.unexpected(p.peek())
+			}
+
+			p.i = i
+			return q, nil
+		}
+
+		if params, ok := p.params(start+1, end-1); ok {
+			vals := make([]interface{}, 0, len(params))
+
+			for _, tok := range params {
+				arg, err := p.arg(tok)
+
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, arg)
+			}
+			return List(vals...), nil
+		}
+	}
+	return p.raw(start, end)
+}
+
+// params returns the placeholders in the given tokens if they are a list of
+// placeholders.
+func (p *parser) params(start, end int) ([]token, bool) {
+	var params []token
+
+	for _, part := range p.split(start, end) {
+		if part[1]-part[0] != 1 || p.toks[part[0]].kind != _Param {
+			return nil, false
+		}
+		params = append(params, p.toks[part[0]])
+	}
+	return params, true
+}
+
+// parseOrder parses the columns of an ORDER BY clause.
+func (p *parser) parseOrder() (Option, error) {
+	var opts []Option
+
+	for _, part := range p.split(p.scan()) {
+		start, end := part[0], part[1]
+
+		if start == end || p.toks[start].kind != _Word {
+			return nil, p.unsupported("ORDER BY expression")
+		}
+
+		cl := orderClause{cols: []string{p.toks[start].val}}
+
+		switch {
+		case end-start == 1:
+		case end-start == 2 && p.is(start+1, "ASC"):
+			cl.dir = "ASC"
+		case end-start == 2 && p.is(start+1, "DESC"):
+			cl.dir = "DESC"
+		default:
+			return nil, p.unsupported("ORDER BY expression")
+		}
+
+		opts = append(opts, func(q Query) Query {
+			q.clauses = append(q.clauses, cl)
+			return q
+		})
+	}
+	return Options(opts...), nil
+}
+
+// parseInt parses the integer of a LIMIT or OFFSET clause, this can be given
+// as a placeholder.
+func (p *parser) parseInt() (int64, error) {
+	tok := p.peek()
+	p.i++
+
+	switch tok.kind {
+	case _Number:
+		n, err := strconv.ParseInt(tok.val, 10, 64)
+
+		if err != nil {
+			return 0, fmt.Errorf("query: %w at position %d: invalid integer %s", ErrSyntax, tok.pos, tok.val)
+		}
+		return n, nil
+	case _Param:
+		arg, err := p.arg(tok)
+
+		if err != nil {
+			return 0, err
+		}
+
+		rv := reflect.ValueOf(arg)
+
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return rv.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int64(rv.Uint()), nil
+		}
+		return 0, fmt.Errorf("query: %w at position %d: expected integer for %s, got %T", ErrSyntax, tok.pos, tok.val, arg)
+	}
+	return 0, p.unexpected(tok)
+}
@@ -0,0 +1,215 @@
+package query
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Placeholder is the style of placeholder used for the arguments in a query
+// once it has been built.
+type Placeholder uint
+
+const (
+	Dollar   Placeholder = iota // $1, $2, ... as used by PostgreSQL
+	Question                    // ?, ?, ... as used by MySQL and SQLite
+	Colon                       // :1, :2, ... as used by Oracle
+	AtP                         // @p1, @p2, ... as used by SQL Server
+	AtName                      // @name, ... for arguments given via Named
+	ColonName                   // :name, ... for arguments given via Named
+)
+
+// named reports whether the placeholder style uses the names of arguments.
+func (p Placeholder) named() bool { return p == AtName || p == ColonName }
+
+// numbered reports whether the placeholder style refers to arguments by their
+// position, allowing for the same argument to be used more than once.
+func (p Placeholder) numbered() bool { return p == Dollar || p == Colon || p == AtP }
+
+// appendParam appends the placeholder for the nth parameter to the given
+// buffer.
+func (p Placeholder) appendParam(buf []byte, n int64) []byte {
+	switch p {
+	case Question:
+		return append(buf, '?')
+	case Colon:
+		buf = append(buf, ':')
+	case AtP:
+		buf = append(buf, '@', 'p')
+	case AtName:
+		buf = append(buf, '@', 'p')
+	case ColonName:
+		buf = append(buf, ':', 'p')
+	default:
+		buf = append(buf, '$')
+	}
+	return strconv.AppendInt(buf, n, 10)
+}
+
+// Rebind replaces each ? in the given SQL with the given placeholder style,
+// numbering each parameter from 1. This is the same conversion used when a
+// Query is built, so it can be used for any raw SQL written with ?, for
+// example,
+//
+//     db.Query(query.Rebind("SELECT * FROM users WHERE id = ?", query.Dollar), 10)
+//
+// A ? within a quoted string or identifier is left as is.
+func Rebind(s string, p Placeholder) string {
+	s, _ = rebindFrom(s, p, 1)
+	return s
+}
+
+// rebindFrom replaces each ? in the given string with the given placeholder
+// style, numbering each parameter from start. The number of the next parameter
+// is returned.
+func rebindFrom(s string, p Placeholder, start int64) (string, int64) {
+	if p == Question {
+		return s, start + int64(countParams(s))
+	}
+
+	buf, next := appendRebind(make([]byte, 0, rebindLen(s)), s, p, start, nil, nil)
+	return string(buf), next
+}
+
+// unnamed returns the given arguments with the value of each sql.NamedArg in
+// place of the sql.NamedArg itself.
+func unnamed(args []interface{}) []interface{} {
+	for i, arg := range args {
+		if _, ok := arg.(sql.NamedArg); ok {
+			vals := make([]interface{}, 0, len(args))
+			vals = append(vals, args[:i]...)
+
+			for _, arg := range args[i:] {
+				if named, ok := arg.(sql.NamedArg); ok {
+					arg = named.Value
+				}
+				vals = append(vals, arg)
+			}
+			return vals
+		}
+	}
+	return args
+}
+
+// skipQuoted returns the index of the closing quote if the byte at i of the
+// given string opens a quoted string or identifier, or the length of the
+// string if it is never closed. Otherwise i is returned.
+func skipQuoted(s string, i int) int {
+	switch c := s[i]; c {
+	case '\'', '"', '`':
+		if j := strings.IndexByte(s[i+1:], c); j >= 0 {
+			return i + j + 1
+		}
+		return len(s)
+	}
+	return i
+}
+
+// countParams returns the number of ? placeholders in the given string, not
+// counting any within quoted strings and identifiers.
+func countParams(s string) int {
+	if !strings.ContainsAny(s, "'\"`") {
+		return strings.Count(s, "?")
+	}
+
+	n := 0
+
+	for i := 0; i < len(s); i++ {
+		if j := skipQuoted(s, i); j != i {
+			i = j
+			continue
+		}
+
+		if s[i] == '?' {
+			n++
+		}
+	}
+	return n
+}
+
+// rebindLen returns an estimate of the length of the given string once its
+// placeholders have been replaced, so the buffer it is written to only needs
+// to be allocated once in most cases.
+func rebindLen(s string) int {
+	n := strings.Count(s, "?")
+	return len(s) + n*len(strconv.Itoa(n)) + n*2
+}
+
+// appendRebind appends the given string to the given buffer in a single pass,
+// replacing each ? with the given placeholder style. Parameters are numbered
+// from start, unless an index is given in which case the number of each
+// parameter is taken from the index. If arguments are given, then any
+// sql.NamedArg will have its name used for its placeholder. A ? within a
+// quoted string or identifier is left as is. The number of the next parameter
+// is returned.
+func appendRebind(buf []byte, s string, p Placeholder, start int64, index []int64, args []interface{}) ([]byte, int64) {
+	next := start
+	last := 0
+	n := 0
+
+	for i := 0; i < len(s); i++ {
+		if j := skipQuoted(s, i); j != i {
+			i = j
+			continue
+		}
+
+		if s[i] != '?' {
+			continue
+		}
+
+		buf = append(buf, s[last:i]...)
+		last = i + 1
+
+		param := start + int64(n)
+
+		if n < len(index) {
+			param = start + index[n]
+		}
+
+		if param >= next {
+			next = param + 1
+		}
+
+		if n < len(args) && p.named() {
+			if arg, ok := args[n].(sql.NamedArg); ok {
+				if p == AtName {
+					buf = append(buf, '@')
+				} else {
+					buf = append(buf, ':')
+				}
+
+				buf = append(buf, arg.Name...)
+				n++
+				continue
+			}
+		}
+
+		buf = p.appendParam(buf, param)
+		n++
+	}
+	return append(buf, s[last:]...), next
+}
+
+// dedupe returns the given arguments with any repeated arguments removed, along
+// with the position of each of the given arguments in the returned arguments.
+// Only arguments of a comparable type are deduplicated.
+func dedupe(args []interface{}) ([]interface{}, []int64) {
+	vals := make([]interface{}, 0, len(args))
+	index := make([]int64, 0, len(args))
+	seen := make(map[interface{}]int64)
+
+	for _, arg := range args {
+		if arg != nil && reflect.TypeOf(arg).Comparable() {
+			if i, ok := seen[arg]; ok {
+				index = append(index, i)
+				continue
+			}
+			seen[arg] = int64(len(vals))
+		}
+
+		index = append(index, int64(len(vals)))
+		vals = append(vals, arg)
+	}
+	return vals, index
+}
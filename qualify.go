@@ -0,0 +1,120 @@
+package query
+
+import "strings"
+
+// Qualify qualifies the unqualified columns of the Query with the alias of the
+// table the Query operates on, or with the name of the table if it has no
+// alias. This applies to the columns given via Columns, the columns of WHERE
+// clauses, and the columns of GROUP BY and ORDER BY clauses, so a query stays
+// unambiguous if a join is added to it later, for example,
+//
+//     q := query.Select(
+//         query.Columns("id", "title", "u.username"),
+//         query.From("posts p JOIN users u ON u.id = p.user_id"),
+//         query.Where("user_id", "=", query.Arg(10)),
+//         query.OrderDesc("created_at"),
+//         query.Qualify(),
+//     )
+//
+// would result in the following query being built,
+//
+//     SELECT p.id, p.title, u.username FROM posts p JOIN users u ON u.id = p.user_id WHERE (p.user_id = $1) ORDER BY p.created_at DESC
+//
+// Columns are qualified when the Query is built, after any hooks have been
+// applied. The columns of an INSERT query are never qualified. Only plain
+// identifiers are qualified, so columns that are already qualified, and
+// expressions such as COUNT(*), are left as they are.
+func Qualify() Option {
+	return func(q Query) Query {
+		q.qualify = true
+		return q
+	}
+}
+
+// qualified returns the Query with its unqualified columns qualified with the
+// alias of its table.
+func (q Query) qualified() Query {
+	alias := q.alias()
+
+	if alias == "" || q.stmt == _Insert {
+		return q
+	}
+
+	exprs := make([]Expr, 0, len(q.exprs))
+
+	for _, e := range q.exprs {
+		if list, ok := e.(listExpr); ok && !list.wrap && q.stmt != _Update && q.stmt != _Delete {
+			e = list.qualify(alias)
+		}
+		exprs = append(exprs, e)
+	}
+
+	clauses := make([]clause, 0, len(q.clauses))
+
+	for _, cl := range q.clauses {
+		switch v := cl.(type) {
+		case whereClause:
+			switch left := v.left.(type) {
+			case identExpr:
+				v.left = identExpr(alias.qualify(string(left)))
+			case listExpr:
+				v.left = left.qualify(alias)
+			}
+			cl = v
+		case orderClause:
+			cols := make([]string, 0, len(v.cols))
+
+			for _, col := range v.cols {
+				cols = append(cols, alias.qualify(col))
+			}
+			v.cols = cols
+			cl = v
+		case groupClause:
+			cols := make([]string, 0, len(v.cols))
+
+			for _, col := range v.cols {
+				cols = append(cols, alias.qualify(col))
+			}
+			v.cols = cols
+			cl = v
+		}
+		clauses = append(clauses, cl)
+	}
+
+	q.exprs = exprs
+	q.clauses = clauses
+	return q
+}
+
+// qualify returns the list with each of its items qualified with the given
+// alias.
+func (e listExpr) qualify(alias Alias) listExpr {
+	items := make([]string, 0, len(e.items))
+
+	for _, item := range e.items {
+		items = append(items, alias.qualify(item))
+	}
+
+	e.items = items
+	return e
+}
+
+// qualify qualifies the given column with the alias if it is a plain
+// identifier, optionally followed by an alias of its own.
+func (a Alias) qualify(col string) string {
+	name := col
+
+	if i := strings.IndexByte(col, ' '); i > 0 {
+		name = col[:i]
+	}
+
+	if !identPattern.MatchString(name) {
+		return col
+	}
+
+	switch strings.ToUpper(name) {
+	case "NULL", "TRUE", "FALSE", "DEFAULT", "DISTINCT":
+		return col
+	}
+	return a.Col(col)
+}
@@ -1,18 +1,64 @@
 package query
 
 import (
+	"database/sql"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type statement uint
 
+// MaxParams is the maximum number of parameters that PostgreSQL allows in a
+// single query.
+const MaxParams = 65535
+
+// Strict makes a Query fail to build if it has an error from Err, rather than
+// silently dropping the options that were misused, or building them into
+// invalid SQL. The error is returned from the methods that execute the Query,
+// such as ExecContext, and from BuildTo, ToSql, and Template, whereas Build
+// returns an empty string. This is meant to be enabled in tests, so the misuse
+// of options is caught early, for example,
+//
+//     func TestMain(m *testing.M) {
+//         query.Strict = true
+//         os.Exit(m.Run())
+//     }
+var Strict bool
+
 // Option is the type for the first class functions that should be used for
 // modifying a Query as it is being built. This will be passed the latest
 // state of the Query, and should return that same Query once any modifications
 // have been made.
 type Option func(Query) Query
 
+// Alias is the name by which a table is referred to within a Query. This is
+// either the alias given to the table in the FROM clause, or the name of the
+// table itself.
+type Alias string
+
+// Col returns the given column qualified with the alias.
+func (a Alias) Col(col string) string {
+	if a == "" {
+		return col
+	}
+	return string(a) + "." + col
+}
+
+// Ident returns an identifier expression for the given column qualified with
+// the alias.
+func (a Alias) Ident(col string) identExpr { return Ident(a.Col(col)) }
+
+// Sqlizer is the interface implemented by types that can build SQL along with
+// the arguments for it. The method name matches the one used by other query
+// builders, so a Query can be given to any code that expects this interface.
+type Sqlizer interface {
+	ToSql() (string, []interface{}, error)
+}
+
 // Query contains the state of a Query that is being built. The only way this
 // should be modified is via the use of the Option first class function.
 type Query struct {
@@ -20,7 +66,14 @@ type Query struct {
 	table   string
 	exprs   []Expr
 	clauses []clause
-	args    []interface{}
+	defers  []Option
+	errs    []error
+	dialect *Dialect
+	quote   bool
+	ignore  bool
+	dedupe  bool
+	tenancy tenancy
+	qualify bool
 }
 
 //go:generate stringer -type statement -linecomment
@@ -32,6 +85,7 @@ const (
 	_Update                // UPDATE
 	_SelectDistinct        // SELECT DISTINCT
 	_SelectDistinctOn      // SELECT DISTINCT ON
+	_Explain               // EXPLAIN
 )
 
 // Delete builds up a DELETE query on the given table applying the given
@@ -42,10 +96,7 @@ func Delete(table string, opts ...Option) Query {
 		table: table,
 	}
 
-	for _, opt := range opts {
-		q = opt(q)
-	}
-	return q
+	return q.apply(opts)
 }
 
 // Insert builds up an INSERT query on the given table using the given leading
@@ -57,10 +108,152 @@ func Insert(table string, expr Expr, opts ...Option) Query {
 		exprs: []Expr{expr},
 	}
 
-	for _, opt := range opts {
-		q = opt(q)
+	return q.apply(opts)
+}
+
+// InsertMap builds up an INSERT query on the given table using the keys of the
+// given map as the columns, and the values of the map as the values to insert.
+// The columns are sorted so that the built query is deterministic.
+func InsertMap(table string, m map[string]interface{}, opts ...Option) Query {
+	cols := make([]string, 0, len(m))
+
+	for col := range m {
+		cols = append(cols, col)
 	}
-	return q
+
+	sort.Strings(cols)
+
+	vals := make([]interface{}, 0, len(cols))
+
+	for _, col := range cols {
+		vals = append(vals, m[col])
+	}
+	return Insert(table, Columns(cols...), append([]Option{Values(vals...)}, opts...)...)
+}
+
+// InsertStruct builds up an INSERT query on the given table using the fields
+// of the given struct that have a db tag. Fields with the readonly option in
+// their db tag are skipped, as are zero value fields with the omitempty
+// option, for example,
+//
+//     type User struct {
+//         ID        int64     `db:"id,readonly"`
+//         Email     string    `db:"email"`
+//         Username  string    `db:"username,omitempty"`
+//         CreatedAt time.Time `db:"created_at,readonly"`
+//     }
+func InsertStruct(table string, v interface{}, opts ...Option) Query {
+	return tags.InsertStruct(table, v, opts...)
+}
+
+// InsertStruct builds up an INSERT query on the given table in the same way as
+// InsertStruct, only the fields are mapped via the Mapper.
+func (m Mapper) InsertStruct(table string, v interface{}, opts ...Option) Query {
+	fields := m.tableFields(table, v)
+
+	cols := make([]string, 0, len(fields))
+	vals := make([]interface{}, 0, len(fields))
+
+	for _, f := range fields {
+		if f.has("readonly") || ((f.pk || f.has("omitempty")) && f.zero()) {
+			continue
+		}
+
+		cols = append(cols, f.col)
+		vals = append(vals, f.val.Interface())
+	}
+	return Insert(table, Columns(cols...), append([]Option{Values(vals...)}, opts...)...)
+}
+
+// BulkInsert builds up one or more INSERT queries on the given table for the
+// given rows of values. Each query will insert as many rows as possible whilst
+// staying under MaxParams. The given options are applied to each query.
+func BulkInsert(table string, cols []string, rows [][]interface{}, opts ...Option) []Query {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	size := len(rows)
+
+	if len(cols) > 0 {
+		if n := MaxParams / len(cols); n < size {
+			size = n
+		}
+	}
+
+	qq := make([]Query, 0, (len(rows)+size-1)/size)
+
+	for len(rows) > 0 {
+		n := size
+
+		if n > len(rows) {
+			n = len(rows)
+		}
+
+		chunk := make([]Option, 0, n+len(opts))
+
+		for _, row := range rows[:n] {
+			chunk = append(chunk, Values(row...))
+		}
+		chunk = append(chunk, opts...)
+
+		qq = append(qq, Insert(table, Columns(cols...), chunk...))
+		rows = rows[n:]
+	}
+	return qq
+}
+
+// BulkInsertStruct builds up one or more INSERT queries on the given table for
+// the given slice of structs in the same way as BulkInsert. The columns are
+// taken from the fields of the structs that have a db tag, and fields with the
+// readonly option are skipped. The omitempty option is ignored, since every
+// row must insert the same columns.
+func BulkInsertStruct(table string, v interface{}, opts ...Option) []Query {
+	return tags.BulkInsertStruct(table, v, opts...)
+}
+
+// BulkInsertStruct builds up one or more INSERT queries on the given table in
+// the same way as BulkInsertStruct, only the fields are mapped via the Mapper.
+func (m Mapper) BulkInsertStruct(table string, v interface{}, opts ...Option) []Query {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		panic("query: expected slice, got " + rv.Kind().String())
+	}
+
+	var cols []string
+
+	// skip is the set of primary key columns that are skipped, this is decided
+	// by the first row, since every row must insert the same columns.
+	skip := make(map[string]struct{})
+
+	rows := make([][]interface{}, 0, rv.Len())
+
+	for i := 0; i < rv.Len(); i++ {
+		fields := m.tableFields(table, rv.Index(i).Interface())
+		row := make([]interface{}, 0, len(fields))
+
+		for _, f := range fields {
+			if f.has("readonly") {
+				continue
+			}
+
+			if i == 0 {
+				if f.pk && f.zero() {
+					skip[f.col] = struct{}{}
+					continue
+				}
+				cols = append(cols, f.col)
+			}
+
+			if _, ok := skip[f.col]; ok {
+				continue
+			}
+			row = append(row, f.val.Interface())
+		}
+		rows = append(rows, row)
+	}
+	return BulkInsert(table, cols, rows, opts...)
 }
 
 // Select will build up a SELECT query using the given leading expression, and
@@ -71,10 +264,7 @@ func Select(expr Expr, opts ...Option) Query {
 		exprs: []Expr{expr},
 	}
 
-	for _, opt := range opts {
-		q = opt(q)
-	}
-	return q
+	return q.apply(opts)
 }
 
 func SelectDistinct(expr Expr, opts ...Option) Query {
@@ -83,10 +273,7 @@ func SelectDistinct(expr Expr, opts ...Option) Query {
 		exprs: []Expr{expr},
 	}
 
-	for _, opt := range opts {
-		q = opt(q)
-	}
-	return q
+	return q.apply(opts)
 }
 
 func SelectDistinctOn(cols []string, expr Expr, opts ...Option) Query {
@@ -101,10 +288,7 @@ func SelectDistinctOn(cols []string, expr Expr, opts ...Option) Query {
 		},
 	}
 
-	for _, opt := range opts {
-		q = opt(q)
-	}
-	return q
+	return q.apply(opts)
 }
 
 // Update will build up an UPDATE query on the given table applying the given
@@ -115,10 +299,36 @@ func Update(table string, opts ...Option) Query {
 		table: table,
 	}
 
-	for _, opt := range opts {
-		q = opt(q)
+	return q.apply(opts)
+}
+
+// UpdateStruct builds up an UPDATE query on the given table, setting the fields
+// of the given struct via SetStruct, and applying the given options.
+func UpdateStruct(table string, v interface{}, opts ...Option) Query {
+	return tags.UpdateStruct(table, v, opts...)
+}
+
+// UpdateStruct builds up an UPDATE query on the given table in the same way as
+// UpdateStruct, only the fields are mapped via the Mapper.
+func (m Mapper) UpdateStruct(table string, v interface{}, opts ...Option) Query {
+	return Update(table, append([]Option{m.SetStruct(v)}, opts...)...)
+}
+
+// SoftDelete builds up an UPDATE query on the given table that sets the
+// deleted_at column to NOW(), applying the given options. This should be used
+// along with NotDeleted to exclude the soft deleted rows from queries. If the
+// table was registered via RegisterTable, then its SoftDelete column is set
+// instead, and if it has none, then ErrSoftDelete is recorded on the Query,
+// see Err.
+func SoftDelete(table string, opts ...Option) Query {
+	col, ok := softDeleteCol(table)
+
+	if !ok {
+		q := Update(table, opts...)
+		q.errs = append(q.errs, fmt.Errorf("query: %w: %s", ErrSoftDelete, table))
+		return q
 	}
-	return q
+	return Update(table, append([]Option{Set(col, Lit("NOW()"))}, opts...)...)
 }
 
 // Union returns a new Query that applies the UNION clause to all fo the given
@@ -127,24 +337,226 @@ func Union(queries ...Query) Query {
 	var q0 Query
 
 	for _, q := range queries {
-		q0.args = append(q0.args, q.args...)
 		q0.clauses = append(q0.clauses, unionClause{
 			q: q,
 		})
 	}
-	return q0
+	return q0.apply(nil)
+}
+
+// apply applies the given options to the Query. The slices of the Query are
+// clipped before and after the options are applied, so appending to them will
+// copy the slices instead of appending to a backing array that could be shared
+// with another Query. This allows for a Query to be used as the base for
+// multiple queries, for example,
+//
+//     base := query.Select(query.Columns("*"), query.From("posts"))
+//
+//     published := query.Where("published", "=", query.Arg(true))(base)
+//     drafts := query.Where("published", "=", query.Arg(false))(base)
+func (q Query) apply(opts []Option) Query {
+	q = q.clip()
+
+	for _, opt := range opts {
+		q = opt(q)
+	}
+	return q.clip()
+}
+
+// clip clips the capacity of the slices in the Query to their length.
+func (q Query) clip() Query {
+	q.exprs = q.exprs[:len(q.exprs):len(q.exprs)]
+	q.clauses = q.clauses[:len(q.clauses):len(q.clauses)]
+	q.defers = q.defers[:len(q.defers):len(q.defers)]
+	q.errs = q.errs[:len(q.errs):len(q.errs)]
+	return q
 }
 
 // Options applies all of the given options to the current query being built.
 func Options(opts ...Option) Option {
 	return func(q Query) Query {
-		for _, opt := range opts {
-			q = opt(q)
+		return q.apply(opts)
+	}
+}
+
+// If applies the given option to the current query being built only if the
+// given condition is true.
+func If(cond bool, opt Option) Option {
+	return func(q Query) Query {
+		if cond {
+			return opt(q)
+		}
+		return q
+	}
+}
+
+// Unless applies the given option to the current query being built only if
+// the given condition is false.
+func Unless(cond bool, opt Option) Option {
+	return If(!cond, opt)
+}
+
+// fromKeywords are the keywords that can follow the table in a FROM clause,
+// these are not taken as the alias of the table.
+var fromKeywords = map[string]struct{}{
+	"JOIN":    {},
+	"LEFT":    {},
+	"RIGHT":   {},
+	"INNER":   {},
+	"OUTER":   {},
+	"FULL":    {},
+	"CROSS":   {},
+	"NATURAL": {},
+	"ON":      {},
+	"USING":   {},
+}
+
+// alias returns the Alias of the table the Query is operating on. This will
+// be taken from the table given to the Query itself, falling back to the first
+// FROM clause in the Query, in the same way as Table. For example, "users u"
+// and "users AS u" would both result in the alias "u". If the table has no
+// alias then the name of the table is returned, and any joins that follow the
+// table are ignored.
+func (q Query) alias() Alias {
+	table := q.table
+
+	if table == "" {
+		for _, cl := range q.clauses {
+			if from, ok := cl.(fromClause); ok {
+				table = from.table
+				break
+			}
+		}
+	}
+
+	parts := strings.Fields(strings.Replace(table, ",", " , ", -1))
+
+	switch {
+	case len(parts) == 0:
+		return ""
+	case len(parts) > 2 && strings.EqualFold(parts[1], "AS"):
+		return Alias(parts[2])
+	case len(parts) > 1 && identPattern.MatchString(parts[1]):
+		if _, ok := fromKeywords[strings.ToUpper(parts[1])]; !ok {
+			return Alias(parts[1])
 		}
+	}
+	return Alias(parts[0])
+}
+
+// deferOpt returns an Option that defers the application of the given option
+// until the Query is built. This is used for options that need to operate on
+// the final state of the Query, regardless of where they were given.
+func deferOpt(opt Option) Option {
+	return func(q Query) Query {
+		q.defers = append(q.defers, opt)
 		return q
 	}
 }
 
+// insertAfter inserts the given clause after the last clause in the Query that
+// is of one of the given kinds. If there is no such clause, then the given
+// clause is inserted at the start.
+func (q Query) insertAfter(cl clause, kinds ...clauseKind) Query {
+	last := -1
+
+	for i, cl := range q.clauses {
+		for _, kind := range kinds {
+			if cl.kind() == kind {
+				last = i
+			}
+		}
+	}
+
+	clauses := make([]clause, 0, len(q.clauses)+1)
+	clauses = append(clauses, q.clauses[:last+1]...)
+	clauses = append(clauses, cl)
+	clauses = append(clauses, q.clauses[last+1:]...)
+
+	q.clauses = clauses
+	return q
+}
+
+// insertBefore inserts the given clause before the first clause in the Query
+// that is of one of the given kinds. If there is no such clause, then the given
+// clause is appended.
+func (q Query) insertBefore(cl clause, kinds ...clauseKind) Query {
+	first := len(q.clauses)
+
+	for i := len(q.clauses) - 1; i >= 0; i-- {
+		for _, kind := range kinds {
+			if q.clauses[i].kind() == kind {
+				first = i
+			}
+		}
+	}
+
+	clauses := make([]clause, 0, len(q.clauses)+1)
+	clauses = append(clauses, q.clauses[:first]...)
+	clauses = append(clauses, cl)
+	clauses = append(clauses, q.clauses[first:]...)
+
+	q.clauses = clauses
+	return q
+}
+
+// ignoreConflicts removes any ON CONFLICT DO NOTHING clauses in the Query so
+// that the Dialect's INSERT modifier for ignoring conflicts, such as OR IGNORE
+// for SQLite, is used instead.
+func (q Query) ignoreConflicts() Query {
+	clauses := make([]clause, 0, len(q.clauses))
+
+	for _, cl := range q.clauses {
+		if v, ok := cl.(conflictClause); ok && len(v.update) == 0 {
+			q.ignore = true
+			continue
+		}
+		clauses = append(clauses, cl)
+	}
+
+	q.clauses = clauses
+	return q
+}
+
+// prepare returns the Query as it will be built for the given Dialect. This
+// applies any deferred options, any global hooks, and any rewriting of the
+// Query that is done for the Dialect. The Dialect to build with is returned
+// too, since this may be changed to quote identifiers.
+func (q Query) prepare(d *Dialect) (Query, *Dialect) {
+	q = applyHooks(q.finalize()).sorted().merged()
+
+	if q.qualify {
+		q = q.qualified()
+	}
+
+	q = q.grouped()
+
+	if q.quote && !d.autoQuote {
+		d = d.Quoted()
+	}
+
+	if d.rewrite != nil {
+		q = d.rewrite(q)
+	}
+
+	if q.stmt == _Insert && d.ignore != "" {
+		q = q.ignoreConflicts()
+	}
+	return q, d
+}
+
+// finalize applies all of the deferred options to the Query.
+func (q Query) finalize() Query {
+	if len(q.defers) == 0 {
+		return q
+	}
+
+	defers := q.defers
+	q.defers = nil
+
+	return q.apply(defers)
+}
+
 // conj returns the string that should be used for conjoining multiple clauses
 // of the same type.
 func (q Query) conj(cl clause) string {
@@ -157,49 +569,119 @@ func (q Query) conj(cl clause) string {
 		return " " + v.conjunction + " "
 	case unionClause:
 		return " " + cl.kind().String() + " "
-	case setClause, valuesClause:
+	case setClause, valuesClause, returningClause, fromClause, fromQueryClause:
 		return ", "
-	case orderClause:
+	case orderClause, groupClause:
 		return ", "
+	case customClause:
+		if cl.kind().keyword() {
+			return ", "
+		}
+		return " "
 	default:
 		return " "
 	}
 }
 
+// estimate returns an estimate of the length of the Query once it has been
+// built, based off the expressions and clauses in the Query.
+func (q Query) estimate() int {
+	n := len(q.table) + 16
+
+	for _, expr := range q.exprs {
+		n += estimate(expr) + 2
+	}
+
+	for _, cl := range q.clauses {
+		n += estimate(cl) + 2
+	}
+	return n
+}
+
 // buildInitial builds up the initial query using ? as the placeholder. This
 // will correctly wrap the portions of the query in parenthese depending on the
 // clauses in the query, and how these clauses are conjoined.
 func (q Query) buildInitial() string {
-	var buf strings.Builder
+	s, _, _ := q.write(dialectOr(q.dialect))
+	return s
+}
+
+// write writes the Query for the given Dialect, and returns the initial query
+// using ? as the placeholder along with its arguments. Both are written in a
+// single pass over the Query, so the arguments will always be in the same
+// order as their placeholders. The query is written in full even if an error
+// is returned, such as ErrTenant for the Query or one of its subqueries.
+func (q Query) write(d *Dialect) (string, []interface{}, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	w := SQLWriter{
+		buf:  *buf,
+		args: make([]interface{}, 0, len(q.clauses)),
+		d:    d,
+	}
+
+	q.WriteSQL(&w)
+
+	*buf = w.buf
+	return w.String(), w.args, w.err
+}
 
-	buf.WriteString(q.stmt.String())
+// WriteSQL writes the Query into the given SQLWriter, along with its
+// arguments. The Query is prepared for the Dialect of the SQLWriter, so a
+// Query used as a subquery is written for the same Dialect as the Query it is
+// in. This implements the SQLExpr interface.
+func (q Query) WriteSQL(w *SQLWriter) {
+	q, d := q.prepare(w.d)
+
+	if err := q.tenantErr(); err != nil && w.err == nil {
+		w.err = err
+	}
+
+	outer := w.d
+	w.d = d
+
+	w.buf.grow(q.estimate())
+	w.buf.WriteString(q.stmt.String())
 
 	switch q.stmt {
 	case _Insert:
-		buf.WriteString(" INTO " + q.table)
+		if q.ignore {
+			w.buf.WriteString(" " + d.ignore)
+		}
+		w.buf.WriteString(" INTO " + d.ident(q.table))
 	case _Update:
-		buf.WriteString(" " + q.table + " ")
+		w.buf.WriteString(" " + d.ident(q.table) + " ")
 	case _Delete:
-		buf.WriteString(" FROM " + q.table + " ")
+		w.buf.WriteString(" FROM " + d.ident(q.table) + " ")
 	}
 
 	for i, expr := range q.exprs {
-		buf.WriteByte(' ')
+		// A Query without a statement, such as one from Append, has nothing
+		// before its first expression.
+		if i > 0 || q.stmt != _Stmt {
+			w.buf.WriteByte(' ')
+		}
 
 		if q.stmt == _Insert {
-			buf.WriteByte('(')
+			w.buf.WriteByte('(')
 		}
 
-		buf.WriteString(expr.Build())
+		w.WriteExpr(expr)
 
 		if q.stmt == _Insert {
-			buf.WriteByte(')')
+			w.buf.WriteByte(')')
 		}
 
 		if q.stmt == _SelectDistinctOn && i == 0 {
 			continue
 		}
-		buf.WriteByte(' ')
+
+		// Nothing follows the last expression if there are no clauses.
+		if i == len(q.exprs)-1 && len(q.clauses) == 0 {
+			continue
+		}
+		w.buf.WriteByte(' ')
 	}
 
 	clauses := make(map[clauseKind]struct{})
@@ -221,21 +703,21 @@ func (q Query) buildInitial() string {
 
 		kind := cl.kind()
 
-		if kind != _UnionClause {
+		if kind.keyword() {
 			// Write the string of the clause kind only once, this avoids something
 			// like multiple WHERE clauses being built into the query.
 			if _, ok := clauses[kind]; !ok {
 				clauses[kind] = struct{}{}
 
-				buf.WriteString(kind.String() + " ")
+				w.buf.WriteString(kind.name() + " ")
 
 				if kind == _WhereClause {
-					buf.WriteByte('(')
+					w.buf.WriteByte('(')
 				}
 			}
 		}
 
-		buf.WriteString(cl.Build())
+		w.WriteExpr(cl)
 
 		if next != nil {
 			conj := q.conj(next)
@@ -253,50 +735,289 @@ func (q Query) buildInitial() string {
 				}
 
 				if wrap {
-					buf.WriteByte(')')
+					w.buf.WriteByte(')')
 				}
 
-				buf.WriteString(conj)
+				w.buf.WriteString(conj)
 
 				if wrap {
-					buf.WriteByte('(')
+					w.buf.WriteByte('(')
 				}
 			} else {
 				if kind == _WhereClause {
-					buf.WriteByte(')')
+					w.buf.WriteByte(')')
 				}
-				buf.WriteByte(' ')
+				w.buf.WriteByte(' ')
 			}
 		}
 
 		if i == end && kind == _WhereClause {
-			buf.WriteByte(')')
+			w.buf.WriteByte(')')
 		}
 	}
-	return buf.String()
+
+	w.d = outer
 }
 
 // Args returns a slice of all the arguments that have been added to the given
 // query.
-func (q Query) Args() []interface{} { return q.args }
+func (q Query) Args() []interface{} {
+	d := dialectOr(q.dialect)
+	return q.ArgsWith(d.placeholder)
+}
+
+// ArgsWith returns the arguments of the Query in the same way as Args, only
+// for a query built via BuildWith with the given placeholder style. This
+// matters for a Query that has Dedupe set, as repeated arguments are only
+// removed for the placeholder styles that number their parameters.
+func (q Query) ArgsWith(p Placeholder) []interface{} {
+	d := dialectOr(q.dialect)
+
+	_, args, _ := q.write(d)
+	return q.finalArgs(d, p, args)
+}
+
+// finalArgs returns the given arguments written for the Query as they would be
+// returned from Args, with any named arguments unwrapped, and any repeated
+// arguments removed if the Query has Dedupe set and is built with the given
+// placeholder style.
+func (q Query) finalArgs(d *Dialect, p Placeholder, args []interface{}) []interface{} {
+	args = unnamed(args)
+
+	if q.dedupe && p.numbered() {
+		args, _ = dedupe(args)
+	}
+	return d.args(args)
+}
+
+// Dedupe enables the deduplication of arguments in the Query. When built, each
+// repeated argument will use the same placeholder, and will only appear once in
+// the arguments returned from Args, for example,
+//
+//     WHERE (user_id = $1 OR author_id = $1)
+//
+// This only applies to placeholder styles that number their parameters, such
+// as Dollar, so a Query built with the Question placeholder style will not be
+// deduplicated.
+func Dedupe() Option {
+	return func(q Query) Query {
+		q.dedupe = true
+		return q
+	}
+}
+
+// NamedArgs returns a slice of all the arguments that have been added to the
+// given query, each as a sql.NamedArg. This should be used along with a query
+// built with either the AtName or ColonName placeholder styles. Arguments that
+// were not given via Named are named pn, where n is the position of the
+// argument. Each name only appears once.
+func (q Query) NamedArgs() []interface{} {
+	d := dialectOr(q.dialect)
+
+	_, args, _ := q.write(d)
+
+	named := make([]interface{}, 0, len(args))
+	seen := make(map[string]struct{})
+
+	vals := d.args(unnamed(args))
+
+	for i, arg := range args {
+		name := "p" + strconv.Itoa(i+1)
+
+		if v, ok := arg.(sql.NamedArg); ok {
+			name = v.Name
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		named = append(named, sql.Named(name, vals[i]))
+	}
+	return named
+}
 
 // Build builds up the query. It will initially create a query using ? as the
-// placeholder for arguments. Once built up it will replace the ? with $n where
-// n is the number of the argument.
+// placeholder for arguments. Once built up it will replace the ? with the
+// placeholder style of the Query's Dialect, for PostgreSQL this would be $n
+// where n is the number of the argument. If the Query cannot be built, such as
+// a query on a table with a Tenant column that was not given ForTenant, then an
+// empty string is returned, and the error can be checked via BuildErr.
 func (q Query) Build() string {
-	s := q.buildInitial()
+	d := dialectOr(q.dialect)
+
+	s, _ := q.render(d, d.placeholder, 1)
+	return s
+}
+
+// render builds up the query for the given Dialect, replacing the ? with the
+// given placeholder style numbered from start. The number of the next
+// placeholder is returned.
+func (q Query) render(d *Dialect, p Placeholder, start int64) (string, int64) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b, next := q.appendRender(*buf, d, p, start)
+	*buf = b
+	return string(b), next
+}
+
+// appendRender renders the query in the same way as render, only the query is
+// appended to the given buffer. Nothing is appended if the query cannot be
+// built.
+func (q Query) appendRender(buf []byte, d *Dialect, p Placeholder, start int64) ([]byte, int64) {
+	n := len(buf)
+
+	buf, next, _, err := q.appendWrite(buf, d, p, start)
+
+	if err != nil {
+		return buf[:n], start
+	}
+	return buf, next
+}
+
+// appendWrite renders the query in the same way as appendRender, and returns
+// the arguments that were written along with it. If Strict is set, then the
+// error from Err is returned, along with any error from writing the query. The
+// query is rendered in full even if an error is returned, it is up to the
+// caller whether it should be used.
+func (q Query) appendWrite(buf []byte, d *Dialect, p Placeholder, start int64) ([]byte, int64, []interface{}, error) {
+	var err error
+
+	if Strict {
+		err = q.Err()
+	}
+
+	s, args, werr := q.write(d)
+
+	if err == nil {
+		err = werr
+	}
+
+	b := buffer(buf)
+	b.grow(rebindLen(s))
+	buf = b
+
+	switch {
+	case p == Question:
+		return append(buf, s...), start + int64(countParams(s)), args, err
+	case p.named():
+		buf, _ = appendRebind(buf, s, p, 1, nil, args)
+		return buf, start, args, err
+	case q.dedupe && p.numbered():
+		_, index := dedupe(unnamed(args))
+
+		buf, next := appendRebind(buf, s, p, start, index, nil)
+		return buf, next, args, err
+	}
+
+	buf, next := appendRebind(buf, s, p, start, nil, nil)
+	return buf, next, args, err
+}
+
+// compile builds up the query and returns it along with its arguments, in the
+// same way as Build and Args, only the Query is written once for both. This is
+// used when the Query is executed. If the Query cannot be built then the error
+// is returned instead.
+func (q Query) compile() (string, []interface{}, error) {
+	d := dialectOr(q.dialect)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b, _, args, err := q.appendWrite(*buf, d, d.placeholder, 1)
+	*buf = b
+
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b), q.finalArgs(d, d.placeholder, args), nil
+}
+
+// AppendBuild builds up the query in the same way as Build, and appends it to
+// the given buffer. This allows for a buffer to be reused across multiple
+// queries, for example,
+//
+//     buf = q.AppendBuild(buf[:0])
+func (q Query) AppendBuild(buf []byte) []byte {
+	d := dialectOr(q.dialect)
+
+	buf, _ = q.appendRender(buf, d, d.placeholder, 1)
+	return buf
+}
+
+// BuildTo builds up the query in the same way as Build, and writes it to the
+// given io.Writer. Any error from building or writing the query is returned.
+func (q Query) BuildTo(w io.Writer) error {
+	d := dialectOr(q.dialect)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b, _, _, err := q.appendWrite(*buf, d, d.placeholder, 1)
+	*buf = b
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
 
-	query := make([]byte, 0, len(s))
-	param := int64(0)
+// BuildErr builds up the query in the same way as Build, only the Query is
+// checked via Validate first.
+func (q Query) BuildErr() (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
 
-	for i := strings.Index(s, "?"); i != -1; i = strings.Index(s, "?") {
-		param++
+	d := dialectOr(q.dialect)
 
-		query = append(query, s[:i]...)
-		query = append(query, '$')
-		query = strconv.AppendInt(query, param, 10)
+	s, _ := q.render(d, d.placeholder, 1)
+	return s, nil
+}
 
-		s = s[i+1:]
+// ToSql builds up the query in the same way as BuildErr, and returns it along
+// with its arguments. This implements the Sqlizer interface.
+func (q Query) ToSql() (string, []interface{}, error) {
+	if err := q.Validate(); err != nil {
+		return "", nil, err
 	}
-	return string(append(query, []byte(s)...))
+
+	return q.compile()
+}
+
+var _ Sqlizer = (*Query)(nil)
+
+// BuildN builds up the query in the same way as Build, only the placeholders
+// are numbered from the given start. The number of the next placeholder is
+// returned, this allows for a built query to be appended to an existing
+// statement, for example,
+//
+//     q1, n := cte.BuildN(1)
+//     q2, _ := q.BuildN(n)
+//
+//     rows, err := db.Query(q1+" "+q2, append(cte.Args(), q.Args()...)...)
+func (q Query) BuildN(start int) (string, int) {
+	d := dialectOr(q.dialect)
+
+	s, next := q.render(d, d.placeholder, int64(start))
+	return s, int(next)
+}
+
+// BuildWith builds up the query in the same way as Build, only the ? will be
+// replaced with the given placeholder style, for example,
+//
+//     q.BuildWith(query.Question)
+//
+// would leave the ? placeholders as they are for use with MySQL or SQLite. The
+// arguments for the query should be taken from ArgsWith, given the same
+// placeholder style.
+func (q Query) BuildWith(p Placeholder) string {
+	s, _ := q.render(dialectOr(q.dialect), p, 1)
+	return s
 }
+
+var _ SQLExpr = (*Query)(nil)
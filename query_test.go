@@ -1,6 +1,20 @@
 package query
 
-import "testing"
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/query/internal/mockdb"
+)
 
 func Test_Query(t *testing.T) {
 	tests := []struct {
@@ -228,6 +242,300 @@ func Test_Query(t *testing.T) {
 				OrderDesc("namespace_id", "created_at"),
 			),
 		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1)",
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("user_id", "=", Arg(1)),
+				If(false, Where("title", "LIKE", Arg("%foo%"))),
+				Unless(true, OrderDesc("created_at")),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND title LIKE $2) ORDER BY created_at DESC",
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("user_id", "=", Arg(1)),
+				If(true, Where("title", "LIKE", Arg("%foo%"))),
+				Unless(false, OrderDesc("created_at")),
+			),
+		},
+		{
+			"SELECT * FROM issues WHERE (deleted_at IS NULL AND label_id IN ($1, $2) AND status = $3 AND user_id = $4)",
+			Select(
+				Columns("*"),
+				From("issues"),
+				WhereMap(map[string]interface{}{
+					"status":     "open",
+					"user_id":    3,
+					"label_id":   []int64{1, 2},
+					"deleted_at": nil,
+				}),
+			),
+		},
+		{
+			"SELECT * FROM issues WHERE (status = $1 AND title LIKE $2 AND label_id IN ($3, $4))",
+			Select(
+				Columns("*"),
+				From("issues"),
+				WhereStruct(struct {
+					Status  string  `db:"status"`
+					Title   string  `db:"title" query:"LIKE"`
+					UserID  int64   `db:"user_id"`
+					Labels  []int64 `db:"label_id"`
+					Ignored string
+				}{
+					Status:  "open",
+					Title:   "%foo%",
+					Labels:  []int64{1, 2},
+					Ignored: "ignored",
+				}),
+			),
+		},
+		{
+			"SELECT * FROM users WHERE (id IN ($1, $2, $3) OR email IN ($4))",
+			Select(
+				Columns("*"),
+				From("users"),
+				WhereIn("id", []int64{1, 2, 3}),
+				OrWhereIn("email", "me@example.com"),
+			),
+		},
+		{
+			"SELECT * FROM users WHERE (1 = 0)",
+			Select(
+				Columns("*"),
+				From("users"),
+				WhereIn("id", []int64{}),
+			),
+		},
+		{
+			"SELECT * FROM users WHERE (id = $1 OR 1 = 0)",
+			Select(
+				Columns("*"),
+				From("users"),
+				Where("id", "=", Arg(1)),
+				OrWhereIn("email", []string{}),
+			),
+		},
+		{
+			"SELECT * FROM users WHERE (1 = 1 AND id = $1)",
+			Select(
+				Columns("*"),
+				From("users"),
+				Where("id", "NOT IN", List()),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"SELECT * FROM users u WHERE (EXISTS (SELECT 1 FROM posts WHERE (posts.user_id = u.id AND title LIKE $1)) AND NOT EXISTS (SELECT 1 FROM bans WHERE (bans.user_id = u.id)))",
+			Select(
+				Columns("*"),
+				From("users u"),
+				WhereExists(func(u Alias) Query {
+					return Select(
+						Columns("1"),
+						From("posts"),
+						Where("posts.user_id", "=", u.Ident("id")),
+						Where("title", "LIKE", Arg("%foo%")),
+					)
+				}),
+				WhereNotExists(func(u Alias) Query {
+					return Select(
+						Columns("1"),
+						From("bans"),
+						Where("bans.user_id", "=", u.Ident("id")),
+					)
+				}),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND (created_at, id) < ($2, $3)) ORDER BY created_at DESC, id DESC LIMIT 25",
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("user_id", "=", Arg(1)),
+				Seek([]OrderedColumn{Desc("created_at"), Desc("id")}, "2006-01-02", 10),
+				Limit(25),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE ((title > $1 OR (title = $2 AND id < $3))) ORDER BY title ASC, id DESC",
+			Select(
+				Columns("*"),
+				From("posts"),
+				Seek([]OrderedColumn{Asc("title"), Desc("id")}, "foo", 10),
+			),
+		},
+		{
+			"SELECT * FROM posts ORDER BY id ASC",
+			Select(Columns("*"), From("posts"), Seek([]OrderedColumn{Asc("id")})),
+		},
+		{
+			"INSERT INTO users (email, password, username) VALUES ($1, $2, $3) RETURNING id",
+			InsertMap(
+				"users",
+				map[string]interface{}{
+					"username": "me",
+					"email":    "me@example.com",
+					"password": "secret",
+				},
+				Returning("id"),
+			),
+		},
+		{
+			"INSERT INTO users (email, username) VALUES ($1, $2) RETURNING id, created_at",
+			InsertStruct(
+				"users",
+				&struct {
+					ID        int64  `db:"id,readonly"`
+					Email     string `db:"email"`
+					Username  string `db:"username"`
+					Bio       string `db:"bio,omitempty"`
+					CreatedAt string `db:"created_at,readonly"`
+				}{
+					Email:    "me@example.com",
+					Username: "me",
+				},
+				Returning("id", "created_at"),
+			),
+		},
+		{
+			"UPDATE users SET email = $1, updated_at = NOW(), username = $2 WHERE (id = $3)",
+			Update(
+				"users",
+				SetMap(map[string]interface{}{
+					"username":   "me",
+					"email":      "me@example.com",
+					"updated_at": Lit("NOW()"),
+				}),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"UPDATE users SET email = $1 WHERE (id = $2)",
+			UpdateStruct(
+				"users",
+				struct {
+					ID       int64  `db:"id,readonly"`
+					Email    string `db:"email"`
+					Username string `db:"username,omitempty"`
+				}{
+					ID:    1,
+					Email: "me@example.com",
+				},
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"UPDATE users SET username = $1 WHERE (id = $2)",
+			Update(
+				"users",
+				SetStruct(
+					struct {
+						Email    string `db:"email"`
+						Username string `db:"username"`
+					}{
+						Email:    "me@example.com",
+						Username: "me",
+					},
+					"username",
+				),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"UPDATE posts SET count = count + $1, score = (SELECT SUM(score) FROM votes WHERE (post_id = $2)) WHERE (id = $3)",
+			Update(
+				"posts",
+				SetExpr("count", Raw("count + ?", 1)),
+				SetExpr("score", Select(Sum("score"), From("votes"), Where("post_id", "=", Arg(1)))),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"UPDATE products SET views = views + $1, stock = stock - $2 WHERE (id = $3)",
+			Update(
+				"products",
+				Incr("views", 1),
+				Decr("stock", 2),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"INSERT INTO posts (title, created_at) VALUES ($1, NOW()), ($2, NOW())",
+			Insert(
+				"posts",
+				Columns("title"),
+				Timestamps{Tables: []string{"posts"}}.Option(),
+				Values("post 1"),
+				Values("post 2"),
+			),
+		},
+		{
+			"UPDATE posts SET title = $1, updated_at = NOW() WHERE (id = $2)",
+			Update(
+				"posts",
+				Timestamps{}.Option(),
+				Set("title", Arg("post 1")),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"UPDATE tags SET name = $1 WHERE (id = $2)",
+			Update(
+				"tags",
+				Timestamps{Tables: []string{"posts"}}.Option(),
+				Set("name", Arg("tag")),
+				Where("id", "=", Arg(1)),
+			),
+		},
+		{
+			"UPDATE posts SET deleted_at = NOW() WHERE (id = $1)",
+			SoftDelete("posts", Where("id", "=", Arg(1))),
+		},
+		{
+			"SELECT * FROM posts WHERE ((user_id = $1 OR author_id = $2) AND deleted_at IS NULL) ORDER BY created_at DESC",
+			Select(
+				Columns("*"),
+				From("posts"),
+				NotDeleted(),
+				Where("user_id", "=", Arg(1)),
+				OrWhere("author_id", "=", Arg(1)),
+				OrderDesc("created_at"),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (((user_id = $1 AND draft = $2) OR (author_id = $3)) AND deleted_at IS NULL)",
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("user_id", "=", Arg(1)),
+				Where("draft", "=", Arg(false)),
+				OrWhere("author_id", "=", Arg(1)),
+				NotDeleted(),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (deleted_at IS NULL) LIMIT 10",
+			Select(Columns("*"), From("posts"), Limit(10), NotDeleted()),
+		},
+		{
+			"UPDATE users SET email = $1 WHERE (id = $2) RETURNING id, lower(email) AS email",
+			Update(
+				"users",
+				Set("email", Arg("Me@Example.com")),
+				Where("id", "=", Arg(1)),
+				Returning("id"),
+				ReturningExpr(Raw("lower(email) AS email")),
+			),
+		},
+		{
+			"DELETE FROM users WHERE (id = $1) RETURNING *",
+			Delete("users", Where("id", "=", Arg(1)), ReturningAll()),
+		},
 	}
 
 	for i, test := range tests {
@@ -238,3 +546,3600 @@ func Test_Query(t *testing.T) {
 		}
 	}
 }
+
+func Test_BulkInsert(t *testing.T) {
+	rows := make([][]interface{}, 0, 70000)
+
+	for i := 0; i < cap(rows); i++ {
+		rows = append(rows, []interface{}{i, "note"})
+	}
+
+	qq := BulkInsert("notes", []string{"id", "title"}, rows, Returning("id"))
+
+	if len(qq) != 3 {
+		t.Fatalf("expected 3 queries, got %d\n", len(qq))
+	}
+
+	total := 0
+
+	for i, q := range qq {
+		n := len(q.Args())
+
+		if n > MaxParams {
+			t.Errorf("qq[%d]: too many params %d\n", i, n)
+		}
+		total += n
+	}
+
+	if total != len(rows)*2 {
+		t.Errorf("expected %d args, got %d\n", len(rows)*2, total)
+	}
+
+	type note struct {
+		ID    int64  `db:"id,readonly"`
+		Title string `db:"title"`
+		Body  string `db:"body,omitempty"`
+	}
+
+	expected := "INSERT INTO notes (title, body) VALUES ($1, $2), ($3, $4)"
+
+	qq = BulkInsertStruct("notes", []note{{Title: "note 1"}, {Title: "note 2", Body: "body"}})
+
+	if len(qq) != 1 {
+		t.Fatalf("expected 1 query, got %d\n", len(qq))
+	}
+
+	if built := qq[0].Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+}
+
+func Test_BuildWith(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("users"),
+		Where("email", "=", Arg("me@example.com")),
+		OrWhere("username", "=", Arg("me")),
+	)
+
+	tests := []struct {
+		p        Placeholder
+		expected string
+	}{
+		{Dollar, "SELECT * FROM users WHERE (email = $1 OR username = $2)"},
+		{Question, "SELECT * FROM users WHERE (email = ? OR username = ?)"},
+		{Colon, "SELECT * FROM users WHERE (email = :1 OR username = :2)"},
+		{AtP, "SELECT * FROM users WHERE (email = @p1 OR username = @p2)"},
+	}
+
+	for i, test := range tests {
+		if built := q.BuildWith(test.p); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+}
+
+func Test_Dialect(t *testing.T) {
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (user_id = ? AND id IN (SELECT post_id FROM tags WHERE (name = ?))) LIMIT 9223372036854775807 OFFSET 10",
+			Select(
+				Columns("*"),
+				From("posts"),
+				WithDialect(MySQL),
+				Where("user_id", "=", Arg(1)),
+				Where("id", "IN", Select(Columns("post_id"), From("tags"), Where("name", "=", Arg("foo")))),
+				Offset(10),
+			),
+		},
+		{
+			"INSERT INTO users (email) VALUES (?)",
+			Insert("users", Columns("email"), Values("me@example.com"), Returning("id"), WithDialect(MySQL)),
+		},
+		{
+			"SELECT * FROM posts ORDER BY id ASC LIMIT -1 OFFSET 10",
+			Select(Columns("*"), From("posts"), OrderAsc("id"), Offset(10), WithDialect(SQLite)),
+		},
+		{
+			"SELECT * FROM posts LIMIT 5 OFFSET 10",
+			Select(Columns("*"), From("posts"), Limit(5), Offset(10), WithDialect(SQLite)),
+		},
+		{
+			"DELETE FROM posts WHERE (id IN (SELECT id FROM posts LIMIT -1 OFFSET 10))",
+			Delete("posts", Where("id", "IN", Select(Columns("id"), From("posts"), Offset(10))), Returning("id"), WithDialect(SQLiteVersion("3.31.1"))),
+		},
+		{
+			"INSERT INTO users (email) VALUES ($1) ON CONFLICT (email) DO NOTHING RETURNING id",
+			Insert("users", Columns("email"), Values("me@example.com"), OnConflictDoNothing("email"), Returning("id")),
+		},
+		{
+			"INSERT INTO users (email, name) VALUES ($1, $2) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+			Insert("users", Columns("email", "name"), Values("me@example.com", "me"), OnConflictUpdate([]string{"email"}, "name")),
+		},
+		{
+			"INSERT IGNORE INTO users (email) VALUES (?)",
+			Insert("users", Columns("email"), Values("me@example.com"), OnConflictDoNothing("email"), WithDialect(MySQL)),
+		},
+		{
+			"INSERT INTO users (email, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)",
+			Insert("users", Columns("email", "name"), Values("me@example.com", "me"), OnConflictUpdate([]string{"email"}, "name"), WithDialect(MySQL)),
+		},
+		{
+			"INSERT OR IGNORE INTO users (email) VALUES (?) RETURNING id",
+			Insert("users", Columns("email"), Values("me@example.com"), OnConflictDoNothing(), Returning("id"), WithDialect(SQLite)),
+		},
+		{
+			"INSERT INTO users (email, name) VALUES (?, ?) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+			Insert("users", Columns("email", "name"), Values("me@example.com", "me"), OnConflictUpdate([]string{"email"}, "name"), Returning("id"), WithDialect(SQLiteVersion("3.31.1"))),
+		},
+		{
+			"SELECT * FROM [posts] WHERE (user_id = @p1) ORDER BY created_at DESC OFFSET 10 ROWS FETCH NEXT 25 ROWS ONLY",
+			Select(
+				Columns("*"),
+				From(SQLServer.Quote("posts")),
+				Where("user_id", "=", Arg(1)),
+				OrderDesc("created_at"),
+				Limit(25),
+				Offset(10),
+				WithDialect(SQLServer),
+			),
+		},
+		{
+			"SELECT * FROM posts ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 25 ROWS ONLY",
+			Select(Columns("*"), From("posts"), Limit(25), WithDialect(SQLServer)),
+		},
+		{
+			"SELECT * FROM posts WHERE (published = :1 AND pinned = 0) ORDER BY created_at DESC FETCH FIRST 25 ROWS ONLY",
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("published", "=", Arg(true)),
+				Where("pinned", "=", Lit(false)),
+				OrderDesc("created_at"),
+				Limit(25),
+				WithDialect(Oracle),
+			),
+		},
+		{
+			"SELECT * FROM posts OFFSET 50 ROWS FETCH FIRST 25 ROWS ONLY",
+			Select(Columns("*"), From("posts"), Limit(25), Offset(50), WithDialect(Oracle)),
+		},
+		{
+			`SELECT "u".*, COUNT(*) FROM "public"."users" AS "u" WHERE ("u"."order" = $1 AND "Name" = $2) ORDER BY "u"."user" DESC`,
+			Select(
+				Columns("u.*", "COUNT(*)"),
+				From("public.users AS u"),
+				Where("u.order", "=", Arg(1)),
+				Where("Name", "=", Arg("me")),
+				OrderDesc("u.user"),
+				QuoteIdents(),
+			),
+		},
+		{
+			"UPDATE `users` SET `order` = ?, `count` = count + ? WHERE (`id` = ?)",
+			Update(
+				"users",
+				Set("order", Arg(1)),
+				Incr("count", 1),
+				Where("id", "=", Arg(1)),
+				WithDialect(MySQL.Quoted()),
+			),
+		},
+		{
+			`SELECT * FROM users WHERE ("order" = $1)`,
+			Select(Columns("*"), From("users"), WhereExpr(Raw(Quote("order").Build()+" = ?", 1))),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+
+	q := Select(Columns("*"), From("posts"), Where("published", "=", Arg(true)), WithDialect(Oracle))
+
+	if args := q.Args(); len(args) != 1 || args[0] != 1 {
+		t.Errorf("expected boolean argument to be 1, got %v\n", args)
+	}
+
+	if !SQLiteVersion("3.45.1").Returning() || SQLiteVersion("3.34.0").Returning() {
+		t.Errorf("unexpected RETURNING support for SQLite version\n")
+	}
+
+	quotes := []struct {
+		d        *Dialect
+		ident    string
+		expected string
+	}{
+		{Postgres, `public.Users`, `"public"."Users"`},
+		{Postgres, `u.*`, `"u".*`},
+		{Postgres, `we"ird`, `"we""ird"`},
+		{MySQL, "order", "`order`"},
+		{MySQL, "we`ird", "`we``ird`"},
+	}
+
+	for i, test := range quotes {
+		if quoted := test.d.Quote(test.ident); quoted != test.expected {
+			t.Errorf("quotes[%d]: expected %s, got %s\n", i, test.expected, quoted)
+		}
+	}
+}
+
+func Test_BuildErr(t *testing.T) {
+	tests := []struct {
+		q   Query
+		err error
+	}{
+		{Select(Columns("*"), From("users"), Where("id", "=", Arg(1))), nil},
+		{Select(Columns("*"), From("users"), Where("id", "is not", Lit("NULL"))), nil},
+		{Select(Columns("*"), From("users"), Where("id", "=1; DROP TABLE users; --", Arg(1))), ErrOperator},
+		{Select(Columns("*"), From("users"), Where("data", "?", Arg("k"))), ErrOperator},
+		{Select(Columns("*"), From("users"), Where("data", "?|", Arg([]string{"k"}))), ErrOperator},
+		{Select(Columns("*"), From("users"), Where("data", "?&", Arg([]string{"k"}))), ErrOperator},
+		{
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("user_id", "IN", Select(Columns("id"), From("users"), Where("name", "LIKE;", Arg("me")))),
+			),
+			ErrOperator,
+		},
+		{Delete("users", Limit(1)), ErrClause},
+		{Delete("users", GroupBy("id")), ErrClause},
+		{Select(Columns("*"), From("users"), Returning("id")), ErrClause},
+		{Insert("users", Columns("email"), Values("me@example.com"), Returning("id"), WithDialect(MySQL)), nil},
+		{Update("users", Where("id", "=", Arg(1))), ErrClause},
+		{Insert("users", Columns("email")), ErrClause},
+		{Delete("", Where("id", "=", Arg(1))), ErrTable},
+		{Select(Columns("*"), From("users"), WhereIn("id", make([]int, MaxParams+1))), ErrTooManyParams},
+		{Select(Columns("*"), From("users"), WhereIn("id", make([]int, 2101)), WithDialect(SQLServer)), ErrTooManyParams},
+		{Select(Columns("*"), From("users"), WhereIn("id", make([]int, 2100)), WithDialect(SQLServer)), nil},
+		{Select(Columns("*"), Where("id", "=", Arg(1))), ErrFrom},
+		{SelectDistinctOn([]string{"user_id"}, Columns("*")), ErrFrom},
+		{Select(Count("*"), From("users")), nil},
+		{Select(Lit("NOW()")), nil},
+		{Select(Columns("NOW()")), nil},
+		{Select(Columns("1")), nil},
+		{Select(Columns("CURRENT_TIMESTAMP AS now", "'ok' AS status")), nil},
+		{Select(Columns("1", "u.id")), ErrFrom},
+		{Insert("users", Columns(), Values()), ErrColumns},
+		{Insert("users", Columns("email", "name"), Values("me@example.com")), ErrColumns},
+		{Insert("users", Columns("email"), Values("me@example.com"), Values("you@example.com", "you")), ErrColumns},
+		{Insert("users", Columns("email"), Values("me@example.com"), Timestamps{}.Option()), nil},
+		{Select(Columns("*"), From("users"), Seek([]OrderedColumn{Desc("created_at"), Desc("id")}, 10)), ErrColumns},
+		{Select(Columns("*"), From("users"), Set("email", Arg("me@example.com"))), ErrClause},
+		{Delete("users", Set("email", Arg("me@example.com"))), ErrClause},
+		{Select(Columns("*"), From("users"), Where("id", "IN", Select(Columns("id"), Set("a", Arg(1))))), ErrClause},
+	}
+
+	for i, test := range tests {
+		if err := test.q.Validate(); !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected Validate error %v, got %v\n", i, test.err, err)
+		}
+
+		_, err := test.q.BuildErr()
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %v, got %v\n", i, test.err, err)
+		}
+	}
+}
+
+func Test_BuildN(t *testing.T) {
+	q := Select(Columns("*"), From("t"), Where("a", "=", Arg(1)), Where("b", "=", Arg(2)))
+
+	expected := "SELECT * FROM t WHERE (a = $4 AND b = $5)"
+
+	built, next := q.BuildN(4)
+
+	if built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	if next != 6 {
+		t.Errorf("expected next placeholder 6, got %d\n", next)
+	}
+}
+
+func Test_Rebind(t *testing.T) {
+	sql := "SELECT * FROM users WHERE (id = ? AND email = ?)"
+	expected := "SELECT * FROM users WHERE (id = @p1 AND email = @p2)"
+
+	if rebound := Rebind(sql, AtP); rebound != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, rebound)
+	}
+
+	sql = `SELECT "a?" FROM users WHERE (note = 'why?' AND id = ?)`
+	expected = `SELECT "a?" FROM users WHERE (note = 'why?' AND id = $1)`
+
+	if rebound := Rebind(sql, Dollar); rebound != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, rebound)
+	}
+
+	q := Update("posts", Set("note", Lit("'?'")), Where("id", "=", Arg(1)))
+
+	if built, n := q.BuildN(1); built != "UPDATE posts SET note = '?' WHERE (id = $1)" || n != 2 {
+		t.Errorf("unexpected query %q with next placeholder %d\n", built, n)
+	}
+}
+
+func Test_Named(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Named("user_id", 10)),
+		Where("title", "LIKE", Arg("%foo%")),
+		OrWhere("author_id", "=", Named("user_id", 10)),
+	)
+
+	tests := []struct {
+		p        Placeholder
+		expected string
+	}{
+		{Dollar, "SELECT * FROM posts WHERE (user_id = $1 AND title LIKE $2) OR (author_id = $3)"},
+		{AtName, "SELECT * FROM posts WHERE (user_id = @user_id AND title LIKE @p2) OR (author_id = @user_id)"},
+		{ColonName, "SELECT * FROM posts WHERE (user_id = :user_id AND title LIKE :p2) OR (author_id = :user_id)"},
+	}
+
+	for i, test := range tests {
+		if built := q.BuildWith(test.p); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+
+	args := q.Args()
+
+	if len(args) != 3 || args[0] != 10 {
+		t.Errorf("expected unnamed args, got %v\n", args)
+	}
+
+	named := q.NamedArgs()
+
+	expected := []interface{}{sql.Named("user_id", 10), sql.Named("p2", "%foo%")}
+
+	if !reflect.DeepEqual(named, expected) {
+		t.Errorf("expected named args %v, got %v\n", expected, named)
+	}
+}
+
+func Test_Template(t *testing.T) {
+	tmpl := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Param("user_id")),
+		Where("status", "=", Named("status", "open")),
+		OrWhere("author_id", "=", Param("user_id")),
+	).Template()
+
+	expected := "SELECT * FROM posts WHERE (user_id = $1 AND status = $2) OR (author_id = $3)"
+
+	if names := tmpl.Params(); !reflect.DeepEqual(names, []string{"user_id", "status"}) {
+		t.Errorf("unexpected params %v\n", names)
+	}
+
+	for _, id := range []int{1, 2} {
+		built, args := tmpl.Bind(map[string]interface{}{"user_id": id})
+
+		if built != expected {
+			t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+		}
+
+		if !reflect.DeepEqual(args, []interface{}{id, "open", id}) {
+			t.Errorf("unexpected args %v\n", args)
+		}
+	}
+}
+
+func Test_Args(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM variables WHERE (namespace_id IN (SELECT namespace_id FROM collaborators WHERE (user_id = $1) UNION SELECT id FROM namespaces WHERE (user_id = $2)) OR user_id = $3)",
+			[]interface{}{1, 2, 3},
+			Select(
+				Columns("*"),
+				From("variables"),
+				Where("namespace_id", "IN",
+					Union(
+						Select(Columns("namespace_id"), From("collaborators"), Where("user_id", "=", Arg(1))),
+						Select(Columns("id"), From("namespaces"), Where("user_id", "=", Arg(2))),
+					),
+				),
+				OrWhere("user_id", "=", Arg(3)),
+			),
+		},
+		{
+			"SELECT * FROM builds WHERE (status = $1 AND namespace_id IN (SELECT id FROM namespaces WHERE (root_id = $2))) OR (user_id = $3) AND (tag = $4)",
+			[]interface{}{"running", 2, 3, "v1"},
+			Select(
+				Columns("*"),
+				From("builds"),
+				Options(
+					Where("status", "=", Arg("running")),
+					Options(
+						Where("namespace_id", "IN", Select(Columns("id"), From("namespaces"), Where("root_id", "=", Arg(2)))),
+						OrWhere("user_id", "=", Arg(3)),
+					),
+				),
+				Where("tag", "=", Arg("v1")),
+			),
+		},
+		{
+			"SELECT COALESCE(name, $1) FROM users WHERE (id = $2)",
+			[]interface{}{"anon", 1},
+			Select(Raw("COALESCE(name, ?)", "anon"), From("users"), Where("id", "=", Arg(1))),
+		},
+		{
+			"UPDATE posts SET score = (SELECT SUM(score) FROM votes WHERE (post_id = $1)), title = $2 WHERE (id = $3) RETURNING id",
+			[]interface{}{1, "title", 1},
+			Update(
+				"posts",
+				Set("score", Select(Sum("score"), From("votes"), Where("post_id", "=", Arg(1)))),
+				Set("title", Arg("title")),
+				Where("id", "=", Arg(1)),
+				Returning("id"),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+}
+
+func Test_Dedupe(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Arg(10)),
+		OrWhere("author_id", "=", Arg(10)),
+		Where("tags", "@>", Arg([]string{"a"})),
+		Where("status", "=", Arg("open")),
+		OrWhere("editor_id", "=", Arg(10)),
+		Dedupe(),
+	)
+
+	expected := "SELECT * FROM posts WHERE (user_id = $1 OR author_id = $1) AND (tags @> $2 AND status = $3) OR (editor_id = $1)"
+
+	if built := q.Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	if args := q.Args(); !reflect.DeepEqual(args, []interface{}{10, []string{"a"}, "open"}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+
+	built, next := q.BuildN(3)
+
+	if next != 6 {
+		t.Errorf("expected next placeholder 6, got %d for %q\n", next, built)
+	}
+
+	expected = "SELECT * FROM posts WHERE (user_id = ? OR author_id = ?) AND (tags @> ? AND status = ?) OR (editor_id = ?)"
+
+	if built := q.BuildWith(Question); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	if args := q.ArgsWith(Question); !reflect.DeepEqual(args, []interface{}{10, 10, []string{"a"}, "open", 10}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+
+	tmpl := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Param("user_id")),
+		OrWhere("author_id", "=", Param("author_id")),
+		Dedupe(),
+	).Template()
+
+	built, args := tmpl.Bind(map[string]interface{}{"user_id": 1, "author_id": 2})
+
+	if expected := "SELECT * FROM posts WHERE (user_id = $1 OR author_id = $2)"; built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	if !reflect.DeepEqual(args, []interface{}{1, 2}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+}
+
+func Test_ToSql(t *testing.T) {
+	var s Sqlizer = Select(Columns("*"), From("users"), Where("id", "=", Arg(1)))
+
+	sql, args, err := s.ToSql()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "SELECT * FROM users WHERE (id = $1)"; sql != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, sql)
+	}
+
+	if !reflect.DeepEqual(args, []interface{}{1}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+
+	if _, _, err := Delete("").ToSql(); !errors.Is(err, ErrTable) {
+		t.Errorf("expected error %v, got %v\n", ErrTable, err)
+	}
+}
+
+func Test_BuildTo(t *testing.T) {
+	tests := []Query{
+		Select(Columns("*"), From("users"), Where("id", "=", Arg(1))),
+		Select(Columns("*"), From("users"), WithDialect(MySQL), Where("id", "=", Arg(1))),
+		Select(Columns("*"), From("users"), Dedupe(), Where("id", "=", Arg(1)), OrWhere("author_id", "=", Arg(1))),
+		benchmarkInsert(10),
+	}
+
+	for i, test := range tests {
+		var buf strings.Builder
+
+		if err := test.BuildTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := test.Build()
+
+		if got := buf.String(); got != expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, expected, got)
+		}
+
+		prefix := []byte("-- query\n")
+
+		if got := string(test.AppendBuild(prefix)); got != string(prefix)+expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, string(prefix)+expected, got)
+		}
+	}
+}
+
+func Test_Fingerprint(t *testing.T) {
+	posts := func(opts ...Option) Query {
+		return Select(Columns("*"), append([]Option{From("posts")}, opts...)...)
+	}
+
+	tests := []struct {
+		same bool
+		q1   Query
+		q2   Query
+	}{
+		{true, posts(Where("id", "=", Arg(1))), posts(Where("id", "=", Arg(2)))},
+		{true, posts(Where("id", "IN", List(1, 2))), posts(Where("id", "IN", List(3, 4)))},
+		{true, benchmarkInsert(2), benchmarkInsert(2)},
+		{false, posts(Where("id", "=", Arg(1))), posts(Where("id", "!=", Arg(1)))},
+		{false, posts(Where("id", "=", Arg(1))), posts(OrWhere("id", "=", Arg(1)))},
+		{false, posts(Where("id", "IN", List(1, 2))), posts(Where("id", "IN", List(1, 2, 3)))},
+		{false, posts(Where("id", "=", Arg(1))), posts(Where("id", "=", Lit("arg")))},
+		{false, posts(Limit(10)), posts(Limit(20))},
+		{false, posts(Where("id", "=", Arg(1))), posts(WithDialect(MySQL), Where("id", "=", Arg(1)))},
+		{false, posts(Where("id", "=", Named("id", 1))), posts(Where("id", "=", Named("post_id", 1)))},
+		{false, benchmarkInsert(2), benchmarkInsert(3)},
+		{
+			false,
+			posts(Dedupe(), Where("user_id", "=", Arg(1)), OrWhere("author_id", "=", Arg(1))),
+			posts(Dedupe(), Where("user_id", "=", Arg(1)), OrWhere("author_id", "=", Arg(2))),
+		},
+	}
+
+	for i, test := range tests {
+		if same := test.q1.Fingerprint() == test.q2.Fingerprint(); same != test.same {
+			t.Errorf("tests[%d]: expected same = %v, got %v\n\t%q\n\t%q\n", i, test.same, same, test.q1.Build(), test.q2.Build())
+		}
+	}
+}
+
+func Test_NormalizedFingerprint(t *testing.T) {
+	posts := func(opts ...Option) Query {
+		return Select(Columns("*"), append([]Option{From("posts")}, opts...)...)
+	}
+
+	tests := []struct {
+		same bool
+		q1   Query
+		q2   Query
+	}{
+		{true, posts(Where("id", "=", Arg(1))), posts(Where("id", "=", Arg(2)))},
+		{true, posts(Where("id", "IN", List(1, 2))), posts(Where("id", "IN", List(1, 2, 3)))},
+		{true, posts(WhereIn("id", []int{1})), posts(WhereIn("id", []int{1, 2, 3, 4}))},
+		{true, posts(Limit(10), Offset(0)), posts(Limit(25), Offset(50))},
+		{true, posts(Limit(10), Offset(0), WithDialect(SQLServer)), posts(Limit(25), Offset(50), WithDialect(SQLServer))},
+		{true, posts(Where("score", ">", Lit(1))), posts(Where("score", ">", Lit(2.5)))},
+		{true, benchmarkInsert(2), benchmarkInsert(3)},
+		{
+			true,
+			posts(Dedupe(), Where("user_id", "=", Arg(1)), OrWhere("author_id", "=", Arg(1))),
+			posts(Dedupe(), Where("user_id", "=", Arg(1)), OrWhere("author_id", "=", Arg(2))),
+		},
+		{false, posts(Where("id", "=", Arg(1))), posts(Where("id", "!=", Arg(1)))},
+		{false, posts(Where("deleted_at", "IS", Lit("NULL"))), posts(Where("deleted_at", "IS", Lit("NOW()")))},
+		{false, posts(Limit(10)), posts(Offset(10))},
+		{false, posts(Where("id", "IN", List(1, 2))), posts(Where("id", "IN", Select(Columns("id"), From("users"))))},
+		{false, benchmarkInsert(2), Insert("posts", Columns("id", "title"), Values(1, "title"))},
+	}
+
+	for i, test := range tests {
+		if same := test.q1.NormalizedFingerprint() == test.q2.NormalizedFingerprint(); same != test.same {
+			t.Errorf("tests[%d]: expected same = %v, got %v\n\t%q\n\t%q\n", i, test.same, same, test.q1.Build(), test.q2.Build())
+		}
+	}
+
+	if q := posts(Limit(10)); q.Fingerprint() == q.NormalizedFingerprint() {
+		t.Errorf("expected fingerprint and normalized fingerprint to differ\n")
+	}
+}
+
+func Test_Cache(t *testing.T) {
+	var cache Cache
+
+	for i := 0; i < 3; i++ {
+		q := Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(i)))
+
+		if got, expected := cache.Build(q), q.Build(); got != expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, expected, got)
+		}
+	}
+
+	if n := cache.Len(); n != 1 {
+		t.Errorf("expected 1 cached query, got %d\n", n)
+	}
+
+	cache.Reset()
+
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected 0 cached queries, got %d\n", n)
+	}
+
+	cache.Max = 2
+
+	for i := 1; i <= 4; i++ {
+		q := Select(Columns("*"), From("posts"), WhereIn("id", make([]int, i)))
+
+		if got, expected := cache.Build(q), q.Build(); got != expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, expected, got)
+		}
+	}
+
+	if n := cache.Len(); n != 2 {
+		t.Errorf("expected 2 cached queries, got %d\n", n)
+	}
+
+	cache.Reset()
+
+	for i := 0; i < 3; i++ {
+		q := Select(Columns("*"), From("posts"), Hook(Where("user_id", "=", Arg(i)), OrderDesc("id")))
+
+		if got, expected := cache.Build(q), q.Build(); got != expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, expected, got)
+		}
+	}
+
+	if n := cache.Len(); n != 1 {
+		t.Errorf("expected 1 cached query, got %d\n", n)
+	}
+
+	q := Select(Columns("*"), From("posts"), Set("title", Arg("foo")))
+
+	if got, expected := cache.Build(q), q.Build(); got != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, got)
+	}
+
+	if n := cache.Len(); n != 1 {
+		t.Errorf("expected query with error to not be cached, got %d cached queries\n", n)
+	}
+}
+
+func Test_Reuse(t *testing.T) {
+	base := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Arg(1)),
+		Where("deleted_at", "IS", Lit("NULL")),
+	)
+
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND deleted_at IS NULL AND published = $2)",
+			Where("published", "=", Arg(true))(base),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND deleted_at IS NULL AND title LIKE $2)",
+			Where("title", "LIKE", Arg("%query%"))(base),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND deleted_at IS NULL) ORDER BY created_at DESC LIMIT 10",
+			Options(OrderDesc("created_at"), Limit(10))(base),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND deleted_at IS NULL) ORDER BY title ASC LIMIT 5",
+			Options(OrderAsc("title"), Limit(5))(base),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND deleted_at IS NULL)",
+			base,
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+
+	args := []interface{}{1, true}
+
+	if !reflect.DeepEqual(tests[0].q.Args(), args) {
+		t.Errorf("expected args %v, got %v\n", args, tests[0].q.Args())
+	}
+}
+
+func Test_Builder(t *testing.T) {
+	var b Builder
+
+	for i := 0; i < 3; i++ {
+		b.Reset()
+		b.Select(Columns("*")).Apply(
+			From("posts"),
+			Where("user_id", "=", Arg(i)),
+			If(i > 0, Where("published", "=", Arg(true))),
+		)
+
+		expected := Select(
+			Columns("*"),
+			From("posts"),
+			Where("user_id", "=", Arg(i)),
+			If(i > 0, Where("published", "=", Arg(true))),
+		)
+
+		if built := b.Build(); built != expected.Build() {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, expected.Build(), built)
+		}
+
+		if !reflect.DeepEqual(b.Args(), expected.Args()) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, expected.Args(), b.Args())
+		}
+	}
+
+	b.Reset()
+	b.Delete("posts").Apply(Where("id", "=", Arg(1)))
+
+	if expected, built := "DELETE FROM posts WHERE (id = $1)", b.Query().Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+}
+
+func Test_StmtCache(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT * FROM posts WHERE (user_id = $1)").WillBeClosed()
+	mock.ExpectPrepare("SELECT * FROM posts WHERE (id = $1)").WillBeClosed()
+
+	var stmts StmtCache
+
+	ctx := context.Background()
+
+	stmt1, err := stmts.Prepare(ctx, db, Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(1))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt2, err := stmts.Prepare(ctx, db, Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(2))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stmt1 != stmt2 {
+		t.Errorf("expected statement to be reused\n")
+	}
+
+	stmt3, err := stmts.Prepare(ctx, db, Select(Columns("*"), From("posts"), Where("id", "=", Arg(1))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stmt3 == stmt1 {
+		t.Errorf("expected new statement to be prepared\n")
+	}
+
+	if _, err := stmts.Prepare(ctx, db, Delete("")); !errors.Is(err, ErrTable) {
+		t.Errorf("expected error %v, got %v\n", ErrTable, err)
+	}
+
+	if err := stmts.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Check(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	errRelation := errors.New(`relation "post" does not exist`)
+
+	mock.ExpectPrepare("SELECT * FROM posts WHERE (user_id = $1)").WillBeClosed()
+	mock.ExpectPrepare("SELECT * FROM post WHERE (user_id = $1)").WillReturnError(errRelation)
+
+	ctx := context.Background()
+
+	if err := Check(ctx, db, Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(1)))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Check(ctx, db, Select(Columns("*"), From("post"), Where("user_id", "=", Arg(1)))); !errors.Is(err, errRelation) {
+		t.Errorf("expected error %v, got %v\n", errRelation, err)
+	}
+
+	if err := Check(ctx, db, Select(Columns("*"))); !errors.Is(err, ErrFrom) {
+		t.Errorf("expected error %v, got %v\n", ErrFrom, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Exec(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	mock.ExpectExec("DELETE FROM posts WHERE (id = $1)").
+		WithArgs(1).
+		WillReturnResult(mockdb.NewResult(0, 1))
+
+	mock.ExpectQuery("SELECT id, title FROM posts WHERE (user_id = $1)").
+		WithArgs(2).
+		WillReturnRows(mockdb.NewRows([]string{"id", "title"}).AddRow(1, "foo").AddRow(2, "bar"))
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM posts WHERE (user_id = $1)").
+		WithArgs(2).
+		WillReturnRows(mockdb.NewRows([]string{"count"}).AddRow(2))
+
+	res, err := Delete("posts", Where("id", "=", Arg(1))).ExecContext(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("expected 1 row affected, got %d\n", n)
+	}
+
+	rows, err := Select(Columns("id", "title"), From("posts"), Where("user_id", "=", Arg(2))).QueryContext(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+
+	for rows.Next() {
+		n++
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Errorf("expected 2 rows, got %d\n", n)
+	}
+
+	var count int
+
+	if err := Select(Count("*"), From("posts"), Where("user_id", "=", Arg(2))).QueryRowContext(ctx, db).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Errorf("expected count 2, got %d\n", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Copy(t *testing.T) {
+	c := CopyIn("public.users", "email", "username")
+
+	if expected, built := `COPY "public"."users" ("email", "username") FROM STDIN`, c.Build(); built != expected {
+		t.Fatalf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectBegin()
+
+	stmt := mock.ExpectPrepare(c.Build())
+	stmt.ExpectExec().WithArgs("me@example.com", "me").WillReturnResult(mockdb.NewResult(0, 0))
+	stmt.ExpectExec().WithArgs("you@example.com", "you").WillReturnResult(mockdb.NewResult(0, 0))
+	stmt.ExpectExec().WithArgs().WillReturnResult(mockdb.NewResult(0, 2))
+	stmt.WillBeClosed()
+
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := c.Exec(context.Background(), tx, [][]interface{}{
+		{"me@example.com", "me"},
+		{"you@example.com", "you"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Errorf("expected 2 rows copied, got %d\n", n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_WithTx(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	debit := Update("accounts", Set("balance", Raw("balance - ?", 10)), Where("id", "=", Arg(1)))
+	credit := Update("accounts", Set("balance", Raw("balance + ?", 10)), Where("id", "=", Arg(2)))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts SET balance = balance - $1 WHERE (id = $2)").WithArgs(10, 1).WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectExec("UPDATE accounts SET balance = balance + $1 WHERE (id = $2)").WithArgs(10, 2).WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = WithTx(ctx, db, func(tx Execer) error {
+		if _, err := debit.ExecContext(ctx, tx); err != nil {
+			return err
+		}
+
+		_, err := credit.ExecContext(ctx, tx)
+		return err
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errFailed := errors.New("failed")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	if err := WithTx(ctx, db, func(tx Execer) error { return errFailed }); !errors.Is(err, errFailed) {
+		t.Errorf("expected error %v, got %v\n", errFailed, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	func() {
+		defer func() {
+			if v := recover(); v != "panicked" {
+				t.Errorf("expected panic %q, got %v\n", "panicked", v)
+			}
+		}()
+
+		WithTx(ctx, db, func(tx Execer) error { panic("panicked") })
+	}()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Typed(t *testing.T) {
+	type Post struct {
+		ID    int64  `db:"id"`
+		Title string `db:"title"`
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT id, title FROM posts WHERE (user_id = $1)").
+		WithArgs(1).
+		WillReturnRows(mockdb.NewRows([]string{"id", "title"}).AddRow(1, "foo").AddRow(2, "bar"))
+
+	mock.ExpectQuery("SELECT id, title FROM posts WHERE (id = $1)").
+		WithArgs(2).
+		WillReturnRows(mockdb.NewRows([]string{"id", "title"}).AddRow(2, "bar"))
+
+	mock.ExpectQuery("SELECT id, title FROM posts WHERE (id = $1)").
+		WithArgs(3).
+		WillReturnRows(mockdb.NewRows([]string{"id", "title"}))
+
+	posts, err := All[Post](ctx, db, Select(Columns("id", "title"), From("posts"), Where("user_id", "=", Arg(1))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []Post{{1, "foo"}, {2, "bar"}}; !reflect.DeepEqual(posts, expected) {
+		t.Errorf("expected %v, got %v\n", expected, posts)
+	}
+
+	p, err := One[Post](ctx, db, Select(Columns("id", "title"), From("posts"), Where("id", "=", Arg(2))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (Post{2, "bar"}); p != expected {
+		t.Errorf("expected %v, got %v\n", expected, p)
+	}
+
+	if _, err := One[Post](ctx, db, Select(Columns("id", "title"), From("posts"), Where("id", "=", Arg(3)))); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected error %v, got %v\n", sql.ErrNoRows, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type errRunner struct {
+	Runner
+
+	err error
+}
+
+func (r errRunner) QueryContext(ctx context.Context, db Execer) (*sql.Rows, error) {
+	return nil, r.err
+}
+
+func Test_Runner(t *testing.T) {
+	q := Select(Columns("id"), From("posts"))
+
+	var r Runner = errRunner{Runner: q, err: errors.New("connection reset")}
+
+	if built := r.Build(); built != q.Build() {
+		t.Errorf("expected = %q, got = %q\n", q.Build(), built)
+	}
+
+	if _, err := All[struct{}](context.Background(), nil, r); err == nil || err.Error() != "connection reset" {
+		t.Errorf("expected error %q, got %v\n", "connection reset", err)
+	}
+}
+
+func Test_Paginate(t *testing.T) {
+	tests := []struct {
+		expected      string
+		page, perPage int
+	}{
+		{"SELECT * FROM posts LIMIT 10 OFFSET 0", 1, 10},
+		{"SELECT * FROM posts LIMIT 10 OFFSET 20", 3, 10},
+		{"SELECT * FROM posts LIMIT 10 OFFSET 0", -1, 10},
+		{"SELECT * FROM posts LIMIT 25 OFFSET 25", 2, 0},
+		{"SELECT * FROM posts LIMIT 100 OFFSET 100", 2, 1000},
+	}
+
+	for i, test := range tests {
+		q := Select(Columns("*"), From("posts"), Paginate(test.page, test.perPage))
+
+		if built := q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+
+	type Post struct {
+		ID int64 `db:"id"`
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT id FROM posts WHERE (user_id = $1) ORDER BY id DESC LIMIT 2 OFFSET 2").
+		WithArgs(1).
+		WillReturnRows(mockdb.NewRows([]string{"id"}).AddRow(3).AddRow(2))
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM posts WHERE (user_id = $1)").
+		WithArgs(1).
+		WillReturnRows(mockdb.NewRows([]string{"count"}).AddRow(5))
+
+	mock.ExpectCommit()
+
+	q := Select(
+		Columns("id"),
+		From("posts"),
+		Where("user_id", "=", Arg(1)),
+		OrderDesc("id"),
+		Paginate(2, 2),
+	)
+
+	posts, total, err := Page[Post](context.Background(), db, q)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []Post{{3}, {2}}; !reflect.DeepEqual(posts, expected) {
+		t.Errorf("expected %v, got %v\n", expected, posts)
+	}
+
+	if total != 5 {
+		t.Errorf("expected total %d, got %d\n", 5, total)
+	}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT id FROM posts WHERE (user_id = $1) ORDER BY id DESC LIMIT 2 OFFSET 2").
+		WithArgs(1).
+		WillReturnError(errors.New("connection reset"))
+
+	mock.ExpectRollback()
+
+	if _, _, err := Page[Post](context.Background(), db, q); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Token(t *testing.T) {
+	key := []byte("secret")
+	created := time.Date(2021, 3, 14, 15, 9, 26, 535897000, time.UTC)
+
+	vals := []interface{}{created, 10, uint8(2), 1.5, "foo", []byte("bar"), true, nil}
+
+	tok, err := EncodeToken(key, vals...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeToken(key, tok)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{created, int64(10), uint64(2), 1.5, "foo", []byte("bar"), true, nil}
+
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("unexpected values\n\texpected = %v\n\tgot      = %v\n", expected, decoded)
+	}
+
+	if _, err := EncodeToken(key, struct{}{}); err == nil {
+		t.Errorf("expected error for unsupported value\n")
+	}
+
+	b := []byte(tok)
+	b[0]++
+
+	bad := []string{
+		"",
+		"!!",
+		string(b),
+		tok[:len(tok)-1],
+	}
+
+	for i, tok := range bad {
+		if _, err := DecodeToken(key, tok); !errors.Is(err, ErrToken) {
+			t.Errorf("bad[%d]: expected error %v, got %v\n", i, ErrToken, err)
+		}
+	}
+
+	if _, err := DecodeToken([]byte("other"), tok); !errors.Is(err, ErrToken) {
+		t.Errorf("expected error %v, got %v\n", ErrToken, err)
+	}
+
+	order := []OrderedColumn{Desc("created_at"), Desc("id")}
+
+	tok, err = EncodeToken(key, created, 10)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expected string
+		tok      string
+	}{
+		{"SELECT * FROM posts ORDER BY created_at DESC, id DESC", ""},
+		{"SELECT * FROM posts WHERE ((created_at, id) < ($1, $2)) ORDER BY created_at DESC, id DESC", tok},
+	}
+
+	for i, test := range tests {
+		seek, err := SeekToken(order, key, test.tok)
+
+		if err != nil {
+			t.Fatalf("tests[%d]: %s\n", i, err)
+		}
+
+		q := Select(Columns("*"), From("posts"), seek)
+
+		if built := q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if i == 1 {
+			if args := q.Args(); !reflect.DeepEqual(args, []interface{}{created, int64(10)}) {
+				t.Errorf("tests[%d]: unexpected args %v\n", i, args)
+			}
+		}
+	}
+
+	tok, err = EncodeToken(key, 10)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SeekToken(order, key, tok); !errors.Is(err, ErrToken) {
+		t.Errorf("expected error %v, got %v\n", ErrToken, err)
+	}
+}
+
+func Test_ForTenant(t *testing.T) {
+	RegisterTable(TableDef{
+		Name:   "invoices",
+		Tenant: "tenant_id",
+	})
+
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			"SELECT * FROM invoices WHERE (invoices.tenant_id = $1)",
+			Select(Columns("*"), From("invoices"), ForTenant(7)),
+		},
+		{
+			"SELECT * FROM invoices i WHERE ((paid = $1 OR total > $2) AND i.tenant_id = $3) ORDER BY id ASC",
+			Select(Columns("*"), From("invoices i"), Where("paid", "=", Arg(false)), OrWhere("total", ">", Arg(100)), OrderAsc("id"), ForTenant(7)),
+		},
+		{
+			"SELECT * FROM invoices WHERE (((paid = $1 AND total > $2) OR (due_at < NOW())) AND invoices.tenant_id = $3)",
+			Select(Columns("*"), From("invoices"), Where("paid", "=", Arg(false)), Where("total", ">", Arg(100)), OrWhere("due_at", "<", Lit("NOW()")), ForTenant(99)),
+		},
+		{
+			"UPDATE invoices SET paid = $1 WHERE (invoices.tenant_id = $2)",
+			Update("invoices", ForTenant(7), Set("paid", Arg(true))),
+		},
+		{
+			"DELETE FROM invoices WHERE (id = $1 AND invoices.tenant_id = $2)",
+			Delete("invoices", Where("id", "=", Arg(1)), ForTenant(7)),
+		},
+		{
+			"SELECT * FROM users WHERE (id IN (SELECT user_id FROM invoices WHERE (invoices.tenant_id = $1)))",
+			Select(Columns("*"), From("users"), Where("id", "IN", Select(Columns("user_id"), From("invoices"), ForTenant(7)))),
+		},
+		{
+			"SELECT * FROM invoices i JOIN customers c ON c.id = i.customer_id WHERE (i.tenant_id = $1)",
+			Select(Columns("*"), From("invoices i JOIN customers c ON c.id = i.customer_id"), ForTenant(7)),
+		},
+		{
+			"UPDATE invoices SET paid = $1 FROM payments p WHERE (p.invoice_id = invoices.id AND invoices.tenant_id = $2)",
+			Update("invoices", Set("paid", Arg(true)), From("payments p"), Where("p.invoice_id", "=", Ident("invoices.id")), ForTenant(7)),
+		},
+		{
+			"SELECT * FROM users",
+			Select(Columns("*"), From("users"), ForTenant(7)),
+		},
+		{
+			"INSERT INTO invoices (total) VALUES ($1)",
+			Insert("invoices", Columns("total"), Values(10)),
+		},
+		{
+			"SELECT COUNT(*) FROM invoices",
+			Select(Count("*"), From("invoices"), AllTenants()),
+		},
+	}
+
+	for i, test := range tests {
+		if err := test.q.Err(); err != nil {
+			t.Fatalf("tests[%d]: %s\n", i, err)
+		}
+
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+
+	missing := []Query{
+		Select(Columns("*"), From("invoices")),
+		Update("invoices", Set("paid", Arg(true))),
+		Delete("invoices"),
+		Select(Columns("*"), From("users"), Where("id", "IN", Select(Columns("user_id"), From("invoices")))),
+		CountOf(Select(Columns("*"), From("invoices"))),
+		CountOf(SelectDistinct(Columns("user_id"), From("invoices"))),
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	for i, q := range missing {
+		if _, err := q.ExecContext(ctx, db); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v from ExecContext, got %v\n", i, ErrTenant, err)
+		}
+
+		if _, err := q.QueryContext(ctx, db); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v from QueryContext, got %v\n", i, ErrTenant, err)
+		}
+
+		var n int
+
+		if err := q.QueryRowContext(ctx, db).Scan(&n); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v from QueryRowContext, got %v\n", i, ErrTenant, err)
+		}
+
+		if _, _, err := q.ToSql(); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v from ToSql, got %v\n", i, ErrTenant, err)
+		}
+
+		if _, err := q.BuildErr(); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v, got %v\n", i, ErrTenant, err)
+		}
+
+		if _, err := json.Marshal(q); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v, got %v\n", i, ErrTenant, err)
+		}
+
+		if a := q.Audit(false); a.SQL == "" {
+			t.Errorf("missing[%d]: expected audit to have SQL\n", i)
+		}
+
+		if s := q.Build(); s != "" {
+			t.Errorf("missing[%d]: expected empty query, got %q\n", i, s)
+		}
+
+		if err := q.BuildTo(io.Discard); !errors.Is(err, ErrTenant) {
+			t.Errorf("missing[%d]: expected error %v, got %v\n", i, ErrTenant, err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Qualify(t *testing.T) {
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			"SELECT p.id, p.title, u.username FROM posts p JOIN users u ON u.id = p.user_id WHERE (p.user_id = $1) ORDER BY p.created_at DESC",
+			Select(
+				Columns("id", "title", "u.username"),
+				From("posts p JOIN users u ON u.id = p.user_id"),
+				Where("user_id", "=", Arg(10)),
+				OrderDesc("created_at"),
+				Qualify(),
+			),
+		},
+		{
+			"SELECT posts.id AS post_id, COUNT(*) FROM posts WHERE (posts.deleted_at IS NULL)",
+			Select(Columns("id AS post_id", "COUNT(*)"), From("posts"), NotDeleted(), Qualify()),
+		},
+		{
+			"SELECT p.* FROM posts AS p WHERE ((p.created_at, p.id) < ($1, $2)) ORDER BY p.created_at DESC, p.id DESC",
+			Select(Columns("p.*"), From("posts AS p"), Seek([]OrderedColumn{Desc("created_at"), Desc("id")}, 1, 2), Qualify()),
+		},
+		{
+			"UPDATE posts SET title = $1 WHERE (posts.id = $2)",
+			Update("posts", Set("title", Arg("foo")), Where("id", "=", Arg(1)), Qualify()),
+		},
+		{
+			"INSERT INTO posts (title) VALUES ($1)",
+			Insert("posts", Columns("title"), Values("foo"), Qualify()),
+		},
+		{
+			"SELECT id FROM posts p JOIN users u ON u.id = p.user_id",
+			Select(Columns("id"), From("posts p JOIN users u ON u.id = p.user_id")),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+}
+
+func Test_Format(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			`SELECT * FROM "users" WHERE id = $1`,
+			[]interface{}{10},
+			Format("SELECT * FROM %s WHERE id = %a", Ident("users"), 10),
+		},
+		{
+			`SELECT "u"."email" FROM "public"."users" u WHERE status IN ($1, $2) AND name LIKE $3 || '%'`,
+			[]interface{}{"active", "pending", "foo"},
+			Format("SELECT %s FROM %s u WHERE status IN %a AND name LIKE %a || '%%'", "u.email", Quote("public.users"), List("active", "pending"), "foo"),
+		},
+		{
+			"SELECT `users`.* FROM `users` WHERE id = ?",
+			[]interface{}{10},
+			WithDialect(MySQL)(Format("SELECT %s FROM %s WHERE id = %a", "users.*", "users", 10)),
+		},
+		{
+			`SELECT * FROM "posts" WHERE user_id IN (SELECT id FROM users WHERE (banned = $1)) AND id > $2`,
+			[]interface{}{false, 5},
+			Format("SELECT * FROM %s WHERE user_id IN %a AND id > %a", "posts", Select(Columns("id"), From("users"), Where("banned", "=", Arg(false))), 5),
+		},
+		{
+			`SELECT * FROM "a""b"`,
+			[]interface{}{},
+			Format("SELECT * FROM %s", `a"b`),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	panics := []func(){
+		func() { Format("SELECT %s") },
+		func() { Format("SELECT %a", 1, 2) },
+		func() { Format("SELECT %d", 1) },
+		func() { Format("SELECT %s", 1) },
+		func() { Format("SELECT 1 %") },
+	}
+
+	for i, fn := range panics {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("panics[%d]: expected panic\n", i)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func Test_BuildInlined(t *testing.T) {
+	type status string
+
+	created := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	var (
+		nullTime *time.Time
+		nullInt  sql.NullInt64
+	)
+
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			`INSERT INTO posts (title, tags, created_at) VALUES ('It''s here', ARRAY['go', 'sql'], '2021-03-14T15:09:26Z'::timestamptz)`,
+			Insert("posts", Columns("title", "tags", "created_at"), Values("It's here", []string{"go", "sql"}, created)),
+		},
+		{
+			`INSERT INTO files (data, path, size, ids, deleted_at, parent_id) VALUES ('\x0102ff'::bytea, E'C:\\temp', 1.5, '{}', NULL, NULL)`,
+			Insert("files", Columns("data", "path", "size", "ids", "deleted_at", "parent_id"), Values([]byte{1, 2, 255}, `C:\temp`, 1.5, []int{}, nullTime, nullInt)),
+		},
+		{
+			`SELECT * FROM posts WHERE (status = 'draft' AND pinned = TRUE AND id IN (1, 2) AND score < 'Infinity'::float8)`,
+			Select(Columns("*"), From("posts"), Where("status", "=", Arg(status("draft"))), Where("pinned", "=", Arg(true)), Where("id", "IN", List(1, 2)), Where("score", "<", Arg(math.Inf(1)))),
+		},
+		{
+			`UPDATE jobs SET timeout = '1500000 microseconds'::interval, note = '?' WHERE (id = 10)`,
+			Update("jobs", Set("timeout", Arg(1500*time.Millisecond)), Set("note", Lit("'?'")), Where("id", "=", Arg(10))),
+		},
+		{
+			`SELECT * FROM posts WHERE (grid = ARRAY[ARRAY[1, 2], ARRAY[3, 4]])`,
+			Select(Columns("*"), From("posts"), Where("grid", "=", Arg([][]int{{1, 2}, {3, 4}}))),
+		},
+		{
+			"INSERT INTO posts (title, pinned, created_at, timeout) VALUES ('It''s here', TRUE, '2021-03-14T15:09:26Z', '1500ms')",
+			Insert("posts", Columns("title", "pinned", "created_at", "timeout"), Values("It's here", true, &created, 1500*time.Millisecond), WithDialect(MySQL)),
+		},
+	}
+
+	for i, test := range tests {
+		s, err := test.q.BuildInlined()
+
+		if err != nil {
+			t.Fatalf("tests[%d]: %s\n", i, err)
+		}
+
+		if s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+
+	if _, err := Select(Columns("*"), From("posts"), Where("meta", "=", Arg(struct{}{}))).BuildInlined(); err == nil {
+		t.Errorf("expected error for unsupported argument\n")
+	}
+
+	if _, err := Select(Columns("*"), From("posts"), Where("id", "~~", Arg(1))).BuildInlined(); !errors.Is(err, ErrOperator) {
+		t.Errorf("expected error %v, got %v\n", ErrOperator, err)
+	}
+}
+
+func Test_ClauseOrder(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND draft = $2) ORDER BY created_at DESC LIMIT 10 OFFSET 20",
+			[]interface{}{1, false},
+			Select(Columns("*"), OrderDesc("created_at"), Offset(20), Limit(10), Where("user_id", "=", Arg(1)), From("posts"), Where("draft", "=", Arg(false))),
+		},
+		{
+			"UPDATE posts SET title = $1 WHERE (id = $2) RETURNING id",
+			[]interface{}{"foo", 1},
+			Update("posts", Returning("id"), Where("id", "=", Arg(1)), Set("title", Arg("foo"))),
+		},
+		{
+			"INSERT INTO posts (id, title) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING RETURNING id",
+			[]interface{}{1, "foo"},
+			Insert("posts", Columns("id", "title"), Returning("id"), OnConflictDoNothing("id"), Values(1, "foo")),
+		},
+		{
+			"DELETE FROM posts WHERE (id = $1) RETURNING id",
+			[]interface{}{1},
+			Delete("posts", Returning("id"), Where("id", "=", Arg(1))),
+		},
+		{
+			"SELECT user_id, COUNT(*) FROM posts WHERE (draft = $1) GROUP BY user_id ORDER BY user_id ASC",
+			[]interface{}{false},
+			Select(Columns("user_id", "COUNT(*)"), OrderAsc("user_id"), GroupBy("user_id"), From("posts"), Where("draft", "=", Arg(false))),
+		},
+		{
+			"SELECT p.user_id, COUNT(*) FROM posts p GROUP BY p.user_id",
+			[]interface{}{},
+			Select(Columns("user_id", "COUNT(*)"), From("posts p"), GroupBy("user_id"), Qualify()),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	kinds := make([]string, 0)
+
+	for _, cl := range tests[0].q.Clauses() {
+		kinds = append(kinds, cl.Kind())
+	}
+
+	if expected := []string{"FROM", "WHERE", "WHERE", "ORDER BY", "LIMIT", "OFFSET"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected clauses %v, got %v\n", expected, kinds)
+	}
+}
+
+func Test_MergeClauses(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts LIMIT 5 OFFSET 10",
+			[]interface{}{},
+			Select(Columns("*"), From("posts"), Limit(10), Offset(20), Limit(5), Offset(10)),
+		},
+		{
+			"SELECT * FROM posts p, users u WHERE (u.id = p.user_id)",
+			[]interface{}{},
+			Select(Columns("*"), From("posts p"), From("users u"), WhereExpr(Lit("u.id = p.user_id"))),
+		},
+		{
+			"SELECT * FROM posts ORDER BY created_at DESC, id ASC",
+			[]interface{}{},
+			Select(Columns("*"), From("posts"), OrderDesc("created_at"), OrderAsc("id", "created_at"), OrderAsc("id")),
+		},
+		{
+			"SELECT user_id, status, COUNT(*) FROM posts GROUP BY user_id, status",
+			[]interface{}{},
+			Select(Columns("user_id", "status", "COUNT(*)"), From("posts"), GroupBy("user_id"), GroupBy("status", "user_id"), GroupBy("user_id")),
+		},
+		{
+			"UPDATE posts SET views = $1, title = $2 RETURNING id, title",
+			[]interface{}{2, "bar"},
+			Update("posts", Set("title", Arg("foo")), Set("views", Arg(2)), Set("title", Arg("bar")), Returning("id"), Returning("title", "id")),
+		},
+		{
+			"INSERT INTO posts (id, title) VALUES ($1, $2), ($3, $4) ON CONFLICT (id) DO UPDATE SET title = EXCLUDED.title",
+			[]interface{}{1, "foo", 2, "bar"},
+			Insert("posts", Columns("id", "title"), Values(1, "foo"), Values(2, "bar"), OnConflictDoNothing("id"), OnConflictUpdate([]string{"id"}, "title")),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND draft = $2)",
+			[]interface{}{1, false},
+			Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(1)), Where("draft", "=", Arg(false))),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %#v, got %#v\n", i, test.args, args)
+		}
+	}
+}
+
+func Test_Cursor(t *testing.T) {
+	q := Select(Columns("id"), From("posts"), Where("user_id", "=", Arg(1)))
+
+	tests := []struct {
+		expected string
+		c        Cursor
+	}{
+		{`DECLARE "export" CURSOR FOR SELECT id FROM posts WHERE (user_id = $1)`, DeclareCursor("export", q)},
+		{`DECLARE "export" NO SCROLL CURSOR WITH HOLD FOR SELECT id FROM posts WHERE (user_id = $1)`, DeclareCursor("export", q, NoScroll(), WithHold())},
+		{`DECLARE "export" SCROLL CURSOR FOR SELECT id FROM posts WHERE (user_id = $1)`, DeclareCursor("export", q, Scroll())},
+	}
+
+	for i, test := range tests {
+		if built := test.c.Declare(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	c := DeclareCursor("export", q)
+
+	mock.ExpectExec(c.Declare()).WithArgs(1).WillReturnResult(mockdb.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH FORWARD 2 FROM "export"`).WillReturnRows(mockdb.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery(`FETCH FORWARD 2 FROM "export"`).WillReturnRows(mockdb.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec(`CLOSE "export"`).WillReturnResult(mockdb.NewResult(0, 0))
+
+	ids := make([]int64, 0)
+
+	err = c.Each(context.Background(), db, 2, func(rows *sql.Rows) error {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []int64{1, 2, 3}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected ids %v, got %v\n", expected, ids)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Listen(t *testing.T) {
+	tests := []struct {
+		expected string
+		fn       func(string) (string, error)
+		channel  string
+		err      error
+	}{
+		{`LISTEN "jobs"`, Listen, "jobs", nil},
+		{`LISTEN "Jobs"`, Listen, "Jobs", nil},
+		{`LISTEN "a""b"`, Listen, `a"b`, nil},
+		{`UNLISTEN "jobs"`, Unlisten, "jobs", nil},
+		{`UNLISTEN *`, Unlisten, "*", nil},
+		{"", Listen, "", ErrChannel},
+		{"", Listen, strings.Repeat("a", 64), ErrChannel},
+		{"", Unlisten, "jobs\x00", ErrChannel},
+	}
+
+	for i, test := range tests {
+		s, err := test.fn(test.channel)
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %v, got %v\n", i, test.err, err)
+			continue
+		}
+
+		if s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+
+	q, err := Notify("jobs", Arg(`{"id":10}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, built := "SELECT pg_notify($1, $2)", q.Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	if args := []interface{}{"jobs", `{"id":10}`}; !reflect.DeepEqual(q.Args(), args) {
+		t.Errorf("expected args %v, got %v\n", args, q.Args())
+	}
+
+	if _, err := Notify("", Arg("")); !errors.Is(err, ErrChannel) {
+		t.Errorf("expected error %v, got %v\n", ErrChannel, err)
+	}
+}
+
+func Test_SetVar(t *testing.T) {
+	tests := []struct {
+		expected string
+		fn       func(string, interface{}) (string, error)
+		name     string
+		val      interface{}
+		err      error
+	}{
+		{"SET statement_timeout = '5000ms'", SetVar, "statement_timeout", 5 * time.Second, nil},
+		{"SET LOCAL statement_timeout = 0", SetLocal, "statement_timeout", 0, nil},
+		{"SET search_path = 'app', 'public'", SetVar, "search_path", []string{"app", "public"}, nil},
+		{"SET LOCAL app.user_id = 10", SetLocal, "app.user_id", int64(10), nil},
+		{"SET LOCAL app.name = 'O''Brien'", SetLocal, "app.name", "O'Brien", nil},
+		{`SET LOCAL app.path = E'C:\\temp'`, SetLocal, "app.path", `C:\temp`, nil},
+		{"SET enable_seqscan = FALSE", SetVar, "enable_seqscan", false, nil},
+		{"", SetVar, "statement_timeout; DROP TABLE users", 0, ErrSetting},
+		{"", SetVar, "", 0, ErrSetting},
+	}
+
+	for i, test := range tests {
+		s, err := test.fn(test.name, test.val)
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %v, got %v\n", i, test.err, err)
+			continue
+		}
+
+		if s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+
+	if _, err := SetVar("app.data", struct{}{}); err == nil {
+		t.Errorf("expected error for unsupported literal\n")
+	}
+}
+
+func Test_SetConfig(t *testing.T) {
+	q := SetConfig("app.current_tenant", "7", true)
+
+	if built := q.Build(); built != "SELECT set_config($1, $2, $3)" {
+		t.Errorf("unexpected query %q\n", built)
+	}
+
+	if args := q.Args(); !reflect.DeepEqual(args, []interface{}{"app.current_tenant", "7", true}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+
+	if err := SetConfig("app.tenant; DROP TABLE users", "7", true).Err(); !errors.Is(err, ErrSetting) {
+		t.Errorf("expected error %v, got %v\n", ErrSetting, err)
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	type tenantKey struct{}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "7")
+
+	settings := map[string]string{
+		"app.role": "member",
+	}
+
+	beginner := WithSettings(db, func(ctx context.Context) map[string]string {
+		settings["app.current_tenant"] = ctx.Value(tenantKey{}).(string)
+		return settings
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config($1, $2, $3)").WithArgs("app.current_tenant", "7", true).WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectExec("SELECT set_config($1, $2, $3)").WithArgs("app.role", "member", true).WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM posts WHERE (id = $1)").WithArgs(1).WillReturnResult(mockdb.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = WithTx(ctx, beginner, func(tx Execer) error {
+		_, err := Delete("posts", Where("id", "=", Arg(1))).ExecContext(ctx, tx)
+		return err
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings["app.bad name"] = ""
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	if err := WithTx(ctx, beginner, func(tx Execer) error { return nil }); !errors.Is(err, ErrSetting) {
+		t.Errorf("expected error %v, got %v\n", ErrSetting, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Explain(t *testing.T) {
+	q := Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(10)))
+
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{"EXPLAIN SELECT * FROM posts WHERE (user_id = $1)", Explain(q)},
+		{"EXPLAIN (ANALYZE, FORMAT JSON) SELECT * FROM posts WHERE (user_id = $1)", Explain(q, Analyze(), FormatJSON())},
+		{"EXPLAIN (ANALYZE, VERBOSE, BUFFERS) SELECT * FROM posts WHERE (user_id = $1)", Explain(q, Analyze(), Verbose(), Buffers())},
+		{"EXPLAIN SELECT * FROM posts WHERE (user_id = ?)", Explain(Select(Columns("*"), From("posts"), WithDialect(MySQL), Where("user_id", "=", Arg(10))))},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if !reflect.DeepEqual(test.q.Args(), []interface{}{10}) {
+			t.Errorf("tests[%d]: unexpected args %v\n", i, test.q.Args())
+		}
+	}
+
+	if _, err := Explain(Select(Columns("*"), From("posts"), Where("id", "==", Arg(1)))).BuildErr(); !errors.Is(err, ErrOperator) {
+		t.Errorf("expected error %v, got %v\n", ErrOperator, err)
+	}
+
+	plan := `[{
+		"Plan": {
+			"Node Type": "Index Scan",
+			"Relation Name": "posts",
+			"Index Name": "posts_user_id_idx",
+			"Startup Cost": 0.29,
+			"Total Cost": 8.3,
+			"Plan Rows": 1,
+			"Plan Width": 64,
+			"Actual Rows": 1,
+			"Actual Loops": 1
+		},
+		"Planning Time": 0.1,
+		"Execution Time": 0.05
+	}]`
+
+	explained, err := DecodePlan([]byte(plan))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Explained{
+		Plan: Plan{
+			NodeType:     "Index Scan",
+			RelationName: "posts",
+			IndexName:    "posts_user_id_idx",
+			StartupCost:  0.29,
+			TotalCost:    8.3,
+			PlanRows:     1,
+			PlanWidth:    64,
+			ActualRows:   1,
+			ActualLoops:  1,
+		},
+		PlanningTime:  0.1,
+		ExecutionTime: 0.05,
+	}
+
+	if !reflect.DeepEqual(explained, expected) {
+		t.Errorf("unexpected plan\n\texpected = %+v\n\tgot      = %+v\n", expected, explained)
+	}
+}
+
+type postStatus int
+
+func (s postStatus) String() string {
+	if s == 1 {
+		return "open"
+	}
+	return "closed"
+}
+
+func Test_DebugString(t *testing.T) {
+	created := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	var (
+		userID    int64 = 10
+		nullTitle *string
+	)
+
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (user_id = 10 AND title = 'It''s here')",
+			Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(10)), Where("title", "=", Arg("It's here"))),
+		},
+		{
+			"SELECT * FROM posts WHERE (id IN (1, 2, 3) AND created_at > '2021-03-04T05:06:07Z'::timestamptz)",
+			Select(Columns("*"), From("posts"), Where("id", "IN", List(1, 2, 3)), Where("created_at", ">", Arg(created))),
+		},
+		{
+			`UPDATE posts SET title = E'C:\\temp', deleted_at = NULL WHERE (id = 1)`,
+			Update("posts", Set("title", Arg(`C:\temp`)), Set("deleted_at", Arg(sql.NullTime{})), Where("id", "=", Arg(1))),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = 10 OR author_id = 10)",
+			Select(Columns("*"), From("posts"), Dedupe(), Where("user_id", "=", Arg(10)), OrWhere("author_id", "=", Arg(10))),
+		},
+		{
+			"SELECT * FROM posts WHERE (published = 1 AND tags = '[a b]')",
+			Select(Columns("*"), From("posts"), WithDialect(Oracle), Where("published", "=", Arg(true)), Where("tags", "=", Arg([]string{"a", "b"}))),
+		},
+		{
+			"SELECT * FROM posts WHERE (title = NULL)",
+			Select(Columns("*"), From("posts"), Where("title", "=", Arg((*sql.NullString)(nil)))),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = 10 AND title = NULL)",
+			Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(&userID)), Where("title", "=", Arg(nullTitle))),
+		},
+		{
+			"SELECT * FROM posts WHERE (status = 1 AND created_at > '2021-03-04T05:06:07Z'::timestamptz)",
+			Select(Columns("*"), From("posts"), Where("status", "=", Arg(postStatus(1))), Where("created_at", ">", Arg(created))),
+		},
+		{
+			"UPDATE posts SET note = '?' WHERE (id = 1)",
+			Update("posts", Set("note", Lit("'?'")), Where("id", "=", Arg(1))),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.DebugString(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_GoString(t *testing.T) {
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			`query.Select(query.Columns("*"), query.From("posts"), query.Where("user_id", "=", query.Arg(10)), query.OrWhere("id", "IN", query.List(1, 2)), query.OrderDesc("created_at"), query.Limit(25))`,
+			Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(10)), OrWhere("id", "IN", List(1, 2)), OrderDesc("created_at"), Limit(25)),
+		},
+		{
+			`query.Insert("posts", query.Columns("user_id", "title"), query.Values(1, "Hello"), query.Returning("id"), query.WithDialect(query.SQLite))`,
+			Insert("posts", Columns("user_id", "title"), Values(1, "Hello"), Returning("id"), WithDialect(SQLite)),
+		},
+		{
+			`query.Update("posts", query.Set("title", query.Arg("Hello")), query.Set("views", query.Raw("views + ?", 1)), query.Where("id", "=", query.Named("id", 10)))`,
+			Update("posts", Set("title", Arg("Hello")), Incr("views", 1), Where("id", "=", Named("id", 10))),
+		},
+		{
+			`query.Select(query.Count("*"), query.From("posts"), query.Where("user_id", "IN", query.Select(query.Columns("id"), query.From("users"))), query.Where("deleted_at", "IS", query.Lit("NULL")))`,
+			Select(Count("*"), From("posts"), Where("user_id", "IN", Select(Columns("id"), From("users"))), NotDeleted()),
+		},
+		{
+			`query.Explain(query.Delete("posts", query.Dedupe()), query.Analyze())`,
+			Explain(Delete("posts", Dedupe()), Analyze()),
+		},
+		{
+			`query.Union(query.Select(query.Columns("id"), query.From("posts")), query.Select(query.Columns("id"), query.From("comments")))`,
+			Union(Select(Columns("id"), From("posts")), Select(Columns("id"), From("comments"))),
+		},
+		{
+			`query.Select(query.Columns("id"), query.From("posts p"), query.Qualify())`,
+			Select(Columns("id"), From("posts p"), Qualify()),
+		},
+		{
+			`query.Select(query.Columns("id"), query.From("posts"), query.WithDialect(query.SQLiteVersion("3.31.1")))`,
+			Select(Columns("id"), From("posts"), WithDialect(SQLiteVersion("3.31.1"))),
+		},
+		{
+			`query.Select(query.Columns("id"), query.From("posts"), query.WithDialect(query.MySQL.Quoted()))`,
+			Select(Columns("id"), From("posts"), WithDialect(MySQL.Quoted())),
+		},
+	}
+
+	for i, test := range tests {
+		if s := fmt.Sprintf("%#v", test.q); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %s\n\tgot      = %s\n", i, test.expected, s)
+		}
+
+		if s := fmt.Sprintf("%v", test.q); s != test.q.Build() {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.q.Build(), s)
+		}
+	}
+
+	Strict = true
+	defer func() { Strict = false }()
+
+	q := Select(Columns("*"), From("posts"), Set("title", Arg("foo")))
+
+	if expected, s := "<query error: query: clause not supported: SET in SELECT>", fmt.Sprint(q); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+}
+
+func Test_Hooks(t *testing.T) {
+	defer ClearHooks()
+
+	tenant := func(q Query) Query {
+		if q.Table() == "posts" {
+			return Where("tenant_id", "=", Arg(7))(q)
+		}
+		return q
+	}
+
+	tables := make([]string, 0)
+
+	observe := func(q Query) Query {
+		tables = append(tables, q.Table())
+		return q
+	}
+
+	limit := func(q Query) Query { return Limit(100)(q) }
+
+	AddHook(tenant)
+
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts p WHERE (user_id = $1 AND tenant_id = $2)",
+			[]interface{}{1, 7},
+			Select(Columns("*"), From("posts p"), Where("user_id", "=", Arg(1))),
+		},
+		{
+			"SELECT * FROM users WHERE (id IN (SELECT user_id FROM posts WHERE (tenant_id = $1)))",
+			[]interface{}{7},
+			Select(Columns("*"), From("users"), Where("id", "IN", Select(Columns("user_id"), From("posts")))),
+		},
+		{
+			"DELETE FROM users LIMIT 100",
+			[]interface{}{},
+			Delete("users", Hook(limit)),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	var b Builder
+
+	b.Hook(observe, limit)
+	b.Select(Columns("*")).Apply(From("comments"))
+
+	if expected, built := "SELECT * FROM comments LIMIT 100", b.Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	b.Reset()
+	b.Select(Columns("*")).Apply(From("users"))
+
+	if expected, built := "SELECT * FROM users LIMIT 100", b.Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+
+	if !reflect.DeepEqual(tables, []string{"comments", "users"}) {
+		t.Errorf("unexpected tables observed %v\n", tables)
+	}
+
+	ClearHooks()
+
+	if expected, built := "SELECT * FROM posts", Select(Columns("*"), From("posts")).Build(); built != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, built)
+	}
+}
+
+func Test_Audit(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("posts p"),
+		Where("user_id", "=", Arg(10)),
+		Where("title", "LIKE", Arg("%secret%")),
+		OrderDesc("created_at"),
+		Limit(5),
+	)
+
+	b, err := json.Marshal(q)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"stmt":"SELECT","table":"posts","sql":"SELECT * FROM posts p WHERE (user_id = $1 AND title LIKE $2) ORDER BY created_at DESC LIMIT 5","clauses":["FROM","WHERE","ORDER BY","LIMIT"],"arg_count":2}`
+
+	if string(b) != expected {
+		t.Errorf("\n\texpected = %s\n\tgot      = %s\n", expected, b)
+	}
+
+	b, err = json.Marshal(q.Audit(true))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(string(b), `"arg_count":2,"args":[10,"%secret%"]}`) {
+		t.Errorf("expected args in audit, got %s\n", b)
+	}
+
+	union := Union(Select(Columns("id"), From("posts")), Select(Columns("id"), From("comments")))
+
+	if a := union.Audit(false); a.Stmt != "UNION" || a.ArgCount != 0 {
+		t.Errorf("unexpected audit for union %+v\n", a)
+	}
+}
+
+func Test_Strict(t *testing.T) {
+	tests := []struct {
+		q   Query
+		err error
+	}{
+		{Select(Columns("*"), From("posts"), Where("id", "=", Arg(1)), Limit(1)), nil},
+		{Select(Columns("*"), From("posts"), Set("title", Arg("foo"))), ErrClause},
+		{Select(Columns("*"), From("posts"), Values(1, 2)), ErrClause},
+		{Select(Columns("*"), From("posts"), Returning("id")), ErrClause},
+		{Delete("posts", From("users")), ErrClause},
+		{Delete("posts", Limit(1)), ErrClause},
+		{Insert("posts", Columns("id"), Values(1), Where("id", "=", Arg(1))), ErrClause},
+		{Update("posts", Set("title", Arg("foo")), Hook(OrderAsc("id"))), ErrClause},
+		{Union(Select(Columns("id"), From("posts")), Select(Columns("id"), From("users"))), nil},
+		{Select(Columns("*"), From("posts"), Where("id", "=<", Arg(1))), ErrOperator},
+		{Select(Columns("*"), From("posts"), OrWhere("title", "LIKEE", Arg("foo"))), ErrOperator},
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	Strict = true
+	defer func() { Strict = false }()
+
+	for i, test := range tests {
+		if err := test.q.Err(); !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %v, got %v\n", i, test.err, err)
+		}
+
+		if err := test.q.Template().Err(); !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %v from Template, got %v\n", i, test.err, err)
+		}
+
+		if test.err != nil {
+			if _, err := test.q.ExecContext(ctx, db); !errors.Is(err, test.err) {
+				t.Errorf("tests[%d]: expected error %v from ExecContext, got %v\n", i, test.err, err)
+			}
+		}
+
+		if err := test.q.BuildTo(io.Discard); !errors.Is(err, test.err) {
+			t.Errorf("tests[%d]: expected error %v from BuildTo, got %v\n", i, test.err, err)
+		}
+
+		if s := test.q.Build(); (s == "") != (test.err != nil) {
+			t.Errorf("tests[%d]: unexpected query %q for error %v\n", i, s, test.err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Clauses(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("posts p"),
+		Where("p.user_id", "IN", Select(Columns("id"), From("users"), Where("org_id", "=", Arg(1)))),
+		OrWhereExpr(Raw("p.score > ?", 10)),
+		OrderDesc("created_at", "id"),
+		NotDeleted(),
+	)
+
+	type clause struct {
+		kind    string
+		table   string
+		columns []string
+		op      string
+		conj    string
+		subs    int
+		args    []interface{}
+	}
+
+	expected := []clause{
+		{"FROM", "posts", nil, "", "", 0, nil},
+		{"WHERE", "", []string{"p.user_id"}, "IN", "AND", 1, []interface{}{1}},
+		{"WHERE", "", nil, "", "OR", 0, []interface{}{10}},
+		{"WHERE", "", []string{"deleted_at"}, "IS", "AND", 0, nil},
+		{"ORDER BY", "", []string{"created_at", "id"}, "", "", 0, nil},
+	}
+
+	clauses := q.Clauses()
+
+	if len(clauses) != len(expected) {
+		t.Fatalf("expected %d clauses, got %d\n", len(expected), len(clauses))
+	}
+
+	for i, cl := range clauses {
+		args := cl.Args()
+
+		if len(args) == 0 {
+			args = nil
+		}
+
+		got := clause{cl.Kind(), cl.Table(), cl.Columns(), cl.Op(), cl.Conjunction(), len(cl.Subqueries()), args}
+
+		if !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("clauses[%d]:\n\texpected = %v\n\tgot      = %v\n", i, expected[i], got)
+		}
+	}
+
+	if stmt := q.Statement(); stmt != "SELECT" {
+		t.Errorf("expected statement SELECT, got %q\n", stmt)
+	}
+
+	if tables := q.Tables(); !reflect.DeepEqual(tables, []string{"posts", "users"}) {
+		t.Errorf("unexpected tables %v\n", tables)
+	}
+
+	u := Union(
+		Update("posts", Set("title", Arg("foo")), Where("id", "=", Select(Columns("post_id"), From("comments")))),
+		Select(Columns("id"), From("posts")),
+	)
+
+	if stmt := u.Statement(); stmt != "UNION" {
+		t.Errorf("expected statement UNION, got %q\n", stmt)
+	}
+
+	if tables := u.Tables(); !reflect.DeepEqual(tables, []string{"posts", "comments"}) {
+		t.Errorf("unexpected tables %v\n", tables)
+	}
+}
+
+func Test_Clone(t *testing.T) {
+	base := Select(
+		Columns("id", "title"),
+		From("posts"),
+		Where("id", "IN", List(1, 2)),
+		Where("user_id", "IN", Select(Columns("id"), From("users"), Where("org_id", "=", Arg(1)))),
+		OrderDesc("created_at"),
+	)
+
+	built := base.Build()
+	args := base.Args()
+
+	clone := base.Clone()
+
+	clone.exprs[0].(listExpr).items[0] = "email"
+	clone.clauses[1].(whereClause).right.(listExpr).args[0] = 3
+	sub := clone.clauses[2].(whereClause).right.(subqueryExpr).q
+	sub.clauses[1] = Where("org_id", "=", Arg(2))(Query{}).clauses[0]
+	clone.clauses[3].(orderClause).cols[0] = "updated_at"
+
+	if s := base.Build(); s != built {
+		t.Errorf("base query changed\n\texpected = %q\n\tgot      = %q\n", built, s)
+	}
+
+	if a := base.Args(); !reflect.DeepEqual(a, args) {
+		t.Errorf("base args changed\n\texpected = %v\n\tgot      = %v\n", args, a)
+	}
+
+	expected := "SELECT email, title FROM posts WHERE (id IN ($1, $2) AND user_id IN (SELECT id FROM users WHERE (org_id = $3))) ORDER BY updated_at DESC"
+
+	if s := clone.Build(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if a := clone.Args(); !reflect.DeepEqual(a, []interface{}{3, 2, 2}) {
+		t.Errorf("unexpected clone args %v\n", a)
+	}
+
+	page := Limit(25)(base.Clone())
+
+	if s := page.Build(); s != built+" LIMIT 25" {
+		t.Errorf("unexpected page query %q\n", s)
+	}
+}
+
+func Test_CountOf(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT COUNT(*) FROM posts WHERE (user_id = $1 AND deleted_at IS NULL)",
+			[]interface{}{10},
+			CountOf(Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(10)), OrderDesc("created_at"), Limit(25), Offset(50), NotDeleted())),
+		},
+		{
+			"SELECT COUNT(*) FROM posts WHERE ((created_at, id) < ($1, $2))",
+			[]interface{}{"2021", 3},
+			CountOf(Select(Columns("id", "title"), From("posts"), Seek([]OrderedColumn{Desc("created_at"), Desc("id")}, "2021", 3))),
+		},
+		{
+			"SELECT COUNT(*) FROM (SELECT DISTINCT user_id FROM posts WHERE (title LIKE $1)) AS count",
+			[]interface{}{"%foo%"},
+			CountOf(SelectDistinct(Columns("user_id"), From("posts"), Where("title", "LIKE", Arg("%foo%")), OrderAsc("user_id"))),
+		},
+		{
+			"SELECT COUNT(*) FROM (SELECT id FROM posts WHERE (user_id = $1) UNION SELECT id FROM comments WHERE (user_id = $2)) AS count",
+			[]interface{}{1, 1},
+			CountOf(Union(
+				Select(Columns("id"), From("posts"), Where("user_id", "=", Arg(1))),
+				Select(Columns("id"), From("comments"), Where("user_id", "=", Arg(1))),
+			)),
+		},
+		{
+			"SELECT COUNT(*) FROM (SELECT user_id FROM posts WHERE (draft = $1) GROUP BY user_id) AS count",
+			[]interface{}{false},
+			CountOf(Select(Columns("user_id"), From("posts"), Where("draft", "=", Arg(false)), GroupBy("user_id"), OrderAsc("user_id"))),
+		},
+		{
+			"SELECT COUNT(*) FROM posts WHERE (user_id = @p1)",
+			[]interface{}{10},
+			CountOf(Select(Columns("*"), From("posts"), WithDialect(SQLServer), Where("user_id", "=", Arg(10)), Limit(10))),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+}
+
+func Test_Append(t *testing.T) {
+	since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cte := Numbered("WITH recent AS (SELECT * FROM posts WHERE created_at > $1 AND (user_id = $2 OR author_id = $2))", since, 10)
+
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"WITH recent AS (SELECT * FROM posts WHERE created_at > $1 AND (user_id = $2 OR author_id = $3)) SELECT * FROM recent WHERE (title LIKE $4)",
+			[]interface{}{since, 10, 10, "%foo%"},
+			Append(" ", cte, Select(Columns("*"), From("recent"), Where("title", "LIKE", Arg("%foo%")))),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1) UNION ALL SELECT * FROM archived_posts WHERE user_id = $2",
+			[]interface{}{1, 1},
+			Append(" UNION ALL ", Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(1))), Numbered("SELECT * FROM archived_posts WHERE user_id = $1", 1)),
+		},
+		{
+			"SELECT '$1', \"$2\" FROM t WHERE a = ? AND b = ?",
+			[]interface{}{"b", "a"},
+			WithDialect(MySQL)(Append("", Numbered(`SELECT '$1', "$2" FROM t WHERE a = $2 AND b = $1`, "a", "b"))),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	nested := Select(Columns("*"), From("posts"), Where("id", "IN", Append(" ", Numbered("SELECT post_id FROM likes WHERE user_id = $1", 5))))
+
+	if expected, s := "SELECT * FROM posts WHERE (id IN (SELECT post_id FROM likes WHERE user_id = $1))", nested.Build(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	defer func() {
+		if v := recover(); v == nil {
+			t.Errorf("expected Numbered to panic for out of range placeholder\n")
+		}
+	}()
+	Numbered("SELECT $3", 1, 2)
+}
+
+var (
+	optionClause = RegisterClause("OPTION")
+	windowClause = RegisterClause("WINDOW", "ORDER BY", "LIMIT")
+	indexClause  = RegisterClause("", "WHERE", "ORDER BY", "LIMIT")
+)
+
+func Test_RegisterClause(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (id = $1) OPTION (MAXDOP 1)",
+			[]interface{}{1},
+			Select(Columns("*"), From("posts"), AddClause(optionClause.Clause(Lit("(MAXDOP 1)"))), Where("id", "=", Arg(1))),
+		},
+		{
+			"SELECT * FROM posts USE INDEX (idx_user_id) FORCE INDEX (idx_created_at) WHERE (user_id = ?) LIMIT 10",
+			[]interface{}{10},
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("user_id", "=", Arg(10)),
+				Limit(10),
+				AddClause(indexClause.Clause(Lit("USE INDEX (idx_user_id)"))),
+				AddClause(indexClause.Clause(Lit("FORCE INDEX (idx_created_at)"))),
+				WithDialect(MySQL),
+			),
+		},
+		{
+			"SELECT SUM(size) OVER w, AVG(size) OVER w2 FROM objects WHERE (user_id = $1) WINDOW w AS (PARTITION BY type), w2 AS (PARTITION BY type ORDER BY size DESC) ORDER BY size DESC LIMIT 5",
+			[]interface{}{1},
+			Select(
+				Lit("SUM(size) OVER w, AVG(size) OVER w2"),
+				From("objects"),
+				AddClause(windowClause.Clause(Lit("w AS (PARTITION BY type)"))),
+				Where("user_id", "=", Arg(1)),
+				OrderDesc("size"),
+				AddClause(windowClause.Clause(Lit("w2 AS (PARTITION BY type ORDER BY size DESC)"))),
+				Limit(5),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (id IN (SELECT post_id FROM likes WHERE (user_id = $1))) OPTION (MAXDOP $2)",
+			[]interface{}{5, 1},
+			Select(
+				Columns("*"),
+				From("posts"),
+				AddClause(optionClause.Clause(Numbered("(MAXDOP $1)", 1))),
+				Where("id", "IN", Select(Columns("post_id"), From("likes"), Where("user_id", "=", Arg(5)))),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	kinds := make([]string, 0)
+
+	for _, cl := range tests[2].q.Clauses() {
+		kinds = append(kinds, cl.Kind())
+	}
+
+	if expected := []string{"FROM", "WHERE", "WINDOW", "WINDOW", "ORDER BY", "LIMIT"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected clauses %v, got %v\n", expected, kinds)
+	}
+
+	if fp := tests[3].q.Fingerprint(); fp != tests[3].q.Clone().Fingerprint() {
+		t.Errorf("expected cloned query to have the same fingerprint\n")
+	}
+
+	q := Select(
+		Lit("SUM(size) OVER w"),
+		Limit(5),
+		AddClause(optionClause.Clause(Lit("(MAXDOP 1)"))),
+		OrderDesc("size"),
+		AddClause(windowClause.Clause(Lit("w AS (PARTITION BY type)"))),
+		AddClause(indexClause.Clause(Lit("USE INDEX (idx_type)"))),
+		From("objects"),
+	)
+
+	if expected := "SELECT SUM(size) OVER w FROM objects USE INDEX (idx_type) WINDOW w AS (PARTITION BY type) ORDER BY size DESC LIMIT 5 OPTION (MAXDOP 1)"; q.Build() != expected {
+		t.Errorf("unexpected query\n\texpected = %q\n\tgot      = %q\n", expected, q.Build())
+	}
+
+	for _, fn := range []func(){
+		func() { RegisterClause("where") },
+		func() { RegisterClause("OPTION") },
+		func() { RegisterClause("QUALIFY", "HAVING") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected RegisterClause to panic\n")
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+type betweenExpr struct {
+	col      string
+	min, max interface{}
+}
+
+func (e betweenExpr) WriteSQL(w *SQLWriter) {
+	w.WriteIdent(e.col)
+	w.WriteString(" BETWEEN ")
+	w.WriteArg(e.min)
+	w.WriteString(" AND ")
+	w.WriteArg(e.max)
+}
+
+func (e betweenExpr) Build() string {
+	s, _ := WriteSQL(e, nil)
+	return s
+}
+
+func (e betweenExpr) Args() []interface{} {
+	_, args := WriteSQL(e, nil)
+	return args
+}
+
+func Test_SQLWriter(t *testing.T) {
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND id BETWEEN $2 AND $3)",
+			[]interface{}{1, 10, 20},
+			Select(Columns("*"), From("posts"), Where("user_id", "=", Arg(1)), WhereExpr(betweenExpr{"id", 10, 20})),
+		},
+		{
+			"SELECT * FROM `posts` WHERE (`created_at` BETWEEN ? AND ?)",
+			[]interface{}{"2021-01-01", "2022-01-01"},
+			Select(Columns("*"), From("posts"), WhereExpr(betweenExpr{"created_at", "2021-01-01", "2022-01-01"}), WithDialect(MySQL), QuoteIdents()),
+		},
+		{
+			"SELECT * FROM posts WHERE (id IN (SELECT post_id FROM likes WHERE (created_at BETWEEN $1 AND $2)) AND user_id = $3)",
+			[]interface{}{1, 2, 3},
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("id", "IN", Select(Columns("post_id"), From("likes"), WhereExpr(betweenExpr{"created_at", 1, 2}))),
+				Where("user_id", "=", Arg(3)),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	w := NewSQLWriter(Oracle)
+	w.WriteExpr(betweenExpr{"size", 1, 2})
+	w.WriteString(" AND id IN ")
+	w.WriteSubquery(Select(Columns("id"), From("objects"), Where("user_id", "=", Arg(3))))
+
+	s, args := w.String(), w.Args()
+
+	if expected := "size BETWEEN ? AND ? AND id IN (SELECT id FROM objects WHERE (user_id = ?))"; s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if expected := []interface{}{1, 2, 3}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v\n", expected, args)
+	}
+}
+
+// countExpr is an SQLExpr that counts the number of times it is written.
+type countExpr struct {
+	n *int
+}
+
+func (e countExpr) WriteSQL(w *SQLWriter) {
+	*e.n++
+	w.WriteString("id = ")
+	w.WriteArg(1)
+}
+
+func (e countExpr) Build() string {
+	s, _ := WriteSQL(e, nil)
+	return s
+}
+
+func (e countExpr) Args() []interface{} {
+	_, args := WriteSQL(e, nil)
+	return args
+}
+
+func Test_WriteOnce(t *testing.T) {
+	var n, hooks int
+
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "IN", Select(Columns("id"), From("users"), WhereExpr(countExpr{&n}))),
+		Hook(func(q Query) Query {
+			hooks++
+			return q
+		}),
+	)
+
+	s, args, err := q.ToSql()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "SELECT * FROM posts WHERE (user_id IN (SELECT id FROM users WHERE (id = $1)))"; s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if !reflect.DeepEqual(args, []interface{}{1}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+
+	n, hooks = 0, 0
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectExec(s).WithArgs(1).WillReturnResult(mockdb.NewResult(0, 0))
+
+	if _, err := q.ExecContext(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 {
+		t.Errorf("expected expression to be written once, got %d\n", n)
+	}
+
+	if hooks != 1 {
+		t.Errorf("expected hook to be applied once, got %d\n", hooks)
+	}
+}
+
+func Test_BuildIndented(t *testing.T) {
+	tests := []struct {
+		expected string
+		q        Query
+	}{
+		{
+			`SELECT *
+FROM posts
+WHERE (id IN (
+    SELECT post_id
+    FROM likes
+    WHERE (user_id = $1 AND created_at > NOW() - INTERVAL '1 day FROM now')
+))
+ORDER BY created_at DESC
+LIMIT 10`,
+			Select(
+				Columns("*"),
+				From("posts"),
+				Where("id", "IN", Select(
+					Columns("post_id"),
+					From("likes"),
+					Where("user_id", "=", Arg(10)),
+					WhereExpr(Lit("created_at > NOW() - INTERVAL '1 day FROM now'")),
+				)),
+				OrderDesc("created_at"),
+				Limit(10),
+			),
+		},
+		{
+			`SELECT EXTRACT(YEAR FROM created_at)
+FROM posts
+WHERE (title IS DISTINCT FROM $1)
+UNION
+SELECT EXTRACT(YEAR FROM created_at)
+FROM archived_posts`,
+			Union(
+				Select(Lit("EXTRACT(YEAR FROM created_at)"), From("posts"), Where("title", "IS DISTINCT FROM", Arg("foo"))),
+				Select(Lit("EXTRACT(YEAR FROM created_at)"), From("archived_posts")),
+			),
+		},
+		{
+			`INSERT INTO posts (user_id, title)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET title = EXCLUDED.title
+RETURNING id`,
+			Insert("posts", Columns("user_id", "title"), Values(1, "foo"), OnConflictUpdate([]string{"user_id"}, "title"), Returning("id")),
+		},
+		{
+			`DELETE FROM posts
+WHERE (id = $1)`,
+			Delete("posts", Where("id", "=", Arg(1))),
+		},
+		{
+			`SELECT *
+FROM posts
+ORDER BY (SELECT NULL)
+OFFSET 0 ROWS
+FETCH NEXT 5 ROWS ONLY`,
+			Select(Columns("*"), From("posts"), Limit(5), WithDialect(SQLServer)),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.BuildIndented(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		sql      string
+		args     []interface{}
+		expected string
+	}{
+		{
+			"SELECT * FROM posts WHERE user_id = $1 AND title LIKE $2 ORDER BY created_at DESC LIMIT 10",
+			[]interface{}{1, "%foo%"},
+			"SELECT * FROM posts WHERE (user_id = $1 AND title LIKE $2) ORDER BY created_at DESC LIMIT 10",
+		},
+		{
+			"select id, title from posts where id in (select post_id from likes where user_id = ?) or author_id = ?;",
+			[]interface{}{1, 2},
+			"SELECT id, title FROM posts WHERE (id IN (SELECT post_id FROM likes WHERE (user_id = $1)) OR author_id = $2)",
+		},
+		{
+			"SELECT COUNT(*), EXTRACT(YEAR FROM created_at) FROM posts p JOIN users u ON u.id = p.user_id WHERE p.created_at BETWEEN $1 AND $2 AND u.name IS DISTINCT FROM $3 OR u.id = $1",
+			[]interface{}{"2021-01-01", "2022-01-01", "foo"},
+			"SELECT COUNT(*), EXTRACT(YEAR FROM created_at) FROM posts p JOIN users u ON u.id = p.user_id WHERE (p.created_at BETWEEN $1 AND $2 AND u.name IS DISTINCT FROM $3 OR u.id = $4)",
+		},
+		{
+			"SELECT DISTINCT ON (user_id) user_id, title FROM posts WHERE id = ANY ($1) AND deleted_at IS NULL LIMIT $2 OFFSET $3",
+			[]interface{}{"{1,2}", 10, 20},
+			"SELECT DISTINCT ON (user_id) user_id, title FROM posts WHERE (id = ANY ($1) AND deleted_at IS NULL) LIMIT 10 OFFSET 20",
+		},
+		{
+			"SELECT * FROM posts WHERE id IN ($1, $2) UNION SELECT * FROM archived_posts WHERE id IN ($2)",
+			[]interface{}{1, 2},
+			"SELECT * FROM posts WHERE (id IN ($1, $2)) UNION SELECT * FROM archived_posts WHERE (id IN ($3))",
+		},
+		{
+			"INSERT INTO posts (user_id, title, created_at) VALUES ($1, $2, NOW()), ($3, lower($4), NOW()) ON CONFLICT (user_id) DO UPDATE SET title = EXCLUDED.title RETURNING id",
+			[]interface{}{1, "foo", 2, "BAR"},
+			"INSERT INTO posts (user_id, title, created_at) VALUES ($1, $2, NOW()), ($3, lower($4), NOW()) ON CONFLICT (user_id) DO UPDATE SET title = EXCLUDED.title RETURNING id",
+		},
+		{
+			"UPDATE posts SET title = $1, views = views + 1, user_id = (SELECT id FROM users WHERE email = $2) WHERE id = $3 RETURNING id, title",
+			[]interface{}{"foo", "me@example.com", 10},
+			"UPDATE posts SET title = $1, views = views + 1, user_id = (SELECT id FROM users WHERE (email = $2)) WHERE (id = $3) RETURNING id, title",
+		},
+		{
+			"DELETE FROM posts WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = posts.user_id) -- orphans",
+			nil,
+			"DELETE FROM posts WHERE (NOT EXISTS (SELECT 1 FROM users WHERE users.id = posts.user_id))",
+		},
+		{
+			"SELECT * FROM posts WHERE title = 'it''s ?' AND user_id = ?",
+			[]interface{}{10},
+			"SELECT * FROM posts WHERE (title = 'it''s ?' AND user_id = $1)",
+		},
+		{
+			"SELECT user_id, date_trunc('day', created_at), COUNT(*) FROM posts WHERE published = $1 GROUP BY user_id, date_trunc('day', created_at) ORDER BY user_id ASC",
+			[]interface{}{true},
+			"SELECT user_id, date_trunc('day', created_at), COUNT(*) FROM posts WHERE (published = $1) GROUP BY user_id, date_trunc('day', created_at) ORDER BY user_id ASC",
+		},
+	}
+
+	for i, test := range tests {
+		q, err := Parse(test.sql, test.args...)
+
+		if err != nil {
+			t.Errorf("tests[%d]: unexpected error: %s\n", i, err)
+			continue
+		}
+
+		if s := q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := q.Args(); len(args) != countParams(q.BuildWith(Question)) {
+			t.Errorf("tests[%d]: unexpected args %v\n", i, args)
+		}
+	}
+
+	q, err := Parse("SELECT * FROM posts WHERE user_id = $1 AND id IN (SELECT post_id FROM likes WHERE user_id = $1)", 10)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q = Options(Where("published", "=", Arg(true)), Limit(5))(q)
+
+	if expected, s := "SELECT * FROM posts WHERE (user_id = $1 AND id IN (SELECT post_id FROM likes WHERE (user_id = $2)) AND published = $3) LIMIT 5", q.Build(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if expected := []interface{}{10, 10, true}; !reflect.DeepEqual(q.Args(), expected) {
+		t.Errorf("expected args %v, got %v\n", expected, q.Args())
+	}
+
+	if expected := []string{"posts", "likes"}; !reflect.DeepEqual(q.Tables(), expected) {
+		t.Errorf("expected tables %v, got %v\n", expected, q.Tables())
+	}
+
+	errtests := []struct {
+		sql string
+		err error
+	}{
+		{"SELECT * FROM posts WHERE id = $2", ErrSyntax},
+		{"SELECT * FROM posts WHERE title = 'foo", ErrSyntax},
+		{"SELECT * FROM posts LIMIT 'foo'", ErrSyntax},
+		{"SELECT * FROM posts WHERE id = $1)", ErrSyntax},
+		{"DROP TABLE posts", ErrSyntax},
+		{"SELECT user_id, COUNT(*) FROM posts GROUP BY user_id HAVING COUNT(*) > 1", ErrUnsupported},
+		{"WITH p AS (SELECT * FROM posts) SELECT * FROM p", ErrUnsupported},
+		{"SELECT * FROM posts UNION ALL SELECT * FROM archived_posts", ErrUnsupported},
+		{"INSERT INTO posts SELECT * FROM archived_posts", ErrUnsupported},
+	}
+
+	for i, test := range errtests {
+		if _, err := Parse(test.sql, 1); !errors.Is(err, test.err) {
+			t.Errorf("errtests[%d]: expected error %q, got %v\n", i, test.err, err)
+		}
+	}
+}
+
+func benchmarkInsert(rows int) Query {
+	opts := make([]Option, 0, rows)
+
+	for i := 0; i < rows; i++ {
+		opts = append(opts, Values(i, "title", "body", true))
+	}
+	return Insert("posts", Columns("id", "title", "body", "published"), opts...)
+}
+
+func Benchmark_Build(b *testing.B) {
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Arg(1)),
+		Where("title", "LIKE", Arg("%foo%")),
+		OrderDesc("created_at"),
+		Limit(25),
+	)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		q.Build()
+	}
+}
+
+func Benchmark_BuildInsert(b *testing.B) {
+	q := benchmarkInsert(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		q.Build()
+	}
+}
+
+// Benchmark_BuildWideInsert builds an INSERT that is too large for its buffer
+// to be put back into the pool, so the buffer is grown on every build.
+func Benchmark_BuildWideInsert(b *testing.B) {
+	q := benchmarkInsert(10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		q.Build()
+	}
+}
+
+func Benchmark_Builder(b *testing.B) {
+	var qb Builder
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		qb.Reset()
+		qb.Select(Columns("*")).Apply(
+			From("posts"),
+			Where("user_id", "=", Arg(i)),
+			Where("title", "LIKE", Arg("%query%")),
+			OrderDesc("created_at"),
+			Limit(25),
+		)
+		qb.Build()
+	}
+}
+
+func Benchmark_Args(b *testing.B) {
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Arg(1)),
+		Where("title", "LIKE", Arg("%foo%")),
+		Where("id", "IN", List(1, 2, 3)),
+		OrderDesc("created_at"),
+		Limit(25),
+	)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		q.Args()
+	}
+}
+
+func Benchmark_Rebind(b *testing.B) {
+	s := benchmarkInsert(1000).BuildWith(Question)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Rebind(s, Dollar)
+	}
+}
+
+func Benchmark_AppendBuild(b *testing.B) {
+	q := benchmarkInsert(1000)
+	buf := make([]byte, 0, 1<<16)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		buf = q.AppendBuild(buf[:0])
+	}
+}
+
+func Benchmark_CacheBuild(b *testing.B) {
+	var cache Cache
+
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Where("user_id", "=", Arg(1)),
+		Where("title", "LIKE", Arg("%query%")),
+		OrderDesc("created_at"),
+		Limit(25),
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cache.Build(q)
+	}
+}
+
+func Test_Column(t *testing.T) {
+	var (
+		id      = Column[int64]("id")
+		userID  = Column[int64]("user_id")
+		status  = Column[string]("status")
+		pos     = Column[int]("position")
+		deleted = Column[time.Time]("deleted_at")
+	)
+
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND status IN ($2, $3) AND deleted_at IS NULL)",
+			[]interface{}{int64(10), "draft", "review"},
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereExpr(userID.Eq(10)),
+				WhereExpr(status.In("draft", "review")),
+				WhereExpr(deleted.IsNull()),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (id != $1 OR id >= $2) AND (status NOT IN ($3))",
+			[]interface{}{int64(1), int64(5), "archived"},
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereExpr(id.Ne(1)),
+				OrWhereExpr(id.Ge(5)),
+				WhereExpr(status.NotIn("archived")),
+			),
+		},
+		{
+			"UPDATE posts SET status = $1 WHERE (id = $2) RETURNING id",
+			[]interface{}{"published", int64(3)},
+			Update("posts", status.Set("published"), WhereExpr(id.Eq(3)), Returning(id.Name())),
+		},
+		{
+			"SELECT * FROM tasks WHERE (position < ? AND deleted_at IS NOT NULL)",
+			[]interface{}{3},
+			Select(
+				Columns("*"),
+				From("tasks"),
+				WhereExpr(pos.Lt(3)),
+				WhereExpr(deleted.IsNotNull()),
+				WithDialect(MySQL),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (1 = 0 AND 1 = 1 AND user_id = $1)",
+			[]interface{}{int64(10)},
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereExpr(status.In()),
+				WhereExpr(status.NotIn()),
+				WhereExpr(userID.Eq(10)),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+}
+
+func Test_Op(t *testing.T) {
+	tests := []struct {
+		op    Op
+		valid bool
+	}{
+		{OpEq, true},
+		{OpIsNotDistinctFrom, true},
+		{OpEqAny, true},
+		{"not ilike", true},
+		{" IN ", true},
+		{"=<", false},
+		{"==", false},
+		{"= 1 OR 1 = 1 --", false},
+	}
+
+	for i, test := range tests {
+		if valid := test.op.Valid(); valid != test.valid {
+			t.Errorf("tests[%d]: expected Valid for %q to be %v, got %v\n", i, test.op, test.valid, valid)
+		}
+	}
+
+	op := ">"
+
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		WhereOp("title", OpILike, Arg("%foo%")),
+		WhereOp("tags", OpContains, Arg("{go}")),
+		OrWhereOp("deleted_at", OpIsNot, Lit("NULL")),
+		Where("score", op, Arg(10)),
+	)
+
+	expected := "SELECT * FROM posts WHERE (title ILIKE $1 AND tags @> $2) OR (deleted_at IS NOT NULL) AND (score > $3)"
+
+	if s := q.Build(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if err := q.Err(); err != nil {
+		t.Errorf("unexpected error %v\n", err)
+	}
+
+	if err := Select(Columns("*"), From("posts"), Where("id", "=<", Arg(1))).Err(); !errors.Is(err, ErrOperator) {
+		t.Errorf("expected error %v, got %v\n", ErrOperator, err)
+	}
+}
+
+func Test_Mapper(t *testing.T) {
+	type User struct {
+		ID        int64 `db:",readonly"`
+		Email     string
+		FullName  string    `db:"name,omitempty"`
+		CreatedAt time.Time `db:",readonly"`
+		Password  string    `db:"-"`
+	}
+
+	u := User{
+		ID:       1,
+		Email:    "me@example.com",
+		Password: "secret",
+	}
+
+	snake := NewMapper(nil)
+	upper := NewMapper(strings.ToUpper)
+
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"INSERT INTO users (email) VALUES ($1) RETURNING id, created_at",
+			[]interface{}{"me@example.com"},
+			snake.InsertStruct("users", u, Returning("id", "created_at")),
+		},
+		{
+			"UPDATE users SET email = $1 WHERE (id = $2)",
+			[]interface{}{"me@example.com", 1},
+			snake.UpdateStruct("users", u, Where("id", "=", Arg(1))),
+		},
+		{
+			"SELECT * FROM users WHERE (ID = $1 AND EMAIL = $2)",
+			[]interface{}{int64(1), "me@example.com"},
+			Select(Columns("*"), From("users"), upper.WhereStruct(u)),
+		},
+		{
+			"UPDATE users SET name = $1 WHERE (id = $2)",
+			[]interface{}{"me", 1},
+			Update("users", snake.SetStruct(User{FullName: "me"}, "name"), Where("id", "=", Arg(1))),
+		},
+		{
+			"INSERT INTO users (name) VALUES ($1)",
+			[]interface{}{"me"},
+			InsertStruct("users", User{FullName: "me"}),
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	qq := snake.BulkInsertStruct("users", []User{{Email: "a@example.com"}, {Email: "b@example.com", FullName: "b"}})
+
+	if expected, s := "INSERT INTO users (email, name) VALUES ($1, $2), ($3, $4)", qq[0].Build(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if expected := "http_status"; SnakeCase("HTTPStatus") != expected {
+		t.Errorf("expected %q, got %q\n", expected, SnakeCase("HTTPStatus"))
+	}
+}
+
+var (
+	accountsTable = RegisterTable(TableDef{
+		Name:       "accounts",
+		Columns:    []string{"id", "email", "display_name", "created_at", "archived_at"},
+		PrimaryKey: []string{"id"},
+		SoftDelete: "archived_at",
+		NameMapper: SnakeCase,
+	})
+
+	eventsTable = RegisterTable(TableDef{
+		Name:       "events",
+		PrimaryKey: []string{"id"},
+	})
+)
+
+func Test_RegisterTable(t *testing.T) {
+	type Account struct {
+		ID          int64
+		Email       string
+		DisplayName string
+		Password    string
+		CreatedAt   time.Time `db:",readonly"`
+	}
+
+	type Event struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	acct := Account{
+		Email:       "me@example.com",
+		DisplayName: "me",
+		Password:    "secret",
+	}
+
+	tests := []struct {
+		expected string
+		args     []interface{}
+		q        Query
+	}{
+		{
+			"INSERT INTO accounts (email, display_name) VALUES ($1, $2)",
+			[]interface{}{"me@example.com", "me"},
+			InsertStruct("accounts", acct),
+		},
+		{
+			"INSERT INTO accounts (id, email, display_name) VALUES ($1, $2, $3)",
+			[]interface{}{int64(7), "me@example.com", "me"},
+			InsertStruct("accounts", Account{ID: 7, Email: "me@example.com", DisplayName: "me"}),
+		},
+		{
+			"UPDATE accounts SET email = $1, display_name = $2 WHERE (id = $3)",
+			[]interface{}{"me@example.com", "me", 7},
+			UpdateStruct("accounts", Account{ID: 7, Email: "me@example.com", DisplayName: "me"}, Where("id", "=", Arg(7))),
+		},
+		{
+			"SELECT id, email, display_name, created_at, archived_at FROM accounts WHERE (email = $1 AND archived_at IS NULL)",
+			[]interface{}{"me@example.com"},
+			accountsTable.Select(WhereStruct(Account{Email: "me@example.com"}), NotDeleted()),
+		},
+		{
+			"SELECT COUNT(*) FROM accounts WHERE (email = $1 AND archived_at IS NULL)",
+			[]interface{}{"me@example.com"},
+			accountsTable.Count(WhereStruct(Account{Email: "me@example.com"}), NotDeleted(), Limit(25)),
+		},
+		{
+			"SELECT COUNT(*) FROM accounts WHERE (email = $1 AND archived_at IS NULL)",
+			[]interface{}{"me@example.com"},
+			CountOf(Select(Columns("*"), From("accounts"), Where("email", "=", Arg("me@example.com")), OrderDesc("id"))),
+		},
+		{
+			"SELECT COUNT(*) FROM accounts a WHERE (a.archived_at IS NOT NULL)",
+			[]interface{}{},
+			CountOf(Select(Columns("*"), From("accounts a"), Where("a.archived_at", "IS NOT", Lit("NULL")))),
+		},
+		{
+			"SELECT COUNT(*) FROM (SELECT DISTINCT email FROM accounts WHERE (archived_at IS NULL)) AS count",
+			[]interface{}{},
+			CountOf(SelectDistinct(Columns("email"), From("accounts"))),
+		},
+		{
+			"UPDATE accounts SET archived_at = NOW() WHERE (id = $1)",
+			[]interface{}{7},
+			SoftDelete("accounts", Where("id", "=", Arg(7))),
+		},
+		{
+			"SELECT * FROM events WHERE (name = $1)",
+			[]interface{}{"signup"},
+			eventsTable.Select(Where("name", "=", Arg("signup")), NotDeleted()),
+		},
+		{
+			"INSERT INTO events (name) VALUES ($1), ($2)",
+			[]interface{}{"signup", "login"},
+			BulkInsertStruct("events", []Event{{Name: "signup"}, {Name: "login"}})[0],
+		},
+	}
+
+	for i, test := range tests {
+		if s := test.q.Build(); s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if args := test.q.Args(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, args)
+		}
+	}
+
+	if err := SoftDelete("events", Where("id", "=", Arg(1))).Err(); !errors.Is(err, ErrSoftDelete) {
+		t.Errorf("expected error %v, got %v\n", ErrSoftDelete, err)
+	}
+
+	if def, ok := LookupTable("accounts a"); !ok || def.SoftDelete != "archived_at" {
+		t.Errorf("expected accounts to be registered, got %+v\n", def)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected RegisterTable to panic for duplicate table\n")
+		}
+	}()
+	RegisterTable(TableDef{Name: "accounts"})
+}
+
+func Test_ReturningInto(t *testing.T) {
+	type User struct {
+		ID        int64     `db:"id,readonly"`
+		Email     string    `db:"email"`
+		CreatedAt time.Time `db:"created_at,readonly"`
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO users (email) VALUES ($1) RETURNING id, email, created_at").
+		WithArgs("me@example.com").
+		WillReturnRows(mockdb.NewRows([]string{"id", "email", "created_at"}).AddRow(1, "me@example.com", now))
+
+	mock.ExpectQuery("UPDATE users SET email = $1 WHERE (id = $2) RETURNING id, email, created_at").
+		WithArgs("you@example.com", 2).
+		WillReturnRows(mockdb.NewRows([]string{"id", "email", "created_at"}))
+
+	u := User{Email: "me@example.com"}
+
+	if err := ReturningInto(ctx, db, InsertStruct("users", u, Returning("id")), &u); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (User{ID: 1, Email: "me@example.com", CreatedAt: now}); u != expected {
+		t.Errorf("expected %+v, got %+v\n", expected, u)
+	}
+
+	q := Update("users", Set("email", Arg("you@example.com")), Where("id", "=", Arg(2)))
+
+	if err := ReturningInto(ctx, db, q, &u); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected error %v, got %v\n", sql.ErrNoRows, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "INSERT INTO users (email) VALUES ($1) RETURNING id, email, created_at"
+
+	if s := InsertStruct("users", u, ReturningStruct(u)).Build(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+}
+
+func Test_Relation(t *testing.T) {
+	type Comment struct {
+		ID     int64  `db:"id"`
+		PostID int64  `db:"post_id"`
+		Body   string `db:"body"`
+	}
+
+	type User struct {
+		ID    int64  `db:"id"`
+		Email string `db:"email"`
+	}
+
+	type Post struct {
+		ID       int64
+		UserID   int64
+		Author   User
+		Comments []Comment
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	posts := []Post{{ID: 1, UserID: 10}, {ID: 2, UserID: 10}, {ID: 3, UserID: 11}}
+
+	mock.ExpectQuery("SELECT * FROM comments WHERE (post_id = ANY ($1)) ORDER BY id ASC").
+		WithArgs([]int64{1, 2, 3}).
+		WillReturnRows(mockdb.NewRows([]string{"id", "post_id", "body"}).AddRow(1, 1, "foo").AddRow(2, 3, "bar").AddRow(3, 1, "baz"))
+
+	mock.ExpectQuery("SELECT * FROM users WHERE (id = ANY ($1))").
+		WithArgs([]int64{10, 11}).
+		WillReturnRows(mockdb.NewRows([]string{"id", "email"}).AddRow(10, "me@example.com"))
+
+	err = LoadMany(ctx, db, HasMany("comments", "post_id"), posts,
+		func(p Post) int64 { return p.ID },
+		func(c Comment) int64 { return c.PostID },
+		func(p *Post, cc []Comment) { p.Comments = cc },
+		OrderAsc("id"),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LoadOne(ctx, db, BelongsTo("users", "id"), posts,
+		func(p Post) int64 { return p.UserID },
+		func(u User) int64 { return u.ID },
+		func(p *Post, u User) { p.Author = u },
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Post{
+		{ID: 1, UserID: 10, Author: User{10, "me@example.com"}, Comments: []Comment{{1, 1, "foo"}, {3, 1, "baz"}}},
+		{ID: 2, UserID: 10, Author: User{10, "me@example.com"}},
+		{ID: 3, UserID: 11, Comments: []Comment{{2, 3, "bar"}}},
+	}
+
+	if !reflect.DeepEqual(posts, expected) {
+		t.Errorf("unexpected posts\n\texpected = %+v\n\tgot      = %+v\n", expected, posts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ValuesRows(t *testing.T) {
+	q := Insert(
+		"users",
+		Columns("email", "username"),
+		ValuesRows([][]interface{}{
+			{"me@example.com", "me"},
+			{"you@example.com", "you"},
+		}),
+		Returning("id"),
+	)
+
+	if built := q.Build(); built != "INSERT INTO users (email, username) VALUES ($1, $2), ($3, $4) RETURNING id" {
+		t.Errorf("unexpected query %q\n", built)
+	}
+
+	if args := q.Args(); !reflect.DeepEqual(args, []interface{}{"me@example.com", "me", "you@example.com", "you"}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+
+	tests := []Query{
+		Insert("users", Columns("email", "username"), ValuesRows([][]interface{}{
+			{"me@example.com", "me"},
+			{"you@example.com"},
+		})),
+		Insert("users", Lit(""), ValuesRows([][]interface{}{
+			{"me@example.com", "me"},
+			{"you@example.com", "you", "secret"},
+		})),
+	}
+
+	for i, q := range tests {
+		if err := q.Err(); !errors.Is(err, ErrColumns) {
+			t.Errorf("tests[%d]: expected error %v, got %v\n", i, ErrColumns, err)
+		}
+	}
+
+	q = Insert("users", Columns("email", "username"), ValuesRows([][]interface{}{
+		{"me@example.com", "me"},
+		{"you@example.com"},
+		{"them@example.com", "them"},
+	}))
+
+	if err := q.Err(); !errors.Is(err, ErrColumns) {
+		t.Errorf("expected error %v, got %v\n", ErrColumns, err)
+	}
+
+	if built := q.Build(); built != "INSERT INTO users (email, username)" {
+		t.Errorf("unexpected query %q\n", built)
+	}
+
+	if args := q.Args(); len(args) != 0 {
+		t.Errorf("unexpected args %v\n", args)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// initialisms are the words that are written in upper case when converted to
+// a Go name.
+var initialisms = map[string]struct{}{
+	"API":  {},
+	"CSS":  {},
+	"DNS":  {},
+	"HTML": {},
+	"HTTP": {},
+	"ID":   {},
+	"IP":   {},
+	"JSON": {},
+	"SQL":  {},
+	"URI":  {},
+	"URL":  {},
+	"UUID": {},
+	"XML":  {},
+}
+
+// goName returns the exported Go name for the given name of a table or column,
+// for example, user_id would become UserID.
+func goName(name string) string {
+	var buf strings.Builder
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		if _, ok := initialisms[strings.ToUpper(word)]; ok {
+			buf.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+
+		buf.WriteString(string(r))
+	}
+
+	s := buf.String()
+
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// fieldNames returns the names of the fields for the columns of the given
+// table. The name of a column that would clash with the Table field, or with
+// another column, is given a numeric suffix.
+func fieldNames(t Table) []string {
+	seen := map[string]struct{}{
+		"Table": {},
+	}
+
+	names := make([]string, 0, len(t.Columns))
+
+	for _, col := range t.Columns {
+		name := goName(col.Name)
+
+		for i := 2; ; i++ {
+			if _, ok := seen[name]; !ok {
+				break
+			}
+			name = goName(col.Name) + strconv.Itoa(i)
+		}
+
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// generate returns the formatted Go source in the given package for the given
+// tables.
+func generate(pkg string, tables []Table) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by querygen. DO NOT EDIT.\n\n")
+	buf.WriteString("package " + pkg + "\n")
+
+	for _, t := range tables {
+		name := goName(t.Name)
+		fields := fieldNames(t)
+
+		buf.WriteString("\n// " + name + " is the " + t.Name + " table.\n")
+		buf.WriteString("var " + name + " = struct {\n")
+		buf.WriteString("Table string\n")
+
+		for i, col := range t.Columns {
+			buf.WriteString(fields[i] + " string // " + col.Type + "\n")
+		}
+
+		buf.WriteString("}{\n")
+		buf.WriteString("Table: " + strconv.Quote(t.Name) + ",\n")
+
+		for i, col := range t.Columns {
+			buf.WriteString(fields[i] + ": " + strconv.Quote(col.Name) + ",\n")
+		}
+		buf.WriteString("}\n")
+	}
+	return format.Source(buf.Bytes())
+}
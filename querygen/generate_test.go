@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func Test_goName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"users", "Users"},
+		{"user_id", "UserID"},
+		{"avatar_url", "AvatarURL"},
+		{"created_at", "CreatedAt"},
+		{"2fa_secret", "X2faSecret"},
+		{"Display Name", "DisplayName"},
+	}
+
+	for i, test := range tests {
+		if name := goName(test.name); name != test.expected {
+			t.Errorf("tests[%d] - unexpected name, expected=%q, got=%q\n", i, test.expected, name)
+		}
+	}
+}
+
+func Test_generate(t *testing.T) {
+	tables := []Table{
+		{
+			Name: "users",
+			Columns: []Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "email", Type: "text"},
+			},
+		},
+		{
+			Name: "post_tags",
+			Columns: []Column{
+				{Name: "post_id", Type: "bigint"},
+				{Name: "table", Type: "text"},
+				{Name: "Table", Type: "text"},
+			},
+		},
+	}
+
+	expected := `// Code generated by querygen. DO NOT EDIT.
+
+package schema
+
+// Users is the users table.
+var Users = struct {
+	Table string
+	ID    string // bigint
+	Email string // text
+}{
+	Table: "users",
+	ID:    "id",
+	Email: "email",
+}
+
+// PostTags is the post_tags table.
+var PostTags = struct {
+	Table  string
+	PostID string // bigint
+	Table2 string // text
+	Table3 string // text
+}{
+	Table:  "post_tags",
+	PostID: "post_id",
+	Table2: "table",
+	Table3: "Table",
+}
+`
+
+	src, err := generate("schema", tables)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(src) != expected {
+		t.Fatalf("unexpected source\n\texpected = %q\n\tgot      = %q\n", expected, string(src))
+	}
+}
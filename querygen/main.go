@@ -0,0 +1,94 @@
+// Command querygen introspects the tables of a PostgreSQL database, and
+// generates Go source with the names of each table and its columns, so the
+// names used when building queries are checked at compile time. For example,
+// a users table with an id and email column would result in the following,
+//
+//     // Users is the users table.
+//     var Users = struct {
+//         Table string
+//         ID    string // bigint
+//         Email string // text
+//     }{
+//         Table: "users",
+//         ID:    "id",
+//         Email: "email",
+//     }
+//
+// which can then be used like so,
+//
+//     q := query.Select(
+//         query.Columns(schema.Users.ID, schema.Users.Email),
+//         query.From(schema.Users.Table),
+//     )
+//
+// The database is connected to via the -dsn flag, or the DATABASE_URL
+// environment variable if not given. Usage,
+//
+//     querygen [-dsn url] [-schema name] [-pkg name] [-o file] [table...]
+//
+// If no tables are given, then all of the tables in the schema are generated.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func run(args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+
+	dsn := fs.String("dsn", os.Getenv("DATABASE_URL"), "the database to connect to")
+	schema := fs.String("schema", "public", "the schema of the tables")
+	pkg := fs.String("pkg", "schema", "the package of the generated source")
+	out := fs.String("o", "", "the file to write to, defaults to stdout")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return fmt.Errorf("no database given, set -dsn or DATABASE_URL")
+	}
+
+	db, err := sql.Open("pgx", *dsn)
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	tables, err := loadTables(context.Background(), db, *schema, fs.Args()...)
+
+	if err != nil {
+		return err
+	}
+
+	if len(tables) == 0 {
+		return fmt.Errorf("no tables found in schema %s", *schema)
+	}
+
+	src, err := generate(*pkg, tables)
+
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
+
+func main() {
+	if err := run(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+
+	"github.com/andrewpillar/query"
+)
+
+// Column is a column of a table that has been introspected.
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is a table that has been introspected.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// loadTables returns the tables in the given schema, along with their columns
+// in the order they were defined. If any table names are given, then only
+// those tables are returned.
+func loadTables(ctx context.Context, db query.Execer, schema string, names ...string) ([]Table, error) {
+	opts := []query.Option{
+		query.From("information_schema.columns"),
+		query.Where("table_schema", "=", query.Arg(schema)),
+	}
+
+	if len(names) > 0 {
+		args := make([]interface{}, 0, len(names))
+
+		for _, name := range names {
+			args = append(args, name)
+		}
+		opts = append(opts, query.Where("table_name", "IN", query.List(args...)))
+	}
+
+	opts = append(opts, query.OrderAsc("table_name", "ordinal_position"))
+
+	q := query.Select(query.Columns("table_name", "column_name", "data_type"), opts...)
+
+	rows, err := q.QueryContext(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tables := make([]Table, 0)
+
+	for rows.Next() {
+		var table string
+		var col Column
+
+		if err := rows.Scan(&table, &col.Name, &col.Type); err != nil {
+			return nil, err
+		}
+
+		if n := len(tables); n == 0 || tables[n-1].Name != table {
+			tables = append(tables, Table{Name: table})
+		}
+
+		t := &tables[len(tables)-1]
+		t.Columns = append(t.Columns, col)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
@@ -0,0 +1,10 @@
+module github.com/andrewpillar/query/querymock
+
+go 1.18
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/andrewpillar/query v0.0.0
+)
+
+replace github.com/andrewpillar/query => ../
@@ -0,0 +1,59 @@
+// Package querymock provides helpers for setting up the expectations of a
+// go-sqlmock database from a Query. This avoids duplicating the SQL of a
+// Query in tests, where it can drift out of sync with the Query itself, for
+// example,
+//
+//     db, mock, err := sqlmock.New()
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(10)),
+//     )
+//
+//     querymock.ExpectQuery(mock, q).WillReturnRows(rows)
+//
+// The built SQL of the Query is escaped via regexp.QuoteMeta, so it can be
+// used with the default regular expression matcher of go-sqlmock.
+package querymock
+
+import (
+	"database/sql/driver"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrewpillar/query"
+)
+
+// SQL returns the built SQL of the given Query escaped for use as a regular
+// expression.
+func SQL(q query.Query) string { return regexp.QuoteMeta(q.Build()) }
+
+// Args returns the arguments of the given Query as driver values, for use
+// with WithArgs.
+func Args(q query.Query) []driver.Value {
+	args := q.Args()
+	vals := make([]driver.Value, 0, len(args))
+
+	for _, arg := range args {
+		vals = append(vals, arg)
+	}
+	return vals
+}
+
+// ExpectQuery expects the given Query to be executed via Query or QueryRow,
+// with its arguments.
+func ExpectQuery(mock sqlmock.Sqlmock, q query.Query) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(SQL(q)).WithArgs(Args(q)...)
+}
+
+// ExpectExec expects the given Query to be executed via Exec, with its
+// arguments.
+func ExpectExec(mock sqlmock.Sqlmock, q query.Query) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(SQL(q)).WithArgs(Args(q)...)
+}
+
+// ExpectPrepare expects the given Query to be prepared.
+func ExpectPrepare(mock sqlmock.Sqlmock, q query.Query) *sqlmock.ExpectedPrepare {
+	return mock.ExpectPrepare(SQL(q))
+}
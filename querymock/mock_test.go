@@ -0,0 +1,70 @@
+package querymock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrewpillar/query"
+)
+
+func Test_Expect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	sel := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.Where("user_id", "=", query.Arg(10)),
+		query.Where("id", "IN", query.List(1, 2)),
+	)
+
+	del := query.Delete("posts", query.Where("id", "=", query.Arg(1)))
+
+	ExpectQuery(mock, sel).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	ExpectExec(mock, del).WillReturnResult(sqlmock.NewResult(0, 1))
+	ExpectPrepare(mock, sel).ExpectQuery().WithArgs(Args(sel)...).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rows, err := sel.QueryContext(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	if _, err := del.ExecContext(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	stmt, err := db.PrepareContext(ctx, sel.Build())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err = stmt.QueryContext(ctx, sel.Args()...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_SQL(t *testing.T) {
+	q := query.Select(query.Columns("*"), query.From("posts"), query.Where("id", "=", query.Arg(1)))
+
+	if expected, got := `SELECT \* FROM posts WHERE \(id = \$1\)`, SQL(q); got != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, got)
+	}
+}
@@ -0,0 +1,94 @@
+package querypgx
+
+import (
+	"context"
+
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v5"
+)
+
+// BatchSender is the interface that wraps the SendBatch method. This is
+// satisfied by *pgx.Conn, *pgxpool.Pool, *pgxpool.Conn, and pgx.Tx.
+type BatchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+var (
+	_ BatchSender = (*pgx.Conn)(nil)
+	_ BatchSender = (pgx.Tx)(nil)
+)
+
+// Queue builds up the given Query and queues it onto the given batch. The
+// queued query is returned so that a callback can be set for handling its
+// results, for example,
+//
+//     var b pgx.Batch
+//
+//     querypgx.Queue(&b, posts).Query(func(rows pgx.Rows) error {
+//         // Handle rows.
+//     })
+//
+//     querypgx.Queue(&b, count).QueryRow(func(row pgx.Row) error {
+//         return row.Scan(&n)
+//     })
+//
+//     err := pool.SendBatch(ctx, &b).Close()
+func Queue(b *pgx.Batch, q query.Query) *pgx.QueuedQuery {
+	sql, args := build(q)
+	return b.Queue(sql, args...)
+}
+
+// Batch returns a new batch with each of the given queries queued onto it.
+func Batch(queries ...query.Query) *pgx.Batch {
+	b := &pgx.Batch{
+		QueuedQueries: make([]*pgx.QueuedQuery, 0, len(queries)),
+	}
+
+	for _, q := range queries {
+		Queue(b, q)
+	}
+	return b
+}
+
+// SendBatch sends each of the given queries in a single batch via the given
+// BatchSender, so they are all sent to the database in a single round trip.
+// The results of the batch must be closed once they have been read.
+func SendBatch(ctx context.Context, db BatchSender, queries ...query.Query) pgx.BatchResults {
+	return db.SendBatch(ctx, Batch(queries...))
+}
+
+// Page sends the given SELECT query and its query.CountOf in a single batch via
+// the given BatchSender, collecting each row of the query with the given
+// function, and scanning the count for the total number of rows across all
+// pages, for example,
+//
+//     posts, total, err := querypgx.Page(ctx, pool, query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.OrderDesc("created_at"),
+//         query.Paginate(page, perPage),
+//     ), pgx.RowToStructByName[Post])
+func Page[T any](ctx context.Context, db BatchSender, q query.Query, fn pgx.RowToFunc[T]) ([]T, int64, error) {
+	var (
+		items []T
+		total int64
+	)
+
+	b := &pgx.Batch{}
+
+	Queue(b, q).Query(func(rows pgx.Rows) error {
+		var err error
+
+		items, err = pgx.CollectRows(rows, fn)
+		return err
+	})
+
+	Queue(b, query.CountOf(q)).QueryRow(func(row pgx.Row) error {
+		return row.Scan(&total)
+	})
+
+	if err := db.SendBatch(ctx, b).Close(); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
@@ -0,0 +1,36 @@
+package querypgx
+
+import (
+	"context"
+	"strings"
+
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v5"
+)
+
+// CopyFromer is the interface that wraps the CopyFrom method. This is
+// satisfied by *pgx.Conn, *pgxpool.Pool, *pgxpool.Conn, and pgx.Tx.
+type CopyFromer interface {
+	CopyFrom(ctx context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error)
+}
+
+var (
+	_ CopyFromer = (*pgx.Conn)(nil)
+	_ CopyFromer = (pgx.Tx)(nil)
+)
+
+// CopyFrom copies the given rows into the table of the given Copy via the
+// given CopyFromer, using the PostgreSQL copy protocol. The number of rows
+// copied is returned, for example,
+//
+//     n, err := querypgx.CopyFrom(ctx, pool, query.CopyIn("users", "email", "username"), rows)
+func CopyFrom(ctx context.Context, db CopyFromer, c query.Copy, rows [][]any) (int64, error) {
+	return CopyFromSource(ctx, db, c, pgx.CopyFromRows(rows))
+}
+
+// CopyFromSource copies the rows from the given source into the table of the
+// given Copy via the given CopyFromer. This should be used over CopyFrom when
+// the rows are streamed, rather than held in memory.
+func CopyFromSource(ctx context.Context, db CopyFromer, c query.Copy, src pgx.CopyFromSource) (int64, error) {
+	return db.CopyFrom(ctx, pgx.Identifier(strings.Split(c.Table(), ".")), c.Columns(), src)
+}
@@ -0,0 +1,60 @@
+package querypgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrParams is returned from Describe when the number of parameters in the
+// described statement does not match the number of arguments of the Query.
+var ErrParams = errors.New("parameter count mismatch")
+
+// Describer is the interface that wraps the Prepare method of a PostgreSQL
+// connection. This is satisfied by *pgconn.PgConn, which can be retrieved from
+// a *pgx.Conn via its PgConn method.
+type Describer interface {
+	Prepare(ctx context.Context, name, sql string, paramOIDs []uint32) (*pgconn.StatementDescription, error)
+}
+
+var _ Describer = (*pgconn.PgConn)(nil)
+
+// Describe checks the given Query against the given connection without
+// executing it. The Query is built via BuildErr, and then described by the
+// server via the unnamed prepared statement, so no statement is left on the
+// connection. The returned description contains the types of the parameters
+// and the fields of the rows the query would return. This is meant for
+// integration tests that check every query an application can build against
+// a test database, for example,
+//
+//     for _, q := range queries {
+//         if _, err := querypgx.Describe(ctx, conn.PgConn(), q); err != nil {
+//             t.Error(err)
+//         }
+//     }
+//
+// ErrParams is returned if the server expects a different number of
+// parameters than the Query has arguments.
+func Describe(ctx context.Context, conn Describer, q query.Query) (*pgconn.StatementDescription, error) {
+	q = query.WithDialect(query.Postgres)(q)
+
+	sql, err := q.BuildErr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := conn.Prepare(ctx, "", sql, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("querypgx: %s: %w", sql, err)
+	}
+
+	if n := len(q.Args()); len(sd.ParamOIDs) != n {
+		return sd, fmt.Errorf("querypgx: %s: %w: expected %d, got %d", sql, ErrParams, len(sd.ParamOIDs), n)
+	}
+	return sd, nil
+}
@@ -0,0 +1,80 @@
+// Package querypgx provides helpers for running queries built with the query
+// package on pgx connections, pools, and transactions, for example,
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(id)),
+//     )
+//
+//     rows, err := querypgx.Query(ctx, pool, q)
+//
+// The arguments of a Query are passed to pgx as they are, so any of the types
+// that pgx natively supports, such as the types in the pgtype package, can be
+// used as arguments.
+package querypgx
+
+import (
+	"context"
+
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the interface that wraps the methods for executing queries via
+// pgx. This is satisfied by *pgx.Conn, *pgxpool.Pool, *pgxpool.Conn, and
+// pgx.Tx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	_ Querier = (*pgx.Conn)(nil)
+	_ Querier = (pgx.Tx)(nil)
+)
+
+// build builds the given Query for PostgreSQL, regardless of the Dialect that
+// was set on the Query, since pgx only supports PostgreSQL.
+func build(q query.Query) (string, []any) {
+	q = query.WithDialect(query.Postgres)(q)
+	return q.Build(), q.Args()
+}
+
+// Exec builds up the given Query and executes it via the given Querier.
+func Exec(ctx context.Context, db Querier, q query.Query) (pgconn.CommandTag, error) {
+	sql, args := build(q)
+	return db.Exec(ctx, sql, args...)
+}
+
+// Query builds up the given Query and executes it via the given Querier,
+// returning the rows.
+func Query(ctx context.Context, db Querier, q query.Query) (pgx.Rows, error) {
+	sql, args := build(q)
+	return db.Query(ctx, sql, args...)
+}
+
+// QueryRow builds up the given Query and executes it via the given Querier,
+// returning at most one row.
+func QueryRow(ctx context.Context, db Querier, q query.Query) pgx.Row {
+	sql, args := build(q)
+	return db.QueryRow(ctx, sql, args...)
+}
+
+// CollectRows builds up the given Query and executes it via the given
+// Querier, collecting each row with the given function. This is a convenience
+// around pgx.CollectRows, for example,
+//
+//     posts, err := querypgx.CollectRows(ctx, pool, q, pgx.RowToStructByName[Post])
+func CollectRows[T any](ctx context.Context, db Querier, q query.Query, fn pgx.RowToFunc[T]) ([]T, error) {
+	rows, err := Query(ctx, db, q)
+
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, fn)
+}
@@ -0,0 +1,275 @@
+package querypgx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andrewpillar/query"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type call struct {
+	sql  string
+	args []any
+}
+
+type querier struct {
+	calls []call
+}
+
+type copied struct {
+	table pgx.Identifier
+	cols  []string
+	rows  [][]any
+}
+
+type copier struct {
+	copies []copied
+}
+
+type row struct{}
+
+func (r row) Scan(dest ...any) error { return nil }
+
+func (q *querier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.calls = append(q.calls, call{sql: sql, args: args})
+	return pgconn.NewCommandTag("DELETE 1"), nil
+}
+
+func (q *querier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.calls = append(q.calls, call{sql: sql, args: args})
+	return nil, nil
+}
+
+func (q *querier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.calls = append(q.calls, call{sql: sql, args: args})
+	return row{}
+}
+
+func (c *copier) CopyFrom(ctx context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error) {
+	rows := make([][]any, 0)
+
+	for src.Next() {
+		vals, err := src.Values()
+
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, vals)
+	}
+
+	c.copies = append(c.copies, copied{table: table, cols: cols, rows: rows})
+	return int64(len(rows)), src.Err()
+}
+
+func Test_Querier(t *testing.T) {
+	var db querier
+
+	ctx := context.Background()
+	title := pgtype.Text{String: "foo", Valid: true}
+
+	tag, err := Exec(ctx, &db, query.Delete("posts", query.Where("id", "=", query.Arg(1))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tag.Delete() {
+		t.Errorf("expected DELETE command tag, got %q\n", tag)
+	}
+
+	if _, err := Query(ctx, &db, query.Select(query.Columns("*"), query.From("posts"), query.Where("title", "=", query.Arg(title)))); err != nil {
+		t.Fatal(err)
+	}
+
+	q := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.WithDialect(query.MySQL),
+		query.Where("id", "=", query.Arg(2)),
+	)
+
+	if err := QueryRow(ctx, &db, q).Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []call{
+		{"DELETE FROM posts WHERE (id = $1)", []any{1}},
+		{"SELECT * FROM posts WHERE (title = $1)", []any{title}},
+		{"SELECT * FROM posts WHERE (id = $1)", []any{2}},
+	}
+
+	if !reflect.DeepEqual(db.calls, expected) {
+		t.Errorf("unexpected calls\n\texpected = %v\n\tgot      = %v\n", expected, db.calls)
+	}
+}
+
+func Test_Batch(t *testing.T) {
+	b := Batch(
+		query.Select(query.Columns("*"), query.From("posts"), query.Where("user_id", "=", query.Arg(1))),
+		query.Select(query.Count("*"), query.From("comments"), query.Where("post_id", "IN", query.List(2, 3))),
+	)
+
+	Queue(b, query.Update("users", query.Set("visited_at", query.Lit("NOW()")), query.Where("id", "=", query.Arg(1))))
+
+	expected := []call{
+		{"SELECT * FROM posts WHERE (user_id = $1)", []any{1}},
+		{"SELECT COUNT(*) FROM comments WHERE (post_id IN ($1, $2))", []any{2, 3}},
+		{"UPDATE users SET visited_at = NOW() WHERE (id = $1)", []any{1}},
+	}
+
+	if len(b.QueuedQueries) != len(expected) {
+		t.Fatalf("expected %d queued queries, got %d\n", len(expected), len(b.QueuedQueries))
+	}
+
+	for i, qq := range b.QueuedQueries {
+		got := call{sql: qq.SQL, args: qq.Arguments}
+
+		if !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("tests[%d]:\n\texpected = %v\n\tgot      = %v\n", i, expected[i], got)
+		}
+	}
+}
+
+func Test_CopyFrom(t *testing.T) {
+	var db copier
+
+	rows := [][]any{
+		{"me@example.com", "me"},
+		{"you@example.com", "you"},
+	}
+
+	n, err := CopyFrom(context.Background(), &db, query.CopyIn("public.users", "email", "username"), rows)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Errorf("expected 2 rows copied, got %d\n", n)
+	}
+
+	expected := []copied{
+		{pgx.Identifier{"public", "users"}, []string{"email", "username"}, rows},
+	}
+
+	if !reflect.DeepEqual(db.copies, expected) {
+		t.Errorf("unexpected copies\n\texpected = %v\n\tgot      = %v\n", expected, db.copies)
+	}
+}
+
+type describer struct {
+	params int
+	err    error
+}
+
+func (d describer) Prepare(ctx context.Context, name, sql string, paramOIDs []uint32) (*pgconn.StatementDescription, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	oids := make([]uint32, d.params)
+
+	for i := range oids {
+		oids[i] = pgtype.Int8OID
+	}
+
+	return &pgconn.StatementDescription{
+		Name:      name,
+		SQL:       sql,
+		ParamOIDs: oids,
+	}, nil
+}
+
+func Test_Describe(t *testing.T) {
+	ctx := context.Background()
+
+	q := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.WithDialect(query.MySQL),
+		query.Where("user_id", "=", query.Arg(1)),
+	)
+
+	sd, err := Describe(ctx, describer{params: 1}, q)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "SELECT * FROM posts WHERE (user_id = $1)"; sd.SQL != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, sd.SQL)
+	}
+
+	if _, err := Describe(ctx, describer{params: 2}, q); !errors.Is(err, ErrParams) {
+		t.Errorf("expected error %v, got %v\n", ErrParams, err)
+	}
+
+	errSyntax := errors.New("syntax error")
+
+	if _, err := Describe(ctx, describer{err: errSyntax}, q); !errors.Is(err, errSyntax) {
+		t.Errorf("expected error %v, got %v\n", errSyntax, err)
+	}
+
+	if _, err := Describe(ctx, describer{}, query.Select(query.Columns("*"))); !errors.Is(err, query.ErrFrom) {
+		t.Errorf("expected error %v, got %v\n", query.ErrFrom, err)
+	}
+}
+
+type batchResults struct {
+	pgx.BatchResults
+}
+
+func (r batchResults) Close() error { return nil }
+
+type batchSender struct {
+	batches []*pgx.Batch
+}
+
+func (s *batchSender) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	s.batches = append(s.batches, b)
+	return batchResults{}
+}
+
+func Test_Page(t *testing.T) {
+	var db batchSender
+
+	q := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.Where("user_id", "=", query.Arg(1)),
+		query.OrderDesc("created_at"),
+		query.Paginate(2, 10),
+	)
+
+	if _, _, err := Page(context.Background(), &db, q, pgx.RowToMap); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.batches) != 1 {
+		t.Fatalf("expected 1 batch, got %d\n", len(db.batches))
+	}
+
+	expected := []call{
+		{"SELECT * FROM posts WHERE (user_id = $1) ORDER BY created_at DESC LIMIT 10 OFFSET 10", []any{1}},
+		{"SELECT COUNT(*) FROM posts WHERE (user_id = $1)", []any{1}},
+	}
+
+	b := db.batches[0]
+
+	if len(b.QueuedQueries) != len(expected) {
+		t.Fatalf("expected %d queued queries, got %d\n", len(expected), len(b.QueuedQueries))
+	}
+
+	for i, qq := range b.QueuedQueries {
+		got := call{sql: qq.SQL, args: qq.Arguments}
+
+		if !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("tests[%d]:\n\texpected = %v\n\tgot      = %v\n", i, expected[i], got)
+		}
+	}
+}
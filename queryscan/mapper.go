@@ -0,0 +1,57 @@
+package queryscan
+
+import (
+	"sync"
+
+	"github.com/andrewpillar/query/internal/naming"
+)
+
+// NameMapper maps the name of a struct field to the name of a column.
+type NameMapper func(field string) string
+
+// Mapper scans rows into structs in the same way as the functions in the
+// package, only fields without a db tag are mapped to columns too, via a
+// NameMapper. This allows for structs to be scanned into without tagging
+// every field, for example,
+//
+//     type Post struct {
+//         ID        int64
+//         Title     string
+//         CreatedAt time.Time
+//         Draft     bool `db:"is_draft"`
+//     }
+//
+//     m := queryscan.NewMapper(queryscan.SnakeCase)
+//
+//     var posts []Post
+//
+//     err := m.All(rows, &posts)
+//
+// would scan the id, title, created_at, and is_draft columns into each Post.
+// A field with a db tag is always mapped to the column in the tag, and a field
+// tagged with db:"-" is always skipped.
+type Mapper struct {
+	name  NameMapper
+	cache sync.Map
+}
+
+// NewMapper returns a new Mapper that maps the fields without a db tag via the
+// given NameMapper. If the NameMapper is nil then SnakeCase is used.
+func NewMapper(fn NameMapper) *Mapper {
+	if fn == nil {
+		fn = SnakeCase
+	}
+	return &Mapper{name: fn}
+}
+
+// column returns the column for the field of the given name.
+func (mp *Mapper) column(field string) string {
+	if mp.name == nil {
+		return field
+	}
+	return mp.name(field)
+}
+
+// SnakeCase returns the given name of a field in snake case, for example,
+// UserID would become user_id, and HTTPStatus would become http_status.
+func SnakeCase(name string) string { return naming.SnakeCase(name) }
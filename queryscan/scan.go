@@ -0,0 +1,310 @@
+// Package queryscan provides a way of scanning the rows returned from a query
+// into structs. The columns of each row are mapped to the fields of a struct
+// via the db tag, in the same way that the query package maps the fields of a
+// struct to columns, for example,
+//
+//     type Post struct {
+//         ID     int64  `db:"id"`
+//         Title  string `db:"title"`
+//         Author User   `db:"author"`
+//     }
+//
+//     q := query.Select(
+//         query.Columns("posts.id", "posts.title", `users.id AS "author.id"`, `users.email AS "author.email"`),
+//         query.From("posts"),
+//         query.Raw("JOIN users ON users.id = posts.user_id"),
+//     )
+//
+//     rows, err := q.QueryContext(ctx, db)
+//
+//     if err != nil {
+//         // Handle error.
+//     }
+//
+//     var posts []Post
+//
+//     err = queryscan.All(rows, &posts)
+//
+// A struct field with a db tag whose type is itself a struct is treated as a
+// nested struct, and is mapped to the columns that are prefixed with the name
+// of the field followed by a dot, such as author.id in the above example. This
+// allows for the rows of a JOIN to be scanned into multiple structs. Fields
+// whose type implements sql.Scanner, and fields of type time.Time, are not
+// treated as nested structs. Embedded structs without a db tag have their
+// fields flattened into the outer struct.
+package queryscan
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+
+	// tags is the Mapper used by the functions in the package, this only maps
+	// the fields that have a db tag.
+	tags = &Mapper{}
+)
+
+// Error records the column that caused a row to fail scanning.
+type Error struct {
+	Column string
+	Err    error
+}
+
+var (
+	// ErrDest is returned when the destination to scan into is not a pointer
+	// of the expected type.
+	ErrDest = errors.New("invalid destination")
+
+	// ErrColumn is returned when a column in a row cannot be mapped to a field
+	// in the struct.
+	ErrColumn = errors.New("no field for column")
+)
+
+func (e *Error) Error() string { return "queryscan: " + e.Column + ": " + e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// fields returns the index of each field in the given struct type, keyed by
+// the column the field is mapped to.
+func (mp *Mapper) fields(t reflect.Type) map[string][]int {
+	if m, ok := mp.cache.Load(t); ok {
+		return m.(map[string][]int)
+	}
+
+	m := make(map[string][]int)
+	mp.appendFields(m, t, "", nil)
+
+	mp.cache.Store(t, m)
+	return m
+}
+
+// nested reports whether the given type should be treated as a nested struct
+// rather than a single column.
+func nested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	return !reflect.PtrTo(t).Implements(scannerType)
+}
+
+func (mp *Mapper) appendFields(m map[string][]int, t reflect.Type, prefix string, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		tag, ok := sf.Tag.Lookup("db")
+
+		if sf.Anonymous && !ok {
+			ft := sf.Type
+
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				mp.appendFields(m, ft, prefix, fieldIndex)
+			}
+			continue
+		}
+
+		if (!ok && mp.name == nil) || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		col := tag
+
+		for j := 0; j < len(tag); j++ {
+			if tag[j] == ',' {
+				col = tag[:j]
+				break
+			}
+		}
+
+		if col == "" {
+			col = mp.column(sf.Name)
+		}
+
+		if nested(sf.Type) {
+			ft := sf.Type
+
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			mp.appendFields(m, ft, prefix+col+".", fieldIndex)
+			continue
+		}
+
+		if _, ok := m[prefix+col]; !ok {
+			m[prefix+col] = fieldIndex
+		}
+	}
+}
+
+// field returns the field at the given index in the given struct value. Any nil
+// pointers to structs along the way are allocated.
+func field(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// scan scans the current row into the given struct value.
+func (mp *Mapper) scan(rows *sql.Rows, cols []string, v reflect.Value) error {
+	m := mp.fields(v.Type())
+	dest := make([]interface{}, 0, len(cols))
+
+	for _, col := range cols {
+		index, ok := m[col]
+
+		if !ok {
+			return &Error{Column: col, Err: ErrColumn}
+		}
+		dest = append(dest, field(v, index).Addr().Interface())
+	}
+	return rows.Scan(dest...)
+}
+
+// structValue returns the struct value that the given destination points to.
+func structValue(dest interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(dest)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("queryscan: %w: expected pointer to struct, got %T", ErrDest, dest)
+	}
+	return v.Elem(), nil
+}
+
+// Row scans the current row of the given rows into the given pointer to a
+// struct. This should be called after a call to rows.Next.
+func Row(rows *sql.Rows, dest interface{}) error { return tags.Row(rows, dest) }
+
+// One scans the first of the given rows into the given pointer to a struct,
+// and closes the rows. If there are no rows then sql.ErrNoRows is returned.
+func One(rows *sql.Rows, dest interface{}) error { return tags.One(rows, dest) }
+
+// All scans each of the given rows into the given pointer to a slice, and
+// closes the rows. The slice can either be a slice of structs, or a slice of
+// pointers to structs.
+func All(rows *sql.Rows, dest interface{}) error { return tags.All(rows, dest) }
+
+// Row scans the current row of the given rows into the given pointer to a
+// struct in the same way as Row, only the fields are mapped via the Mapper.
+func (mp *Mapper) Row(rows *sql.Rows, dest interface{}) error {
+	v, err := structValue(dest)
+
+	if err != nil {
+		return err
+	}
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+	return mp.scan(rows, cols, v)
+}
+
+// One scans the first of the given rows into the given pointer to a struct in
+// the same way as One, only the fields are mapped via the Mapper.
+func (mp *Mapper) One(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+
+	v, err := structValue(dest)
+
+	if err != nil {
+		return err
+	}
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	if err := mp.scan(rows, cols, v); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// All scans each of the given rows into the given pointer to a slice in the
+// same way as All, only the fields are mapped via the Mapper.
+func (mp *Mapper) All(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+
+	v := reflect.ValueOf(dest)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("queryscan: %w: expected pointer to slice, got %T", ErrDest, dest)
+	}
+
+	slice := v.Elem()
+	elem := slice.Type().Elem()
+	ptr := elem.Kind() == reflect.Ptr
+
+	if ptr {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("queryscan: %w: expected slice of structs, got %T", ErrDest, dest)
+	}
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		item := reflect.New(elem)
+
+		if err := mp.scan(rows, cols, item.Elem()); err != nil {
+			return err
+		}
+
+		if ptr {
+			slice.Set(reflect.Append(slice, item))
+			continue
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
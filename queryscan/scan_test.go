@@ -0,0 +1,206 @@
+package queryscan
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/query/internal/mockdb"
+)
+
+type User struct {
+	ID    int64  `db:"id"`
+	Email string `db:"email"`
+}
+
+type Timestamps struct {
+	CreatedAt time.Time    `db:"created_at"`
+	DeletedAt sql.NullTime `db:"deleted_at"`
+}
+
+type Post struct {
+	Timestamps
+
+	ID     int64  `db:"id"`
+	Title  string `db:"title,omitempty"`
+	Author User   `db:"author"`
+	Editor *User  `db:"editor"`
+	Ignore string `db:"-"`
+}
+
+func Test_All(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(
+		mockdb.NewRows([]string{"id", "title", "created_at", "deleted_at", "author.id", "author.email", "editor.id"}).
+			AddRow(1, "foo", now, nil, 10, "me@example.com", 11).
+			AddRow(2, "bar", now, now, 12, "you@example.com", 13),
+	)
+
+	rows, err := db.Query("SELECT")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var posts []*Post
+
+	if err := All(rows, &posts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*Post{
+		{
+			Timestamps: Timestamps{CreatedAt: now},
+			ID:         1,
+			Title:      "foo",
+			Author:     User{ID: 10, Email: "me@example.com"},
+			Editor:     &User{ID: 11},
+		},
+		{
+			Timestamps: Timestamps{CreatedAt: now, DeletedAt: sql.NullTime{Time: now, Valid: true}},
+			ID:         2,
+			Title:      "bar",
+			Author:     User{ID: 12, Email: "you@example.com"},
+			Editor:     &User{ID: 13},
+		},
+	}
+
+	if !reflect.DeepEqual(posts, expected) {
+		t.Errorf("unexpected posts\n\texpected = %+v\n\tgot      = %+v\n", expected, posts)
+	}
+}
+
+func Test_One(t *testing.T) {
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(mockdb.NewRows([]string{"id", "email"}).AddRow(1, "me@example.com"))
+	mock.ExpectQuery("SELECT").WillReturnRows(mockdb.NewRows([]string{"id", "email"}))
+	mock.ExpectQuery("SELECT").WillReturnRows(mockdb.NewRows([]string{"id", "username"}).AddRow(1, "me"))
+
+	var u User
+
+	rows, _ := db.Query("SELECT")
+
+	if err := One(rows, &u); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (User{ID: 1, Email: "me@example.com"}); u != expected {
+		t.Errorf("expected %+v, got %+v\n", expected, u)
+	}
+
+	rows, _ = db.Query("SELECT")
+
+	if err := One(rows, &u); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected error %v, got %v\n", sql.ErrNoRows, err)
+	}
+
+	rows, _ = db.Query("SELECT")
+
+	if err := One(rows, &u); !errors.Is(err, ErrColumn) {
+		t.Errorf("expected error %v, got %v\n", ErrColumn, err)
+	}
+
+	if err := One(rows, u); !errors.Is(err, ErrDest) {
+		t.Errorf("expected error %v, got %v\n", ErrDest, err)
+	}
+}
+
+func Test_SnakeCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"ID", "id"},
+		{"UserID", "user_id"},
+		{"CreatedAt", "created_at"},
+		{"HTTPStatus", "http_status"},
+		{"Address2", "address2"},
+		{"S3Key", "s3_key"},
+		{"already_snake", "already_snake"},
+	}
+
+	for i, test := range tests {
+		if s := SnakeCase(test.name); s != test.expected {
+			t.Errorf("tests[%d] - unexpected name, expected=%q, got=%q\n", i, test.expected, s)
+		}
+	}
+}
+
+func Test_Mapper(t *testing.T) {
+	type Account struct {
+		UserID    int64
+		Email     string `db:"email_address"`
+		CreatedAt time.Time
+		Owner     User
+		Secret    string `db:"-"`
+	}
+
+	db, mock, err := mockdb.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(
+		mockdb.NewRows([]string{"user_id", "email_address", "created_at", "owner.id"}).AddRow(1, "me@example.com", now, 2),
+	)
+	mock.ExpectQuery("SELECT").WillReturnRows(
+		mockdb.NewRows([]string{"USERID", "email_address"}).AddRow(3, "you@example.com"),
+	)
+	mock.ExpectQuery("SELECT").WillReturnRows(mockdb.NewRows([]string{"secret"}).AddRow("foo"))
+
+	var accts []Account
+
+	rows, _ := db.Query("SELECT")
+
+	if err := NewMapper(nil).All(rows, &accts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Account{{UserID: 1, Email: "me@example.com", CreatedAt: now, Owner: User{ID: 2}}}
+
+	if !reflect.DeepEqual(accts, expected) {
+		t.Errorf("unexpected accounts\n\texpected = %+v\n\tgot      = %+v\n", expected, accts)
+	}
+
+	var acct Account
+
+	rows, _ = db.Query("SELECT")
+
+	if err := NewMapper(strings.ToUpper).One(rows, &acct); err != nil {
+		t.Fatal(err)
+	}
+
+	if acct.UserID != 3 || acct.Email != "you@example.com" {
+		t.Errorf("unexpected account %+v\n", acct)
+	}
+
+	rows, _ = db.Query("SELECT")
+
+	if err := NewMapper(nil).One(rows, &acct); !errors.Is(err, ErrColumn) {
+		t.Errorf("expected error %v, got %v\n", ErrColumn, err)
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/andrewpillar/query/queryslog
+
+go 1.21
+
+require github.com/andrewpillar/query v0.0.0
+
+replace github.com/andrewpillar/query => ../
@@ -0,0 +1,160 @@
+// Package queryslog provides a query.Execer that logs each query it executes
+// via log/slog, for example,
+//
+//     db := queryslog.New(sqldb, slog.Default())
+//
+//     rows, err := q.QueryContext(ctx, db)
+//
+// would log the query with the following attributes,
+//
+//     sql         the query that was executed
+//     fingerprint a hash of the query, for grouping the logs of the same query
+//     args        the number of arguments given to the query
+//     duration    how long the query took to execute
+//     rows        the number of rows affected, only for ExecContext
+//     err         the error returned from the query, if any
+//
+// The values of the arguments are never logged, since they could contain
+// sensitive data.
+package queryslog
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/andrewpillar/query"
+)
+
+// Execer wraps a query.Execer, logging each query that is executed.
+type Execer struct {
+	db     query.Execer
+	logger *slog.Logger
+	level  slog.Level
+	msg    string
+}
+
+var _ query.Execer = (*Execer)(nil)
+
+// Option is the type for the first-class functions that configure an Execer.
+type Option func(e *Execer)
+
+// Level sets the level at which successful queries are logged, by default this
+// is slog.LevelDebug. Queries that fail are always logged at slog.LevelError.
+func Level(level slog.Level) Option {
+	return func(e *Execer) {
+		e.level = level
+	}
+}
+
+// Message sets the message each query is logged with, by default this is
+// "query".
+func Message(msg string) Option {
+	return func(e *Execer) {
+		e.msg = msg
+	}
+}
+
+// New returns an Execer that executes queries on the given database, and logs
+// them to the given logger. If the logger is nil then slog.Default is used.
+func New(db query.Execer, logger *slog.Logger, opts ...Option) *Execer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	e := &Execer{
+		db:     db,
+		logger: logger,
+		level:  slog.LevelDebug,
+		msg:    "query",
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Unwrap returns the underlying database of the Execer.
+func (e *Execer) Unwrap() query.Execer { return e.db }
+
+// Fingerprint returns a hash of the given query as a hex string. Since the
+// arguments of a query are given via placeholders, the same query with
+// different arguments will have the same fingerprint.
+func Fingerprint(sql string) string {
+	h := fnv.New64a()
+	h.Write([]byte(sql))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func (e *Execer) log(ctx context.Context, sql string, args int, start time.Time, err error, attrs ...slog.Attr) {
+	level := e.level
+
+	if err != nil {
+		level = slog.LevelError
+	}
+
+	if !e.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append(attrs,
+		slog.String("sql", sql),
+		slog.String("fingerprint", Fingerprint(sql)),
+		slog.Int("args", args),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+	}
+	e.logger.LogAttrs(ctx, level, e.msg, attrs...)
+}
+
+// ExecContext executes the given query, and logs it along with the number of
+// rows affected.
+func (e *Execer) ExecContext(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+
+	res, err := e.db.ExecContext(ctx, sql, args...)
+
+	if err != nil {
+		e.log(ctx, sql, len(args), start, err)
+		return res, err
+	}
+
+	var attrs []slog.Attr
+
+	if n, err := res.RowsAffected(); err == nil {
+		attrs = append(attrs, slog.Int64("rows", n))
+	}
+
+	e.log(ctx, sql, len(args), start, nil, attrs...)
+	return res, nil
+}
+
+// QueryContext executes the given query, and logs it. The duration logged is
+// the time taken for the query to return, and does not include the time taken
+// to iterate over the rows.
+func (e *Execer) QueryContext(ctx context.Context, sql string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+
+	rows, err := e.db.QueryContext(ctx, sql, args...)
+
+	e.log(ctx, sql, len(args), start, err)
+	return rows, err
+}
+
+// QueryRowContext executes the given query, and logs it. Any error from the
+// query is only returned when the row is scanned, so it is logged via Err.
+func (e *Execer) QueryRowContext(ctx context.Context, sql string, args ...interface{}) *sql.Row {
+	start := time.Now()
+
+	row := e.db.QueryRowContext(ctx, sql, args...)
+
+	e.log(ctx, sql, len(args), start, row.Err())
+	return row
+}
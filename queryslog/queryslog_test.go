@@ -0,0 +1,100 @@
+package queryslog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/andrewpillar/query"
+)
+
+type execer struct {
+	err error
+}
+
+func (e execer) ExecContext(ctx context.Context, s string, args ...any) (sql.Result, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (e execer) QueryContext(ctx context.Context, s string, args ...any) (*sql.Rows, error) {
+	return nil, e.err
+}
+
+func (e execer) QueryRowContext(ctx context.Context, s string, args ...any) *sql.Row {
+	return &sql.Row{}
+}
+
+func newLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func Test_Execer(t *testing.T) {
+	var buf bytes.Buffer
+
+	ctx := context.Background()
+
+	q := query.Delete("posts", query.Where("id", "=", query.Arg(10)))
+	sql := q.Build()
+
+	db := New(execer{}, newLogger(&buf))
+
+	if _, err := q.ExecContext(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `level=DEBUG msg=query rows=3 sql="` + sql + `" fingerprint=` + Fingerprint(sql) + " args=1\n"
+
+	if s := buf.String(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	buf.Reset()
+
+	errBroken := errors.New("broken")
+
+	db = New(execer{err: errBroken}, newLogger(&buf), Level(slog.LevelInfo), Message("sql"))
+
+	if _, err := q.QueryContext(ctx, db); !errors.Is(err, errBroken) {
+		t.Fatalf("expected error %v, got %v\n", errBroken, err)
+	}
+
+	expected = `level=ERROR msg=sql sql="` + sql + `" fingerprint=` + Fingerprint(sql) + " args=1 err=broken\n"
+
+	if s := buf.String(); s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+}
+
+func Test_Fingerprint(t *testing.T) {
+	q1 := query.Select(query.Columns("*"), query.From("posts"), query.Where("id", "=", query.Arg(1)))
+	q2 := query.Select(query.Columns("*"), query.From("posts"), query.Where("id", "=", query.Arg(2)))
+	q3 := query.Select(query.Columns("*"), query.From("users"), query.Where("id", "=", query.Arg(1)))
+
+	if Fingerprint(q1.Build()) != Fingerprint(q2.Build()) {
+		t.Errorf("expected fingerprints to match for %q and %q\n", q1.Build(), q2.Build())
+	}
+
+	if Fingerprint(q1.Build()) == Fingerprint(q3.Build()) {
+		t.Errorf("expected fingerprints to differ for %q and %q\n", q1.Build(), q3.Build())
+	}
+
+	if s := Fingerprint(q1.Build()); strings.Trim(s, "0123456789abcdef") != "" {
+		t.Errorf("expected hex fingerprint, got %q\n", s)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/andrewpillar/query/querysqlx
+
+go 1.16
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/andrewpillar/query v0.0.0
+	github.com/jmoiron/sqlx v1.3.5
+)
+
+replace github.com/andrewpillar/query => ../
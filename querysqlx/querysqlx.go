@@ -0,0 +1,93 @@
+// Package querysqlx provides helpers for running queries built with the query
+// package via sqlx, for example,
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(id)),
+//     )
+//
+//     var posts []Post
+//
+//     err := querysqlx.Select(ctx, db, &posts, q)
+//
+// The placeholder style a Query is built with is taken from the driver of the
+// sqlx database, in the same way that sqlx rebinds its own queries.
+package querysqlx
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/andrewpillar/query"
+	"github.com/jmoiron/sqlx"
+)
+
+// Placeholder returns the placeholder style for the given sqlx bind type, as
+// returned from sqlx.BindType.
+func Placeholder(bindType int) query.Placeholder {
+	switch bindType {
+	case sqlx.QUESTION:
+		return query.Question
+	case sqlx.NAMED:
+		return query.Colon
+	case sqlx.AT:
+		return query.AtP
+	default:
+		return query.Dollar
+	}
+}
+
+// Build builds up the given Query for the driver of the given database,
+// returning the built query and its arguments.
+func Build(db sqlx.ExtContext, q query.Query) (string, []interface{}) {
+	return q.BuildWith(Placeholder(sqlx.BindType(db.DriverName()))), q.Args()
+}
+
+// Get builds up the given Query and scans the single row it returns into dest
+// via sqlx.GetContext.
+func Get(ctx context.Context, db sqlx.ExtContext, dest interface{}, q query.Query) error {
+	s, args := Build(db, q)
+	return sqlx.GetContext(ctx, db, dest, s, args...)
+}
+
+// Select builds up the given Query and scans each of the rows it returns into
+// the slice dest via sqlx.SelectContext.
+func Select(ctx context.Context, db sqlx.ExtContext, dest interface{}, q query.Query) error {
+	s, args := Build(db, q)
+	return sqlx.SelectContext(ctx, db, dest, s, args...)
+}
+
+// Queryx builds up the given Query and executes it, returning the rows.
+func Queryx(ctx context.Context, db sqlx.ExtContext, q query.Query) (*sqlx.Rows, error) {
+	s, args := Build(db, q)
+	return db.QueryxContext(ctx, s, args...)
+}
+
+// Exec builds up the given Query and executes it.
+func Exec(ctx context.Context, db sqlx.ExtContext, q query.Query) (sql.Result, error) {
+	s, args := Build(db, q)
+	return db.ExecContext(ctx, s, args...)
+}
+
+// Named builds up the given Query with the ColonName placeholder style, and
+// returns it along with a map of its named arguments. This can be used with the
+// named query functions of sqlx, such as sqlx.NamedExecContext, for example,
+//
+//     s, arg := querysqlx.Named(q)
+//
+//     _, err := sqlx.NamedExecContext(ctx, db, s, arg)
+//
+// Arguments given via query.Named use their name, and all other arguments are
+// named pn, where n is the position of the argument.
+func Named(q query.Query) (string, map[string]interface{}) {
+	named := q.NamedArgs()
+	arg := make(map[string]interface{}, len(named))
+
+	for _, v := range named {
+		if a, ok := v.(sql.NamedArg); ok {
+			arg[a.Name] = a.Value
+		}
+	}
+	return q.BuildWith(query.ColonName), arg
+}
@@ -0,0 +1,132 @@
+package querysqlx
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrewpillar/query"
+	"github.com/jmoiron/sqlx"
+)
+
+type post struct {
+	ID    int64  `db:"id"`
+	Title string `db:"title"`
+}
+
+func Test_Placeholder(t *testing.T) {
+	tests := []struct {
+		driver   string
+		expected string
+	}{
+		{"postgres", "SELECT * FROM posts WHERE (id = $1 AND user_id = $2)"},
+		{"mysql", "SELECT * FROM posts WHERE (id = ? AND user_id = ?)"},
+		{"godror", "SELECT * FROM posts WHERE (id = :1 AND user_id = :2)"},
+		{"sqlserver", "SELECT * FROM posts WHERE (id = @p1 AND user_id = @p2)"},
+	}
+
+	q := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.Where("id", "=", query.Arg(1)),
+		query.Where("user_id", "=", query.Arg(2)),
+	)
+
+	for i, test := range tests {
+		db, _, err := sqlmock.New()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s, args := Build(sqlx.NewDb(db, test.driver), q)
+
+		if s != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, s)
+		}
+
+		if !reflect.DeepEqual(args, []interface{}{1, 2}) {
+			t.Errorf("tests[%d]: unexpected args %v\n", i, args)
+		}
+		db.Close()
+	}
+}
+
+func Test_Select(t *testing.T) {
+	mockdb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer mockdb.Close()
+
+	db := sqlx.NewDb(mockdb, "postgres")
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT id, title FROM posts WHERE (user_id = $1)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "foo").AddRow(2, "bar"))
+
+	mock.ExpectQuery("SELECT id, title FROM posts WHERE (id = $1)").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "bar"))
+
+	var posts []post
+
+	if err := Select(ctx, db, &posts, query.Select(query.Columns("id", "title"), query.From("posts"), query.Where("user_id", "=", query.Arg(1)))); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []post{{1, "foo"}, {2, "bar"}}; !reflect.DeepEqual(posts, expected) {
+		t.Errorf("expected %v, got %v\n", expected, posts)
+	}
+
+	var p post
+
+	if err := Get(ctx, db, &p, query.Select(query.Columns("id", "title"), query.From("posts"), query.Where("id", "=", query.Arg(2)))); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (post{2, "bar"}); p != expected {
+		t.Errorf("expected %v, got %v\n", expected, p)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Named(t *testing.T) {
+	q := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.Where("user_id", "=", query.Named("user_id", 1)),
+		query.Where("title", "LIKE", query.Arg("%foo%")),
+	)
+
+	s, arg := Named(q)
+
+	if expected := "SELECT * FROM posts WHERE (user_id = :user_id AND title LIKE :p2)"; s != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, s)
+	}
+
+	if expected := map[string]interface{}{"user_id": 1, "p2": "%foo%"}; !reflect.DeepEqual(arg, expected) {
+		t.Errorf("expected %v, got %v\n", expected, arg)
+	}
+
+	bound, args, err := sqlx.Named(s, arg)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "SELECT * FROM posts WHERE (user_id = ? AND title LIKE ?)"; bound != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, bound)
+	}
+
+	if !reflect.DeepEqual(args, []interface{}{1, "%foo%"}) {
+		t.Errorf("unexpected args %v\n", args)
+	}
+}
@@ -0,0 +1,174 @@
+// Package querytest provides helpers for comparing queries in tests. Rather
+// than comparing the entire built query as a single string, the queries are
+// compared clause by clause, and argument by argument, so a failing test
+// reports what actually differs, for example,
+//
+//     if diff := querytest.Diff(expected, q); diff != "" {
+//         t.Errorf("unexpected query\n%s", diff)
+//     }
+//
+// might report the following,
+//
+//     clauses[1]: WHERE
+//         expected = user_id = ?
+//         got      = author_id = ?
+//     args[0]:
+//         expected = 10 (int)
+//         got      = "10" (string)
+package querytest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrewpillar/query"
+)
+
+type diff struct {
+	buf strings.Builder
+}
+
+func (d *diff) add(what string, expected, got string) {
+	fmt.Fprintf(&d.buf, "%s\n    expected = %s\n    got      = %s\n", what, expected, got)
+}
+
+func (d *diff) String() string { return d.buf.String() }
+
+func value(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v (%T)", v, v)
+}
+
+func (d *diff) args(expected, got []interface{}) {
+	n := len(expected)
+
+	if len(got) > n {
+		n = len(got)
+	}
+
+	for i := 0; i < n; i++ {
+		switch {
+		case i >= len(expected):
+			d.add(fmt.Sprintf("args[%d]: unexpected", i), "none", value(got[i]))
+		case i >= len(got):
+			d.add(fmt.Sprintf("args[%d]: missing", i), value(expected[i]), "none")
+		case !reflect.DeepEqual(expected[i], got[i]):
+			d.add(fmt.Sprintf("args[%d]:", i), value(expected[i]), value(got[i]))
+		}
+	}
+}
+
+func clause(cl query.Clause) string { return cl.Kind() + " " + cl.Build() }
+
+// Diff returns a description of the differences between the two given
+// queries, or an empty string if they are the same. The statements, clauses,
+// and arguments of the queries are compared, and if these are all the same
+// then the built queries are compared, which catches any difference in their
+// columns, tables, or Dialects.
+func Diff(expected, got query.Query) string {
+	var d diff
+
+	if expected.Statement() != got.Statement() {
+		d.add("statement:", expected.Statement(), got.Statement())
+	}
+
+	want := expected.Clauses()
+	have := got.Clauses()
+
+	n := len(want)
+
+	if len(have) > n {
+		n = len(have)
+	}
+
+	for i := 0; i < n; i++ {
+		switch {
+		case i >= len(want):
+			d.add(fmt.Sprintf("clauses[%d]: unexpected", i), "none", clause(have[i]))
+		case i >= len(have):
+			d.add(fmt.Sprintf("clauses[%d]: missing", i), clause(want[i]), "none")
+		case want[i].Kind() != have[i].Kind():
+			d.add(fmt.Sprintf("clauses[%d]:", i), clause(want[i]), clause(have[i]))
+		case want[i].Build() != have[i].Build():
+			d.add(fmt.Sprintf("clauses[%d]: %s", i, want[i].Kind()), want[i].Build(), have[i].Build())
+		}
+	}
+
+	if d.buf.Len() == 0 {
+		if s1, s2 := expected.Build(), got.Build(); s1 != s2 {
+			d.add("query:", s1, s2)
+		}
+	}
+
+	d.args(expected.Args(), got.Args())
+	return d.String()
+}
+
+// DiffSQL returns a description of the differences between the given SQL and
+// arguments, and the given Query, or an empty string if they are the same.
+// The position of the first difference in the built query is reported, along
+// with any arguments that differ.
+func DiffSQL(expected string, args []interface{}, got query.Query) string {
+	var d diff
+
+	if s := got.Build(); s != expected {
+		i := 0
+
+		for i < len(s) && i < len(expected) && s[i] == expected[i] {
+			i++
+		}
+		d.add(fmt.Sprintf("query: differs at offset %d", i), excerpt(expected, i), excerpt(s, i))
+	}
+
+	d.args(args, got.Args())
+	return d.String()
+}
+
+// excerpt returns the part of the given string around the given offset, this
+// is marked with ^ so the difference can be found in long queries.
+func excerpt(s string, i int) string {
+	const context = 24
+
+	start := i - context
+
+	if start < 0 {
+		start = 0
+	}
+
+	end := i + context
+
+	if end > len(s) {
+		end = len(s)
+	}
+
+	prefix := ""
+
+	if start > 0 {
+		prefix = "..."
+	}
+
+	suffix := ""
+
+	if end < len(s) {
+		suffix = "..."
+	}
+
+	if i > len(s) {
+		i = len(s)
+	}
+	return prefix + s[start:i] + "^" + s[i:end] + suffix
+}
+
+// Equal reports an error via t.Errorf if the two given queries differ, along
+// with the differences between them.
+func Equal(t testing.TB, expected, got query.Query) {
+	t.Helper()
+
+	if diff := Diff(expected, got); diff != "" {
+		t.Errorf("queries differ\n%s", diff)
+	}
+}
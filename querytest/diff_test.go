@@ -0,0 +1,77 @@
+package querytest
+
+import (
+	"testing"
+
+	"github.com/andrewpillar/query"
+)
+
+func Test_Diff(t *testing.T) {
+	posts := func(opts ...query.Option) query.Query {
+		return query.Select(query.Columns("*"), append([]query.Option{query.From("posts")}, opts...)...)
+	}
+
+	tests := []struct {
+		expected query.Query
+		got      query.Query
+		diff     string
+	}{
+		{
+			posts(query.Where("user_id", "=", query.Arg(10))),
+			posts(query.Where("user_id", "=", query.Arg(10))),
+			"",
+		},
+		{
+			posts(query.Where("user_id", "=", query.Arg(10))),
+			posts(query.Where("author_id", "=", query.Arg("10"))),
+			"clauses[1]: WHERE\n    expected = user_id = ?\n    got      = author_id = ?\n" +
+				"args[0]:\n    expected = 10 (int)\n    got      = \"10\" (string)\n",
+		},
+		{
+			posts(query.Where("user_id", "=", query.Arg(10)), query.Limit(5)),
+			posts(query.Where("user_id", "=", query.Arg(10))),
+			"clauses[2]: missing\n    expected = LIMIT 5\n    got      = none\n",
+		},
+		{
+			posts(query.Where("id", "IN", query.List(1, 2))),
+			posts(query.Where("id", "IN", query.List(1, 2, 3))),
+			"clauses[1]: WHERE\n    expected = id IN (?, ?)\n    got      = id IN (?, ?, ?)\n" +
+				"args[2]: unexpected\n    expected = none\n    got      = 3 (int)\n",
+		},
+		{
+			posts(),
+			query.Select(query.Columns("id"), query.From("posts")),
+			"query:\n    expected = SELECT * FROM posts\n    got      = SELECT id FROM posts\n",
+		},
+		{
+			query.Delete("posts"),
+			query.Update("posts", query.Set("title", query.Arg(nil))),
+			"statement:\n    expected = DELETE\n    got      = UPDATE\n" +
+				"clauses[0]: unexpected\n    expected = none\n    got      = SET title = ?\n" +
+				"args[0]: unexpected\n    expected = none\n    got      = nil\n",
+		},
+	}
+
+	for i, test := range tests {
+		if diff := Diff(test.expected, test.got); diff != test.diff {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.diff, diff)
+		}
+	}
+}
+
+func Test_DiffSQL(t *testing.T) {
+	q := query.Select(query.Columns("*"), query.From("posts"), query.Where("user_id", "=", query.Arg(10)))
+
+	if diff := DiffSQL("SELECT * FROM posts WHERE (user_id = $1)", []interface{}{10}, q); diff != "" {
+		t.Errorf("expected no diff, got %q\n", diff)
+	}
+
+	expected := "query: differs at offset 27\n" +
+		"    expected = ...ECT * FROM posts WHERE (^author_id = $1)\n" +
+		"    got      = ...ECT * FROM posts WHERE (^user_id = $1)\n" +
+		"args[0]:\n    expected = 11 (int)\n    got      = 10 (int)\n"
+
+	if diff := DiffSQL("SELECT * FROM posts WHERE (author_id = $1)", []interface{}{11}, q); diff != expected {
+		t.Errorf("\n\texpected = %q\n\tgot      = %q\n", expected, diff)
+	}
+}
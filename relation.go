@@ -0,0 +1,159 @@
+package query
+
+import "context"
+
+// Relation is a relation between a set of parent rows and the rows of another
+// table, this is used for loading the related rows of many parents in a single
+// query, rather than a query for each parent.
+type Relation struct {
+	// Table is the table of the related rows.
+	Table string
+
+	// Key is the column of the related rows that is matched against the keys
+	// of the parent rows.
+	Key string
+}
+
+// HasMany returns the Relation for the rows of the given table that reference
+// a parent via the given foreign key, for example the comments of a post,
+//
+//     query.HasMany("comments", "post_id")
+//
+// The keys of the parents would be the ids of the posts.
+func HasMany(table, foreignKey string) Relation {
+	return Relation{
+		Table: table,
+		Key:   foreignKey,
+	}
+}
+
+// BelongsTo returns the Relation for the rows of the given table that a parent
+// references via the given key of the table, for example the author of a post,
+//
+//     query.BelongsTo("users", "id")
+//
+// The keys of the parents would be the user_id of the posts.
+func BelongsTo(table, key string) Relation {
+	return Relation{
+		Table: table,
+		Key:   key,
+	}
+}
+
+// Query returns the query for the related rows of the parents with the given
+// keys, applying the given options. The keys are given as a single array
+// argument, for example,
+//
+//     query.HasMany("comments", "post_id").Query([]int64{1, 2, 3})
+//
+// would result in the following query being built,
+//
+//     SELECT * FROM comments WHERE (post_id = ANY ($1))
+//
+// This relies on PostgreSQL arrays, so the driver must support slices as
+// arguments, as pgx does.
+func (r Relation) Query(keys interface{}, opts ...Option) Query {
+	return Select(
+		Columns("*"),
+		append([]Option{From(r.Table), WhereOp(r.Key, OpEqAny, Raw("(?)", keys))}, opts...)...,
+	)
+}
+
+// Keys returns the distinct keys of the given parents in the order they first
+// appear, for passing to Relation.Query.
+func Keys[P any, K comparable](parents []P, key func(P) K) []K {
+	seen := make(map[K]struct{}, len(parents))
+	keys := make([]K, 0, len(parents))
+
+	for _, p := range parents {
+		k := key(p)
+
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Group returns the given rows grouped by their key, the order of the rows in
+// each group is preserved.
+func Group[T any, K comparable](rows []T, key func(T) K) map[K][]T {
+	m := make(map[K][]T)
+
+	for _, row := range rows {
+		k := key(row)
+		m[k] = append(m[k], row)
+	}
+	return m
+}
+
+// LoadMany loads the rows related to the given parents via a HasMany relation
+// in a single query, and attaches the rows for each parent via the attach
+// function, for example,
+//
+//     err := query.LoadMany(ctx, db, query.HasMany("comments", "post_id"), posts,
+//         func(p Post) int64 { return p.ID },
+//         func(c Comment) int64 { return c.PostID },
+//         func(p *Post, cc []Comment) { p.Comments = cc },
+//     )
+//
+// The rows are scanned via queryscan, and the given options are applied to
+// the query, such as for ordering the rows. The attach function is called for
+// every parent, with nil for a parent that has no related rows.
+func LoadMany[P, C any, K comparable](ctx context.Context, db Execer, r Relation, parents []P, parentKey func(P) K, childKey func(C) K, attach func(*P, []C), opts ...Option) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	children, err := All[C](ctx, db, r.Query(Keys(parents, parentKey), opts...))
+
+	if err != nil {
+		return err
+	}
+
+	groups := Group(children, childKey)
+
+	for i := range parents {
+		attach(&parents[i], groups[parentKey(parents[i])])
+	}
+	return nil
+}
+
+// LoadOne loads the rows related to the given parents via a BelongsTo relation
+// in a single query, and attaches the row for each parent via the attach
+// function, for example,
+//
+//     err := query.LoadOne(ctx, db, query.BelongsTo("users", "id"), posts,
+//         func(p Post) int64 { return p.UserID },
+//         func(u User) int64 { return u.ID },
+//         func(p *Post, u User) { p.Author = u },
+//     )
+//
+// The attach function is not called for a parent that has no related row.
+func LoadOne[P, C any, K comparable](ctx context.Context, db Execer, r Relation, parents []P, parentKey func(P) K, childKey func(C) K, attach func(*P, C), opts ...Option) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	rows, err := All[C](ctx, db, r.Query(Keys(parents, parentKey), opts...))
+
+	if err != nil {
+		return err
+	}
+
+	m := make(map[K]C, len(rows))
+
+	for _, row := range rows {
+		m[childKey(row)] = row
+	}
+
+	for i := range parents {
+		if row, ok := m[parentKey(parents[i])]; ok {
+			attach(&parents[i], row)
+		}
+	}
+	return nil
+}
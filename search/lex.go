@@ -0,0 +1,161 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+type token uint
+
+//go:generate stringer -type token -linecomment
+const (
+	_EOF    token = iota // end of input
+	_Ident               // identifier
+	_String              // string
+	_LParen              // (
+	_RParen              // )
+	_Comma               // ,
+	_Eq                  // :
+	_Neq                 // !:
+	_Match               // ~
+	_Gt                  // >
+	_Geq                 // >=
+	_Lt                  // <
+	_Leq                 // <=
+	_And                 // AND
+	_Or                  // OR
+	_Not                 // NOT
+	_In                  // IN
+)
+
+var keywords = map[string]token{
+	"AND": _And,
+	"OR":  _Or,
+	"NOT": _Not,
+	"IN":  _In,
+}
+
+// item is a single lexed token along with its literal value and its position
+// in the input.
+type item struct {
+	tok token
+	lit string
+	pos int
+}
+
+// lexer splits a search expression into items.
+type lexer struct {
+	src string
+	pos int
+}
+
+func isIdent(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-@%*/+", r)
+}
+
+// next returns the next item in the input, or an error if an unexpected
+// character, or unterminated string, is encountered.
+func (l *lexer) next() (item, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+
+	if l.pos >= len(l.src) {
+		return item{tok: _EOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+
+	two := ""
+
+	if l.pos+1 < len(l.src) {
+		two = l.src[l.pos : l.pos+2]
+	}
+
+	switch two {
+	case "!:":
+		l.pos += 2
+		return item{tok: _Neq, lit: two, pos: start}, nil
+	case ">=":
+		l.pos += 2
+		return item{tok: _Geq, lit: two, pos: start}, nil
+	case "<=":
+		l.pos += 2
+		return item{tok: _Leq, lit: two, pos: start}, nil
+	}
+
+	single := map[byte]token{
+		'(': _LParen,
+		')': _RParen,
+		',': _Comma,
+		':': _Eq,
+		'~': _Match,
+		'>': _Gt,
+		'<': _Lt,
+	}
+
+	if tok, ok := single[c]; ok {
+		l.pos++
+		return item{tok: tok, lit: string(c), pos: start}, nil
+	}
+
+	if c == '"' {
+		return l.string()
+	}
+
+	for l.pos < len(l.src) {
+		r := rune(l.src[l.pos])
+
+		if r >= 0x80 {
+			// Let multi-byte runes through as part of the identifier.
+			l.pos++
+			continue
+		}
+
+		if !isIdent(r) {
+			break
+		}
+		l.pos++
+	}
+
+	if l.pos == start {
+		return item{}, &Error{Pos: start, Msg: "unexpected character " + string(c)}
+	}
+
+	lit := l.src[start:l.pos]
+
+	if tok, ok := keywords[strings.ToUpper(lit)]; ok {
+		return item{tok: tok, lit: lit, pos: start}, nil
+	}
+	return item{tok: _Ident, lit: lit, pos: start}, nil
+}
+
+// string lexes a double quoted string, a double quote can be escaped within
+// the string with a backslash.
+func (l *lexer) string() (item, error) {
+	start := l.pos
+	l.pos++
+
+	var buf strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch c {
+		case '\\':
+			if l.pos+1 < len(l.src) {
+				buf.WriteByte(l.src[l.pos+1])
+				l.pos += 2
+				continue
+			}
+		case '"':
+			l.pos++
+			return item{tok: _String, lit: buf.String(), pos: start}, nil
+		}
+
+		buf.WriteByte(c)
+		l.pos++
+	}
+	return item{}, &Error{Pos: start, Msg: "unterminated string"}
+}
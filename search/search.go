@@ -0,0 +1,366 @@
+// Package search provides a compiler for search expressions into a query.Expr
+// that can be used as a WHERE clause. This is intended for search boxes where
+// users can write their own filters, such as,
+//
+//     status:open AND (title~"foo" OR tag IN (a, b))
+//
+// Each term in an expression is a field, an operator, and a value. The fields
+// that can be searched on, and the operators that can be used for each field,
+// are configured via a Search. The supported operators are,
+//
+//     :   equal to
+//     !:  not equal to
+//     ~   case insensitive substring match
+//     >   greater than
+//     >=  greater than or equal to
+//     <   less than
+//     <=  less than or equal to
+//     IN  in a parenthesised list of values
+//
+// Terms can be combined with AND and OR, negated with NOT, and grouped with
+// parentheses. Values containing whitespace or special characters should be
+// double quoted. All values are given to the resulting expression as
+// arguments, and are never placed into the query itself.
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andrewpillar/query"
+)
+
+// Field is a field that can be searched on.
+type Field struct {
+	// Column is the column in the query that the field maps to.
+	Column string
+
+	// Ops is the list of operators that can be used on the field, for
+	// example ":" or "IN". If empty then all operators can be used.
+	Ops []string
+}
+
+// Search is the configuration of the fields that can be searched on, keyed by
+// the name of the field as it would appear in a search expression.
+type Search map[string]Field
+
+// Error records the position in a search expression at which an error
+// occurred.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string { return "search: " + strconv.Itoa(e.Pos) + ": " + e.Msg }
+
+var sqlops = map[token]string{
+	_Eq:    "=",
+	_Neq:   "!=",
+	_Match: "ILIKE",
+	_Gt:    ">",
+	_Geq:   ">=",
+	_Lt:    "<",
+	_Leq:   "<=",
+	_In:    "IN",
+}
+
+// expr is the compiled search expression.
+type expr struct {
+	sql  string
+	args []interface{}
+}
+
+var _ query.Expr = (*expr)(nil)
+
+func (e expr) Args() []interface{} { return e.args }
+func (e expr) Build() string       { return e.sql }
+
+type parser struct {
+	lex    *lexer
+	search Search
+	tok    item
+}
+
+// Compile compiles the given search expression into a query.Expr. An error is
+// returned if the expression is malformed, or if it uses a field or operator
+// that is not allowed.
+func (s Search) Compile(src string) (query.Expr, error) {
+	p := parser{
+		lex:    &lexer{src: src},
+		search: s,
+	}
+
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	e, err := p.or()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.tok != _EOF {
+		return nil, p.unexpected()
+	}
+	return e, nil
+}
+
+// Option compiles the given search expression and returns a query.Option that
+// will append it as a WHERE clause. An empty search expression will result in
+// an Option that does nothing.
+func (s Search) Option(src string) (query.Option, error) {
+	if strings.TrimSpace(src) == "" {
+		return query.Options(), nil
+	}
+
+	e, err := s.Compile(src)
+
+	if err != nil {
+		return nil, err
+	}
+	return query.WhereExpr(e), nil
+}
+
+func (p *parser) next() error {
+	it, err := p.lex.next()
+
+	if err != nil {
+		return err
+	}
+
+	p.tok = it
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return &Error{Pos: p.tok.pos, Msg: "unexpected " + p.tok.tok.String()}
+}
+
+func (p *parser) expect(tok token) error {
+	if p.tok.tok != tok {
+		return &Error{Pos: p.tok.pos, Msg: "expected " + tok.String() + ", got " + p.tok.tok.String()}
+	}
+	return p.next()
+}
+
+// join conjoins the given expressions with the given keyword.
+func join(kw string, exprs []expr) expr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+
+	sqls := make([]string, 0, len(exprs))
+	args := make([]interface{}, 0)
+
+	for _, e := range exprs {
+		sqls = append(sqls, e.sql)
+		args = append(args, e.args...)
+	}
+
+	return expr{
+		sql:  "(" + strings.Join(sqls, " "+kw+" ") + ")",
+		args: args,
+	}
+}
+
+func (p *parser) or() (expr, error) {
+	e, err := p.and()
+
+	if err != nil {
+		return expr{}, err
+	}
+
+	exprs := []expr{e}
+
+	for p.tok.tok == _Or {
+		if err := p.next(); err != nil {
+			return expr{}, err
+		}
+
+		e, err := p.and()
+
+		if err != nil {
+			return expr{}, err
+		}
+		exprs = append(exprs, e)
+	}
+	return join("OR", exprs), nil
+}
+
+func (p *parser) and() (expr, error) {
+	e, err := p.unary()
+
+	if err != nil {
+		return expr{}, err
+	}
+
+	exprs := []expr{e}
+
+	for p.tok.tok == _And {
+		if err := p.next(); err != nil {
+			return expr{}, err
+		}
+
+		e, err := p.unary()
+
+		if err != nil {
+			return expr{}, err
+		}
+		exprs = append(exprs, e)
+	}
+	return join("AND", exprs), nil
+}
+
+func (p *parser) unary() (expr, error) {
+	switch p.tok.tok {
+	case _Not:
+		if err := p.next(); err != nil {
+			return expr{}, err
+		}
+
+		e, err := p.unary()
+
+		if err != nil {
+			return expr{}, err
+		}
+
+		e.sql = "NOT " + e.sql
+		return e, nil
+	case _LParen:
+		if err := p.next(); err != nil {
+			return expr{}, err
+		}
+
+		e, err := p.or()
+
+		if err != nil {
+			return expr{}, err
+		}
+
+		if err := p.expect(_RParen); err != nil {
+			return expr{}, err
+		}
+
+		if !strings.HasPrefix(e.sql, "(") {
+			e.sql = "(" + e.sql + ")"
+		}
+		return e, nil
+	case _Ident:
+		return p.term()
+	}
+	return expr{}, p.unexpected()
+}
+
+func (p *parser) value() (string, error) {
+	if p.tok.tok != _Ident && p.tok.tok != _String {
+		return "", &Error{Pos: p.tok.pos, Msg: "expected value, got " + p.tok.tok.String()}
+	}
+
+	lit := p.tok.lit
+	return lit, p.next()
+}
+
+func (p *parser) term() (expr, error) {
+	name := p.tok
+
+	field, ok := p.search[name.lit]
+
+	if !ok {
+		return expr{}, &Error{Pos: name.pos, Msg: "unknown field " + name.lit}
+	}
+
+	if err := p.next(); err != nil {
+		return expr{}, err
+	}
+
+	op := p.tok
+
+	sqlop, ok := sqlops[op.tok]
+
+	if !ok {
+		return expr{}, &Error{Pos: op.pos, Msg: "expected operator, got " + op.tok.String()}
+	}
+
+	if !field.allows(op.tok) {
+		return expr{}, &Error{Pos: op.pos, Msg: "operator " + op.tok.String() + " not allowed for field " + name.lit}
+	}
+
+	if err := p.next(); err != nil {
+		return expr{}, err
+	}
+
+	if op.tok == _In {
+		if err := p.expect(_LParen); err != nil {
+			return expr{}, err
+		}
+
+		var (
+			items []string
+			args  []interface{}
+		)
+
+		for {
+			val, err := p.value()
+
+			if err != nil {
+				return expr{}, err
+			}
+
+			items = append(items, "?")
+			args = append(args, val)
+
+			if p.tok.tok != _Comma {
+				break
+			}
+
+			if err := p.next(); err != nil {
+				return expr{}, err
+			}
+		}
+
+		if err := p.expect(_RParen); err != nil {
+			return expr{}, err
+		}
+
+		return expr{
+			sql:  field.Column + " IN (" + strings.Join(items, ", ") + ")",
+			args: args,
+		}, nil
+	}
+
+	val, err := p.value()
+
+	if err != nil {
+		return expr{}, err
+	}
+
+	if op.tok == _Match {
+		val = "%" + escapeLike(val) + "%"
+	}
+
+	return expr{
+		sql:  field.Column + " " + sqlop + " ?",
+		args: []interface{}{val},
+	}, nil
+}
+
+// allows reports whether the given operator can be used on the field.
+func (f Field) allows(tok token) bool {
+	if len(f.Ops) == 0 {
+		return true
+	}
+
+	for _, op := range f.Ops {
+		if strings.EqualFold(op, tok.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike escapes the wildcard characters in the given value so that it is
+// matched literally in a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
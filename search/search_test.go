@@ -0,0 +1,81 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrewpillar/query"
+)
+
+var s = Search{
+	"status": {Column: "status", Ops: []string{":", "!:"}},
+	"title":  {Column: "title"},
+	"tag":    {Column: "tags.name"},
+	"stars":  {Column: "stars"},
+}
+
+func Test_Compile(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+		args     []interface{}
+	}{
+		{
+			`status:open`,
+			"SELECT * FROM repos WHERE (status = $1)",
+			[]interface{}{"open"},
+		},
+		{
+			`status:open AND (title~"foo bar" OR tag IN (a, b))`,
+			"SELECT * FROM repos WHERE ((status = $1 AND (title ILIKE $2 OR tags.name IN ($3, $4))))",
+			[]interface{}{"open", "%foo bar%", "a", "b"},
+		},
+		{
+			`NOT status!:closed or stars >= 10`,
+			"SELECT * FROM repos WHERE ((NOT status != $1 OR stars >= $2))",
+			[]interface{}{"closed", "10"},
+		},
+		{
+			`title~"100%_\"done\""`,
+			"SELECT * FROM repos WHERE (title ILIKE $1)",
+			[]interface{}{`%100\%\_"done"%`},
+		},
+	}
+
+	for i, test := range tests {
+		e, err := s.Compile(test.src)
+
+		if err != nil {
+			t.Fatalf("tests[%d]: %s\n", i, err)
+		}
+
+		q := query.Select(query.Columns("*"), query.From("repos"), query.WhereExpr(e))
+
+		if built := q.Build(); built != test.expected {
+			t.Errorf("tests[%d]:\n\texpected = %q\n\tgot      = %q\n", i, test.expected, built)
+		}
+
+		if !reflect.DeepEqual(q.Args(), test.args) {
+			t.Errorf("tests[%d]: expected args %v, got %v\n", i, test.args, q.Args())
+		}
+	}
+}
+
+func Test_CompileErrors(t *testing.T) {
+	tests := []string{
+		`password:secret`,
+		`status~open`,
+		`status:open AND`,
+		`(status:open`,
+		`title:"foo`,
+		`status open`,
+		`tag IN (a b)`,
+		`status:open; DROP TABLE repos`,
+	}
+
+	for i, src := range tests {
+		if _, err := s.Compile(src); err == nil {
+			t.Errorf("tests[%d]: expected error for %q\n", i, src)
+		}
+	}
+}
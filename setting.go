@@ -0,0 +1,152 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// settingPattern matches the name of a setting, including custom settings
+// which are prefixed with a namespace, such as app.user_id.
+var settingPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+// SetVar builds up a SET statement for the given setting and value. The value
+// is quoted as a literal, for example,
+//
+//     query.SetVar("statement_timeout", 5*time.Second)
+//
+// would result in the following statement being built,
+//
+//     SET statement_timeout = '5000ms'
+//
+// A slice of strings is written as a list of literals, which can be used for
+// settings such as search_path. An error is returned if the setting name is
+// not valid, or if the value cannot be written as a literal.
+func SetVar(name string, val interface{}) (string, error) { return setVar("SET ", name, val) }
+
+// SetLocal builds up a SET LOCAL statement for the given setting and value in
+// the same way as SetVar. A setting set via SET LOCAL only lasts until the end
+// of the current transaction.
+func SetLocal(name string, val interface{}) (string, error) { return setVar("SET LOCAL ", name, val) }
+
+func setVar(stmt, name string, val interface{}) (string, error) {
+	if !settingPattern.MatchString(name) {
+		return "", fmt.Errorf("query: %w: %q", ErrSetting, name)
+	}
+
+	if vals, ok := val.([]string); ok {
+		items := make([]string, 0, len(vals))
+
+		for _, v := range vals {
+			items = append(items, quoteString(v))
+		}
+		return stmt + name + " = " + strings.Join(items, ", "), nil
+	}
+
+	lit, err := literal(val)
+
+	if err != nil {
+		return "", err
+	}
+	return stmt + name + " = " + lit, nil
+}
+
+// SetConfig builds up a SELECT set_config(...) query for the given setting and
+// value. Unlike SetVar and SetLocal, the name and value are bound as arguments
+// rather than written as literals, for example,
+//
+//     query.SetConfig("app.current_tenant", tenantID, true)
+//
+// would result in the following query being built,
+//
+//     SELECT set_config($1, $2, $3)
+//
+// If local is true, then the setting only lasts until the end of the current
+// transaction, as with SET LOCAL. This is typically used for setting the
+// values that row level security policies are keyed off of via
+// current_setting. If the setting name is not valid then an error is recorded
+// on the Query, see Err.
+func SetConfig(name, val string, local bool) Query {
+	q := Select(Raw("set_config(?, ?, ?)", name, val, local))
+
+	if !settingPattern.MatchString(name) {
+		q.errs = append(q.errs, fmt.Errorf("query: %w: %q", ErrSetting, name))
+	}
+	return q
+}
+
+// settingsBeginner is the TxBeginner returned from WithSettings.
+type settingsBeginner struct {
+	db TxBeginner
+	fn func(ctx context.Context) map[string]string
+}
+
+// WithSettings returns a TxBeginner that begins transactions on the given
+// database, and sets the settings returned from the given function at the
+// start of each transaction via SetConfig. The settings are local to the
+// transaction, so they cannot leak to other uses of the same connection. The
+// function is given the context the transaction was begun with, so the
+// settings can be taken from the request, for example,
+//
+//     db := query.WithSettings(sqldb, func(ctx context.Context) map[string]string {
+//         return map[string]string{
+//             "app.current_tenant": tenantFromContext(ctx),
+//         }
+//     })
+//
+//     err := query.WithTx(ctx, db, func(tx query.Execer) error {
+//         // Queries are subject to the row level security policies for the
+//         // current tenant.
+//     })
+//
+// The settings are set in order of their name. If the name of a setting is not
+// valid, or a setting cannot be set, then the transaction is rolled back, and
+// the error is returned.
+func WithSettings(db TxBeginner, fn func(ctx context.Context) map[string]string) TxBeginner {
+	return settingsBeginner{
+		db: db,
+		fn: fn,
+	}
+}
+
+// BeginTx begins a transaction on the underlying database, and sets the
+// settings for the transaction.
+func (b settingsBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := b.db.BeginTx(ctx, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	settings := b.fn(ctx)
+	names := make([]string, 0, len(settings))
+
+	for name := range settings {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	qq := make([]Query, 0, len(names))
+
+	for _, name := range names {
+		q := SetConfig(name, settings[name], true)
+
+		if err := q.Err(); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		qq = append(qq, q)
+	}
+
+	for _, q := range qq {
+		if _, err := q.ExecContext(ctx, tx); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return tx, nil
+}
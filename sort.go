@@ -0,0 +1,85 @@
+package query
+
+import "sort"
+
+// clauseOrder is the canonical order of the kinds of clauses in a statement.
+// Kinds that cannot appear in the same statement, such as SET and FROM for a
+// SELECT, share the one order.
+var clauseOrder = map[clauseKind]float64{
+	_SetClause:       0,
+	_ValuesClause:    1,
+	_FromClause:      2,
+	_WhereClause:     3,
+	_GroupClause:     4,
+	_UnionClause:     5,
+	_ConflictClause:  6,
+	_OrderClause:     7,
+	_LimitClause:     8,
+	_OffsetClause:    9,
+	_FetchClause:     10,
+	_ReturningClause: 11,
+}
+
+// rank returns the position of the clause kind in the canonical order. A kind
+// registered via RegisterClause is placed just before the first of the kinds
+// it was registered to be placed before, otherwise it is placed at the end.
+func (k clauseKind) rank() float64 {
+	if r, ok := clauseOrder[k]; ok {
+		return r
+	}
+
+	def, ok := customDefOf(k)
+
+	if !ok || len(def.before) == 0 {
+		return float64(len(clauseOrder))
+	}
+
+	r := float64(len(clauseOrder))
+
+	for _, before := range def.before {
+		if r1 := before.rank(); r1 < r {
+			r = r1
+		}
+	}
+	return r - 1.0/64
+}
+
+// sorted returns the Query with its clauses sorted into the canonical order,
+// so the SQL is valid regardless of the order the options were given in, for
+// example, an ORDER BY given before a WHERE. Clauses of the same kind keep the
+// order they were given in.
+func (q Query) sorted() Query {
+	ranks := make([]float64, len(q.clauses))
+	ok := true
+
+	for i, cl := range q.clauses {
+		ranks[i] = cl.kind().rank()
+
+		if i > 0 && ranks[i] < ranks[i-1] {
+			ok = false
+		}
+	}
+
+	if ok {
+		return q
+	}
+
+	idx := make([]int, len(q.clauses))
+
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		return ranks[idx[i]] < ranks[idx[j]]
+	})
+
+	clauses := make([]clause, 0, len(q.clauses))
+
+	for _, i := range idx {
+		clauses = append(clauses, q.clauses[i])
+	}
+
+	q.clauses = clauses
+	return q
+}
@@ -15,11 +15,12 @@ func _() {
 	_ = x[_Update-4]
 	_ = x[_SelectDistinct-5]
 	_ = x[_SelectDistinctOn-6]
+	_ = x[_Explain-7]
 }
 
-const _statement_name = "DELETEINSERTSELECTUPDATESELECT DISTINCTSELECT DISTINCT ON"
+const _statement_name = "DELETEINSERTSELECTUPDATESELECT DISTINCTSELECT DISTINCT ONEXPLAIN"
 
-var _statement_index = [...]uint8{0, 0, 6, 12, 18, 24, 39, 57}
+var _statement_index = [...]uint8{0, 0, 6, 12, 18, 24, 39, 57, 64}
 
 func (i statement) String() string {
 	if i >= statement(len(_statement_index)-1) {
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// Preparer is the interface that wraps the PrepareContext method. This is
+// satisfied by *sql.DB, *sql.Conn, and *sql.Tx.
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// StmtCache is a cache of prepared statements, keyed by the database they were
+// prepared on and the built query. This allows for queries of the same shape
+// to reuse the same prepared statement, for example,
+//
+//     var stmts query.StmtCache
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(id)),
+//     )
+//
+//     stmt, err := stmts.Prepare(ctx, db, q)
+//
+//     if err != nil {
+//         // Handle error.
+//     }
+//
+//     rows, err := stmt.QueryContext(ctx, q.Args()...)
+//
+// The statements in the cache live for as long as the cache does, so the
+// cache should only be used with a long lived Preparer, such as *sql.DB. The
+// zero value of a StmtCache is ready to use, and it is safe for concurrent use.
+type StmtCache struct {
+	mu    sync.Mutex
+	stmts map[Preparer]map[string]*sql.Stmt
+}
+
+var stmts StmtCache
+
+// Prepare returns a prepared statement for the given Query on the given
+// database from the default StmtCache.
+func Prepare(ctx context.Context, db Preparer, q Query) (*sql.Stmt, error) {
+	return stmts.Prepare(ctx, db, q)
+}
+
+// Check checks the given Query against the given database without executing
+// it. The Query is built via BuildErr, and then prepared on the database and
+// closed straight away, so the database checks the syntax of the query, and
+// that the tables and columns it uses exist. This is meant for integration
+// tests that check every query an application can build against a test
+// database, for example,
+//
+//     for _, q := range queries {
+//         if err := query.Check(ctx, db, q); err != nil {
+//             t.Error(err)
+//         }
+//     }
+//
+// Any error from the database is returned along with the built query.
+func Check(ctx context.Context, db Preparer, q Query) error {
+	s, err := q.BuildErr()
+
+	if err != nil {
+		return err
+	}
+
+	stmt, err := db.PrepareContext(ctx, s)
+
+	if err != nil {
+		return fmt.Errorf("query: %s: %w", s, err)
+	}
+	return stmt.Close()
+}
+
+func (c *StmtCache) get(db Preparer, s string) (*sql.Stmt, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stmt, ok := c.stmts[db][s]
+	return stmt, ok
+}
+
+// Prepare returns a prepared statement for the given Query on the given
+// database. The Query is built via BuildErr, and if a statement has already
+// been prepared for the built query on the database then that statement is
+// returned. The returned statement should not be closed, since it is owned by
+// the cache.
+func (c *StmtCache) Prepare(ctx context.Context, db Preparer, q Query) (*sql.Stmt, error) {
+	s, err := q.BuildErr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt, ok := c.get(db, s); ok {
+		return stmt, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have prepared the same statement whilst we were
+	// preparing ours, in which case we use theirs.
+	if prepared, ok := c.stmts[db][s]; ok {
+		stmt.Close()
+		return prepared, nil
+	}
+
+	if c.stmts == nil {
+		c.stmts = make(map[Preparer]map[string]*sql.Stmt)
+	}
+
+	if c.stmts[db] == nil {
+		c.stmts[db] = make(map[string]*sql.Stmt)
+	}
+
+	c.stmts[db][s] = stmt
+	return stmt, nil
+}
+
+// Forget closes and removes all of the statements in the cache that were
+// prepared on the given database. The first error that occurs when closing the
+// statements is returned.
+func (c *StmtCache) Forget(db Preparer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+
+	for _, stmt := range c.stmts[db] {
+		if cerr := stmt.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	delete(c.stmts, db)
+	return err
+}
+
+// Close closes and removes all of the statements in the cache. The first
+// error that occurs when closing the statements is returned.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+
+	for _, stmts := range c.stmts {
+		for _, stmt := range stmts {
+			if cerr := stmt.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	}
+
+	c.stmts = nil
+	return err
+}
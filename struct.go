@@ -0,0 +1,206 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/andrewpillar/query/internal/naming"
+)
+
+// NameMapper maps the name of a struct field to the name of a column.
+type NameMapper func(field string) string
+
+// Mapper maps the fields of a struct to columns for the struct helpers, such
+// as InsertStruct, SetStruct, and WhereStruct. The struct helpers in the
+// package only map the fields that have a db tag, whereas a Mapper maps the
+// fields without a db tag too, via its NameMapper. This allows for structs to
+// be used without tagging every field, for example,
+//
+//     type User struct {
+//         ID        int64     `db:",readonly"`
+//         Email     string
+//         CreatedAt time.Time `db:",readonly"`
+//     }
+//
+//     m := query.NewMapper(query.SnakeCase)
+//
+//     q := m.InsertStruct("users", u, query.Returning("id", "created_at"))
+//
+// would result in the following query being built,
+//
+//     INSERT INTO users (email) VALUES ($1) RETURNING id, created_at
+//
+// A field with a name in its db tag is always mapped to that column, and a
+// field tagged with db:"-" is always skipped. The queryscan.Mapper can be used
+// for scanning rows into structs in the same way.
+type Mapper struct {
+	name NameMapper
+}
+
+// tags is the Mapper used by the struct helpers in the package, this only maps
+// the fields that have a db tag.
+var tags Mapper
+
+// field is a single field of a struct that has been mapped to a column via
+// its struct tags.
+type field struct {
+	col  string
+	op   string
+	opts map[string]struct{}
+	val  reflect.Value
+
+	// pk reports whether the field is mapped to a column in the primary key of
+	// the table, as registered via RegisterTable.
+	pk bool
+}
+
+// has reports whether the field's db tag has the given option, for example
+// omitempty.
+func (f field) has(opt string) bool {
+	_, ok := f.opts[opt]
+	return ok
+}
+
+// zero reports whether the field's value is the zero value for its type.
+func (f field) zero() bool { return f.val.IsZero() }
+
+// value returns the underlying value of the field, dereferencing it if it is a
+// non-nil pointer.
+func (f field) value() interface{} {
+	if f.val.Kind() == reflect.Ptr {
+		if f.val.IsNil() {
+			return nil
+		}
+		return f.val.Elem().Interface()
+	}
+	return f.val.Interface()
+}
+
+// NewMapper returns a new Mapper that maps the fields without a db tag via the
+// given NameMapper. If the NameMapper is nil then SnakeCase is used.
+func NewMapper(fn NameMapper) Mapper {
+	if fn == nil {
+		fn = SnakeCase
+	}
+	return Mapper{name: fn}
+}
+
+// SnakeCase returns the given name of a field in snake case, for example,
+// UserID would become user_id, and HTTPStatus would become http_status. This
+// is the NameMapper used by default.
+func SnakeCase(name string) string { return naming.SnakeCase(name) }
+
+// fields returns the fields of the given struct, or pointer to a struct, that
+// are mapped to a column by the Mapper.
+func (m Mapper) fields(v interface{}) []field { return structFields(v, m.name) }
+
+// tableFields returns the fields of the given struct, or pointer to a struct,
+// for the given table. If the table was registered via RegisterTable, then the
+// NameMapper of the table is used if the Mapper does not have one, the fields
+// that are not columns of the table are dropped, and the fields in the primary
+// key of the table are marked.
+func (m Mapper) tableFields(table string, v interface{}) []field {
+	def, ok := LookupTable(table)
+
+	if !ok {
+		return m.fields(v)
+	}
+
+	if m.name == nil {
+		m.name = def.NameMapper
+	}
+
+	fields := m.fields(v)
+	mapped := fields[:0]
+
+	for _, f := range fields {
+		if len(def.Columns) > 0 && !hasString(def.Columns, f.col) {
+			continue
+		}
+
+		f.pk = hasString(def.PrimaryKey, f.col)
+		mapped = append(mapped, f)
+	}
+	return mapped
+}
+
+// structFields returns the fields of the given struct, or pointer to a struct,
+// that have a db tag. If a NameMapper is given, then the fields without a db
+// tag are returned too, with the column being the mapped name of the field.
+// The column name is taken from the first part of the db tag, and any
+// subsequent comma separated parts are treated as options. The query tag is
+// used as the operator for the field when used as a filter.
+// Fields tagged with db:"-" are skipped, and embedded structs without a db tag
+// have their fields flattened into the returned slice.
+func structFields(v interface{}, name NameMapper) []field {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		panic("query: expected struct, got " + rv.Kind().String())
+	}
+	return appendFields(nil, rv, name)
+}
+
+func appendFields(fields []field, rv reflect.Value, name NameMapper) []field {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("db")
+
+		if sf.Anonymous && !ok {
+			fv := rv.Field(i)
+
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+				fields = appendFields(fields, fv, name)
+			}
+			continue
+		}
+
+		if (!ok && name == nil) || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+
+		f := field{
+			col:  parts[0],
+			op:   sf.Tag.Get("query"),
+			opts: make(map[string]struct{}),
+			val:  rv.Field(i),
+		}
+
+		if f.col == "" {
+			f.col = sf.Name
+
+			if name != nil {
+				f.col = name(sf.Name)
+			}
+		}
+
+		for _, opt := range parts[1:] {
+			f.opts[strings.TrimSpace(opt)] = struct{}{}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// TableDef is the definition of a table registered via RegisterTable. This is
+// consulted by the helpers that depend on the conventions of a table, such as
+// InsertStruct, SetStruct, SoftDelete, NotDeleted, and CountOf.
+type TableDef struct {
+	// Name is the name of the table.
+	Name string
+
+	// Columns are the columns of the table. If given, then only the fields of
+	// a struct that map to these columns are used by the struct helpers.
+	Columns []string
+
+	// PrimaryKey are the columns of the primary key of the table. Zero value
+	// fields for these columns are skipped by InsertStruct, and these columns
+	// are never set by SetStruct, or UpdateStruct.
+	PrimaryKey []string
+
+	// SoftDelete is the column set by SoftDelete, and checked by NotDeleted. If
+	// empty, then the table does not support soft deletes.
+	SoftDelete string
+
+	// Tenant is the column that scopes the rows of the table to a tenant. If
+	// given, then SELECT, UPDATE, and DELETE queries on the table must be
+	// given ForTenant, or AllTenants, otherwise they fail to build.
+	Tenant string
+
+	// NameMapper maps the fields of a struct to the columns of the table for
+	// the struct helpers, if the Mapper being used does not have one.
+	NameMapper NameMapper
+}
+
+var (
+	tablesMu sync.RWMutex
+	tables   = make(map[string]TableDef)
+)
+
+// RegisterTable registers the given table definition, so the conventions of
+// the table are defined in a single place, for example,
+//
+//     var Posts = query.RegisterTable(query.TableDef{
+//         Name:       "posts",
+//         Columns:    []string{"id", "user_id", "title", "created_at", "deleted_at"},
+//         PrimaryKey: []string{"id"},
+//         SoftDelete: "deleted_at",
+//         NameMapper: query.SnakeCase,
+//     })
+//
+// The definition is consulted by the helpers for queries on the table, such
+// as InsertStruct, BulkInsertStruct, UpdateStruct, SetStruct, WhereStruct,
+// SoftDelete, and NotDeleted. Tables that have not been registered keep the
+// default conventions. This panics if the name is empty, or if the table has
+// already been registered. This should be called during initialization.
+func RegisterTable(def TableDef) TableDef {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+
+	if def.Name == "" {
+		panic("query: table name is empty")
+	}
+
+	if _, ok := tables[def.Name]; ok {
+		panic("query: table " + def.Name + " already registered")
+	}
+
+	if def.Tenant != "" {
+		atomic.AddInt32(&tenantTables, 1)
+	}
+
+	tables[def.Name] = def
+	return def
+}
+
+// LookupTable returns the definition of the given table if it was registered
+// via RegisterTable. Any alias given with the table is ignored.
+func LookupTable(table string) (TableDef, bool) {
+	if parts := strings.Fields(table); len(parts) > 0 {
+		table = parts[0]
+	}
+
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+
+	def, ok := tables[table]
+	return def, ok
+}
+
+// Select builds up a SELECT query for the columns of the table, applying the
+// given options. If the table was defined without any columns, then all of
+// the columns are selected.
+func (t TableDef) Select(opts ...Option) Query {
+	cols := t.Columns
+
+	if len(cols) == 0 {
+		cols = []string{"*"}
+	}
+	return Select(Columns(cols...), append([]Option{From(t.Name)}, opts...)...)
+}
+
+// Count builds up a query that counts the rows the query built by Select
+// would return for the given options, via CountOf, so soft deleted rows are
+// not counted. This should be used for the total of a page of results from
+// Select, so both queries are built from the same definition and options, for
+// example,
+//
+//     opts := []query.Option{query.Where("user_id", "=", query.Arg(10)), query.NotDeleted()}
+//
+//     page := Posts.Select(append(opts, query.Limit(25))...)
+//     total := Posts.Count(opts...)
+//
+// would result in the following queries being built,
+//
+//     SELECT id, user_id, title, created_at, deleted_at FROM posts WHERE (user_id = $1 AND deleted_at IS NULL) LIMIT 25
+//     SELECT COUNT(*) FROM posts WHERE (user_id = $1 AND deleted_at IS NULL)
+func (t TableDef) Count(opts ...Option) Query { return CountOf(t.Select(opts...)) }
+
+// softDeleteCol returns the column used for soft deletes on the given table,
+// and whether the table supports soft deletes. This is deleted_at for tables
+// that have not been registered.
+func softDeleteCol(table string) (string, bool) {
+	if def, ok := LookupTable(table); ok {
+		return def.SoftDelete, def.SoftDelete != ""
+	}
+	return "deleted_at", true
+}
+
+func hasString(ss []string, s string) bool {
+	for _, s1 := range ss {
+		if s1 == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package query
+
+import "database/sql"
+
+// Template is a Query that has been built once, so that it can be bound with
+// different values for its named arguments each time it is used. This avoids
+// building the same Query over and over again.
+type Template struct {
+	sql     string
+	args    []interface{}
+	dialect *Dialect
+	err     error
+}
+
+// Param returns a named argument expression for the given name with no value.
+// The value should be given when the Query is bound via Bind, for example,
+//
+//     t := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Param("user_id")),
+//     ).Template()
+//
+//     sql, args := t.Bind(map[string]interface{}{"user_id": 10})
+func Param(name string) argExpr { return Named(name, nil) }
+
+// Template builds up the Query and returns it as a Template. If the Query
+// cannot be built then the Template will have no SQL, and the error is
+// returned from its Err method. Dedupe is not applied to a Template, since
+// arguments that are the same when built may differ once bound.
+func (q Query) Template() Template {
+	q.dedupe = false
+
+	d := dialectOr(q.dialect)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b, _, args, err := q.appendWrite(*buf, d, d.placeholder, 1)
+	*buf = b
+
+	if err != nil {
+		return Template{
+			dialect: d,
+			err:     err,
+		}
+	}
+
+	return Template{
+		sql:     string(b),
+		args:    args,
+		dialect: d,
+	}
+}
+
+// Bind builds up the Query and binds the given values to its named arguments,
+// see Template.Bind.
+func (q Query) Bind(vals map[string]interface{}) (string, []interface{}) {
+	return q.Template().Bind(vals)
+}
+
+// SQL returns the built SQL of the Template.
+func (t Template) SQL() string { return t.sql }
+
+// Err returns the error from building the Query of the Template, if any.
+func (t Template) Err() error { return t.err }
+
+// Params returns the names of the named arguments in the Template, in the order
+// they first appear.
+func (t Template) Params() []string {
+	names := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, arg := range t.args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			if _, ok := seen[named.Name]; !ok {
+				seen[named.Name] = struct{}{}
+				names = append(names, named.Name)
+			}
+		}
+	}
+	return names
+}
+
+// Bind returns the SQL of the Template along with its arguments. The value of
+// each named argument is replaced with the value of the same name in the given
+// map. Named arguments not in the map keep the value they were given, which for
+// arguments given via Param will be nil.
+func (t Template) Bind(vals map[string]interface{}) (string, []interface{}) {
+	args := make([]interface{}, 0, len(t.args))
+
+	for _, arg := range t.args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			arg = named.Value
+
+			if val, ok := vals[named.Name]; ok {
+				arg = val
+			}
+		}
+		args = append(args, arg)
+	}
+	return t.sql, t.dialect.args(args)
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// tenancy is how a Query has been scoped to the tenants of a table, via
+// ForTenant or AllTenants.
+type tenancy uint8
+
+const (
+	_NoTenant tenancy = iota
+	_Tenant
+	_AllTenants
+)
+
+// tenantTables is the number of tables registered with a Tenant column, this
+// allows for the check for a tenant to be skipped when there are none.
+var tenantTables int32
+
+// ForTenant scopes the Query to the given tenant. If the table of the Query was
+// registered via RegisterTable with a Tenant column, then a WHERE clause for
+// the tenant is appended to SELECT, UPDATE, and DELETE queries on the table.
+// This is applied once the Query is built, and will be placed after any other
+// WHERE clauses and conjoined with AND. If any of the other WHERE clauses are
+// conjoined with OR, then they are grouped in parentheses, so the tenant
+// applies to the query as a whole, for example,
+//
+//     var Posts = query.RegisterTable(query.TableDef{
+//         Name:   "posts",
+//         Tenant: "tenant_id",
+//     })
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         query.Where("user_id", "=", query.Arg(10)),
+//         query.OrWhere("pinned", "=", query.Arg(true)),
+//         query.ForTenant(tenantID),
+//     )
+//
+// would result in the following query being built,
+//
+//     SELECT * FROM posts WHERE ((user_id = $1 OR pinned = $2) AND posts.tenant_id = $3)
+//
+// The Tenant column is qualified with the alias of the table, so it stays
+// unambiguous if the Query joins another table with the same column.
+//
+// Queries on a table with a Tenant column that are not given ForTenant, or
+// AllTenants, are built as an empty string, so a query cannot leak the rows of
+// other tenants by omission. BuildErr, BuildTo, and MarshalJSON return ErrTenant.
+// Subqueries are checked in the same way, so each subquery must be given
+// ForTenant too. Only the table the Query operates on is checked, and not the
+// tables that are joined.
+func ForTenant(id interface{}) Option {
+	tenant := deferOpt(func(q Query) Query {
+		col, ok := q.tenantCol()
+
+		if !ok {
+			return q
+		}
+
+		return q.insertAfter(whereClause{
+			conjunction: "AND",
+			op:          "=",
+			left:        q.alias().Ident(col),
+			right:       Arg(id),
+			filter:      true,
+		}, _FromClause, _SetClause, _WhereClause)
+	})
+
+	return func(q Query) Query {
+		q.tenancy = _Tenant
+		return tenant(q)
+	}
+}
+
+// AllTenants marks the Query as deliberately operating on the rows of every
+// tenant, so it can be built without ForTenant. This should only be used for
+// queries that are not made on behalf of a tenant, such as for maintenance.
+func AllTenants() Option {
+	return func(q Query) Query {
+		q.tenancy = _AllTenants
+		return q
+	}
+}
+
+// tenantCol returns the Tenant column of the table of the Query, if the Query
+// is a statement that should be scoped to a tenant.
+func (q Query) tenantCol() (string, bool) {
+	if atomic.LoadInt32(&tenantTables) == 0 {
+		return "", false
+	}
+
+	switch q.stmt {
+	case _Select, _SelectDistinct, _SelectDistinctOn, _Update, _Delete:
+	default:
+		return "", false
+	}
+
+	def, ok := LookupTable(q.Table())
+	return def.Tenant, ok && def.Tenant != ""
+}
+
+// tenantErr returns ErrTenant if the Query is on a table with a Tenant column,
+// and was not given ForTenant or AllTenants.
+func (q Query) tenantErr() error {
+	if q.tenancy != _NoTenant {
+		return nil
+	}
+
+	if _, ok := q.tenantCol(); ok {
+		return fmt.Errorf("query: %w for %s on %s", ErrTenant, q.stmt, q.Table())
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// EncodeToken encodes the given values into an opaque token for keyset
+// pagination via Seek. The values would typically be those of the columns the
+// query is ordered by for the last row of a page, for example,
+//
+//     last := posts[len(posts)-1]
+//
+//     next, err := query.EncodeToken(key, last.CreatedAt, last.ID)
+//
+// The token is signed with the given key via HMAC-SHA256, so a token that has
+// been modified will be rejected by DecodeToken. The token is not encrypted,
+// so the values should not be secret. The values can be nil, a bool, an
+// integer, a float, a string, a []byte, a time.Time, or a driver.Valuer that
+// returns one of these.
+func EncodeToken(key []byte, vals ...interface{}) (string, error) {
+	items := make([]string, 0, len(vals))
+
+	for _, v := range vals {
+		item, err := tokenItem(v)
+
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item)
+	}
+
+	payload, err := json.Marshal(items)
+
+	if err != nil {
+		return "", err
+	}
+
+	b := append(payload, tokenMAC(key, payload)...)
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeToken decodes the values from the given token, as encoded by
+// EncodeToken. This returns ErrToken if the token is malformed, or if it was
+// not signed with the given key. Integers are decoded as an int64, unsigned
+// integers as a uint64, and floats as a float64.
+func DecodeToken(key []byte, tok string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(tok)
+
+	if err != nil || len(b) < sha256.Size {
+		return nil, fmt.Errorf("query: %w: malformed", ErrToken)
+	}
+
+	payload, mac := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+
+	if !hmac.Equal(mac, tokenMAC(key, payload)) {
+		return nil, fmt.Errorf("query: %w: signature mismatch", ErrToken)
+	}
+
+	var items []string
+
+	if err := json.Unmarshal(payload, &items); err != nil {
+		return nil, fmt.Errorf("query: %w: malformed", ErrToken)
+	}
+
+	vals := make([]interface{}, 0, len(items))
+
+	for _, item := range items {
+		v, err := tokenValue(item)
+
+		if err != nil {
+			return nil, fmt.Errorf("query: %w: malformed value %q", ErrToken, item)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// SeekToken returns a Seek option for the given columns, seeking past the
+// values decoded from the given token. If the token is empty then the option
+// only orders by the given columns, as for the first page, for example,
+//
+//     seek, err := query.SeekToken(order, key, r.URL.Query().Get("after"))
+//
+//     if err != nil {
+//         // Handle error.
+//     }
+//
+//     q := query.Select(
+//         query.Columns("*"),
+//         query.From("posts"),
+//         seek,
+//         query.Limit(25),
+//     )
+//
+// This returns ErrToken if the token cannot be decoded, or if the number of
+// values in the token does not match the number of columns.
+func SeekToken(order []OrderedColumn, key []byte, tok string) (Option, error) {
+	if tok == "" {
+		return Seek(order), nil
+	}
+
+	vals, err := DecodeToken(key, tok)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vals) != len(order) {
+		return nil, fmt.Errorf("query: %w: expected %d values, got %d", ErrToken, len(order), len(vals))
+	}
+	return Seek(order, vals...), nil
+}
+
+func tokenMAC(key, payload []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// tokenItem returns the given value as a string prefixed with a byte for its
+// type, so the type can be restored when decoded.
+func tokenItem(v interface{}) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+
+		if err != nil {
+			return "", err
+		}
+		v = val
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "n", nil
+	case bool:
+		return "B" + strconv.FormatBool(v), nil
+	case int:
+		return "i" + strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return "i" + strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return "i" + strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return "i" + strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return "i" + strconv.FormatInt(v, 10), nil
+	case uint:
+		return "u" + strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return "u" + strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return "u" + strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return "u" + strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return "u" + strconv.FormatUint(v, 10), nil
+	case float32:
+		return "f" + strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return "f" + strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return "s" + v, nil
+	case []byte:
+		return "b" + base64.StdEncoding.EncodeToString(v), nil
+	case time.Time:
+		return "t" + v.Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("query: cannot encode %T in token", v)
+}
+
+// tokenValue returns the value of the given string as encoded by tokenItem.
+func tokenValue(item string) (interface{}, error) {
+	if item == "" {
+		return nil, ErrToken
+	}
+
+	s := item[1:]
+
+	switch item[0] {
+	case 'n':
+		return nil, nil
+	case 'B':
+		return strconv.ParseBool(s)
+	case 'i':
+		return strconv.ParseInt(s, 10, 64)
+	case 'u':
+		return strconv.ParseUint(s, 10, 64)
+	case 'f':
+		return strconv.ParseFloat(s, 64)
+	case 's':
+		return s, nil
+	case 'b':
+		return base64.StdEncoding.DecodeString(s)
+	case 't':
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	return nil, ErrToken
+}
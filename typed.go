@@ -0,0 +1,88 @@
+package query
+
+import (
+	"context"
+
+	"github.com/andrewpillar/query/queryscan"
+)
+
+// All builds up the given query and executes it on the given database,
+// scanning each of the returned rows into a T via queryscan, for example,
+//
+//     posts, err := query.All[Post](ctx, db, q)
+//
+// T should either be a struct, or a pointer to a struct.
+func All[T any](ctx context.Context, db Execer, q Runner) ([]T, error) {
+	rows, err := q.QueryContext(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+
+	if err := queryscan.All(rows, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// One builds up the given query and executes it on the given database,
+// scanning the first row returned into a T via queryscan. If no rows are
+// returned then sql.ErrNoRows is returned, for example,
+//
+//     post, err := query.One[Post](ctx, db, q)
+//
+// T should be a struct.
+func One[T any](ctx context.Context, db Execer, q Runner) (T, error) {
+	var item, zero T
+
+	rows, err := q.QueryContext(ctx, db)
+
+	if err != nil {
+		return zero, err
+	}
+
+	if err := queryscan.One(rows, &item); err != nil {
+		return zero, err
+	}
+	return item, nil
+}
+
+// ReturningInto executes the given INSERT, UPDATE, or DELETE query on the
+// given database with a RETURNING clause for the fields of the given struct,
+// and scans the returned row back into the struct. Any RETURNING clause the
+// query already has is replaced. This covers the case of getting the columns
+// set by the database, such as the id and created_at, after an INSERT, for
+// example,
+//
+//     u := User{Email: "me@example.com"}
+//
+//     err := query.ReturningInto(ctx, db, query.InsertStruct("users", u), &u)
+//
+// The columns are derived from the fields in the same way as ReturningStruct.
+// If no row is returned then sql.ErrNoRows is returned. T should be a struct.
+func ReturningInto[T any](ctx context.Context, db Execer, q Query, dest *T) error {
+	fields := tags.tableFields(q.Table(), dest)
+
+	cols := make([]string, 0, len(fields))
+	vals := make([]interface{}, 0, len(fields))
+
+	for _, f := range fields {
+		cols = append(cols, f.col)
+		vals = append(vals, f.val.Addr().Interface())
+	}
+
+	clauses := make([]clause, 0, len(q.clauses))
+
+	for _, cl := range q.clauses {
+		if cl.kind() != _ReturningClause {
+			clauses = append(clauses, cl)
+		}
+	}
+
+	q.clauses = clauses
+	q = Returning(cols...)(q)
+
+	return q.QueryRowContext(ctx, db).Scan(vals...)
+}
@@ -0,0 +1,399 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrOperator is returned when a WHERE clause uses an operator that is not
+	// known.
+	ErrOperator = errors.New("unknown operator")
+
+	// ErrClause is returned when a clause is used in a statement that does not
+	// support it.
+	ErrClause = errors.New("clause not supported")
+
+	// ErrTable is returned when a statement that requires a table has not been
+	// given one.
+	ErrTable = errors.New("missing table")
+
+	// ErrFrom is returned when a SELECT statement for the columns of a table
+	// has no FROM clause.
+	ErrFrom = errors.New("missing FROM")
+
+	// ErrColumns is returned when the columns of an INSERT statement do not
+	// match its values, or when the values given to Seek do not match its
+	// columns.
+	ErrColumns = errors.New("columns do not match values")
+
+	// ErrTooManyParams is returned when a query has more parameters than the
+	// Dialect allows.
+	ErrTooManyParams = errors.New("too many parameters")
+
+	// ErrChannel is returned when the name of a channel given to LISTEN,
+	// UNLISTEN, or NOTIFY is not valid.
+	ErrChannel = errors.New("invalid channel")
+
+	// ErrSyntax is returned when the SQL given to Parse cannot be parsed.
+	ErrSyntax = errors.New("syntax error")
+
+	// ErrUnsupported is returned when the SQL given to Parse uses something
+	// that cannot be expressed as a Query.
+	ErrUnsupported = errors.New("unsupported SQL")
+
+	// ErrSetting is returned when the name of a setting given to SET is not
+	// valid.
+	ErrSetting = errors.New("invalid setting")
+
+	// ErrSoftDelete is returned when SoftDelete is used on a table that was
+	// registered without a SoftDelete column.
+	ErrSoftDelete = errors.New("soft delete not supported")
+
+	// ErrTenant is returned when a query on a table registered with a Tenant
+	// column is not scoped to a tenant via ForTenant, or AllTenants.
+	ErrTenant = errors.New("missing tenant")
+
+	// ErrToken is returned when a token given to DecodeToken is malformed, or
+	// was not signed with the given key.
+	ErrToken = errors.New("invalid token")
+)
+
+// operators is the whitelist of operators that can be used in a WHERE clause.
+var operators = map[string]struct{}{
+	"":                     {},
+	"=":                    {},
+	"!=":                   {},
+	"<>":                   {},
+	"<":                    {},
+	"<=":                   {},
+	">":                    {},
+	">=":                   {},
+	"~":                    {},
+	"~*":                   {},
+	"!~":                   {},
+	"!~*":                  {},
+	"@>":                   {},
+	"<@":                   {},
+	"&&":                   {},
+	"IN":                   {},
+	"NOT IN":               {},
+	"IS":                   {},
+	"IS NOT":               {},
+	"LIKE":                 {},
+	"NOT LIKE":             {},
+	"ILIKE":                {},
+	"NOT ILIKE":            {},
+	"SIMILAR TO":           {},
+	"NOT SIMILAR TO":       {},
+	"IS DISTINCT FROM":     {},
+	"IS NOT DISTINCT FROM": {},
+	"EXISTS":               {},
+	"NOT EXISTS":           {},
+	"= ANY":                {},
+	"!= ALL":               {},
+}
+
+// clauseStmts is the set of statements each kind of clause can be used in.
+var clauseStmts = map[clauseKind][]statement{
+	_FromClause:      {_Select, _SelectDistinct, _SelectDistinctOn, _Update},
+	_GroupClause:     {_Select, _SelectDistinct, _SelectDistinctOn},
+	_LimitClause:     {_Select, _SelectDistinct, _SelectDistinctOn},
+	_OffsetClause:    {_Select, _SelectDistinct, _SelectDistinctOn},
+	_OrderClause:     {_Select, _SelectDistinct, _SelectDistinctOn},
+	_ValuesClause:    {_Insert},
+	_WhereClause:     {_Select, _SelectDistinct, _SelectDistinctOn, _Update, _Delete},
+	_ReturningClause: {_Insert, _Update, _Delete},
+	_SetClause:       {_Update},
+	_ConflictClause:  {_Insert},
+}
+
+// Validate checks the Query for any errors that would result in invalid SQL
+// being built, or in SQL that would fail against the database. An error is
+// returned if,
+//
+//     a WHERE clause uses an unknown operator
+//     a clause is given to a statement that does not support it, such as SET
+//     on a SELECT statement
+//     an INSERT, UPDATE, or DELETE statement has no table
+//     a SELECT statement for columns of a table has no FROM clause
+//     an INSERT statement has no columns, or values that do not match them
+//     an UPDATE statement has no SET clause
+//     the Query has too many parameters for its Dialect
+//
+// Subqueries are validated too. A RETURNING clause is not an error for a
+// Dialect that does not support it, since it is dropped when built. This is
+// meant for catching mistakes in tests, rather than at the point the query is
+// sent to the database.
+func (q Query) Validate() error {
+	if err := q.validate(dialectOr(q.dialect)); err != nil {
+		return err
+	}
+	return q.CheckParams()
+}
+
+// validate checks the Query for any errors that would result in invalid SQL
+// being built for the given Dialect. This will check the operators used in
+// WHERE clauses, that each clause is supported by the statement, and that
+// statements which require a table have one. Subqueries are validated too.
+func (q Query) validate(d *Dialect) error {
+	q = applyHooks(q.finalize())
+
+	if len(q.errs) > 0 {
+		return q.errs[0]
+	}
+
+	if err := q.tenantErr(); err != nil {
+		return err
+	}
+
+	switch q.stmt {
+	case _Insert, _Update, _Delete:
+		if strings.TrimSpace(q.table) == "" {
+			return fmt.Errorf("query: %w for %s", ErrTable, q.stmt)
+		}
+	}
+
+	for _, expr := range q.exprs {
+		if err := validateExpr(expr, d); err != nil {
+			return err
+		}
+	}
+
+	var set, values, from bool
+
+	for _, cl := range q.clauses {
+		kind := cl.kind()
+
+		switch kind {
+		case _FromClause:
+			from = true
+		case _SetClause:
+			set = true
+		case _ValuesClause:
+			values = true
+
+			if err := q.validateValues(cl.(valuesClause)); err != nil {
+				return err
+			}
+		}
+
+		if err := clauseErr(kind, q.stmt); err != nil {
+			return err
+		}
+
+		if v, ok := cl.(whereClause); ok {
+			if !Op(v.op).Valid() {
+				return fmt.Errorf("query: %w %q", ErrOperator, v.op)
+			}
+
+			if v.left != nil {
+				if err := validateExpr(v.left, d); err != nil {
+					return err
+				}
+			}
+
+			if err := validateExpr(v.right, d); err != nil {
+				return err
+			}
+		}
+
+		if v, ok := cl.(setClause); ok {
+			if err := validateExpr(v.expr, d); err != nil {
+				return err
+			}
+		}
+
+		if v, ok := cl.(unionClause); ok {
+			if err := v.q.validate(d); err != nil {
+				return err
+			}
+		}
+
+		if v, ok := cl.(fromQueryClause); ok {
+			if err := v.q.validate(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	if q.stmt == _Update && !set {
+		return fmt.Errorf("query: %w: SET in %s", ErrClause, q.stmt)
+	}
+
+	if q.stmt == _Insert && !values {
+		return fmt.Errorf("query: %w: VALUES in %s", ErrClause, q.stmt)
+	}
+
+	if !from {
+		if cols, ok := q.selectCols(); ok {
+			for _, col := range cols.items {
+				if columnRef(col) {
+					return fmt.Errorf("query: %w for %s %s", ErrFrom, q.stmt, strings.Join(cols.items, ", "))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sqlValueFuncs are the SQL functions that are called without parentheses, so
+// look like a column when given as one.
+var sqlValueFuncs = map[string]struct{}{
+	"NULL":              {},
+	"TRUE":              {},
+	"FALSE":             {},
+	"CURRENT_DATE":      {},
+	"CURRENT_TIME":      {},
+	"CURRENT_TIMESTAMP": {},
+	"CURRENT_USER":      {},
+	"LOCALTIME":         {},
+	"LOCALTIMESTAMP":    {},
+}
+
+// columnRef reports whether the given column of a SELECT statement refers to
+// the column of a table, such as id, p.*, or "id AS post_id", and so needs a
+// FROM clause. Expressions such as NOW() or 1 do not.
+func columnRef(col string) bool {
+	fields := strings.Fields(col)
+
+	if len(fields) == 0 {
+		return false
+	}
+
+	name := fields[0]
+
+	if _, ok := sqlValueFuncs[strings.ToUpper(name)]; ok {
+		return false
+	}
+
+	for _, part := range strings.Split(name, ".") {
+		if part != "*" && !identPattern.MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// clauseErr returns ErrClause if the given kind of clause cannot be used in
+// the given statement.
+func clauseErr(kind clauseKind, stmt statement) error {
+	stmts, ok := clauseStmts[kind]
+
+	if !ok || stmt == _Stmt {
+		return nil
+	}
+
+	for _, s := range stmts {
+		if s == stmt {
+			return nil
+		}
+	}
+	return fmt.Errorf("query: %w: %s in %s", ErrClause, kind, stmt)
+}
+
+// Err returns the first error from an Option that was given to a statement
+// that does not support it, such as Set given to a SELECT statement, or
+// Values given to an UPDATE statement, or from an Option that was given an
+// unknown operator, such as Where, or if the Query is on a table registered
+// with a Tenant column and was not scoped to a tenant, see ForTenant. Unlike
+// Validate, this only checks for the misuse of options, and not for errors
+// that depend on the Dialect, so it is cheap enough to call before every query
+// is built. See Strict for having this checked when a Query is built.
+func (q Query) Err() error {
+	q = q.finalize()
+
+	if len(q.errs) > 0 {
+		return q.errs[0]
+	}
+
+	if err := q.tenantErr(); err != nil {
+		return err
+	}
+
+	for _, cl := range q.clauses {
+		if err := clauseErr(cl.kind(), q.stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectCols returns the list of columns a SELECT statement is for, if it was
+// given a list of columns.
+func (q Query) selectCols() (listExpr, bool) {
+	i := 0
+
+	switch q.stmt {
+	case _Select, _SelectDistinct:
+	case _SelectDistinctOn:
+		i = 1
+	default:
+		return listExpr{}, false
+	}
+
+	if i >= len(q.exprs) {
+		return listExpr{}, false
+	}
+
+	cols, ok := q.exprs[i].(listExpr)
+	return cols, ok && !cols.wrap
+}
+
+// insertCols returns the list of columns an INSERT statement is for, if it was
+// given a list of columns.
+func (q Query) insertCols() (listExpr, bool) {
+	if q.stmt != _Insert || len(q.exprs) == 0 {
+		return listExpr{}, false
+	}
+
+	cols, ok := q.exprs[0].(listExpr)
+	return cols, ok
+}
+
+// validateValues checks that the given VALUES clause of an INSERT statement
+// has a value for each of the columns of the statement.
+func (q Query) validateValues(v valuesClause) error {
+	cols, ok := q.insertCols()
+
+	if !ok {
+		return nil
+	}
+
+	if len(cols.items) == 0 {
+		return fmt.Errorf("query: %w: no columns for %s", ErrColumns, q.stmt)
+	}
+
+	if len(v.items) != len(cols.items) {
+		return fmt.Errorf("query: %w: %d columns, %d values", ErrColumns, len(cols.items), len(v.items))
+	}
+	return nil
+}
+
+// validateExpr validates the given expression if it is a subquery, or the
+// query of an EXPLAIN statement.
+func validateExpr(e Expr, d *Dialect) error {
+	switch v := e.(type) {
+	case Query:
+		return v.validate(d)
+	case subqueryExpr:
+		return v.q.validate(d)
+	case explainExpr:
+		return v.q.validate(d)
+	}
+	return nil
+}
+
+// CheckParams returns ErrTooManyParams if the Query has more arguments than
+// the maximum number of parameters allowed by its Dialect. This can be used to
+// catch the error before the query is sent to the database, where the error
+// can be less clear.
+func (q Query) CheckParams() error {
+	d := dialectOr(q.dialect)
+
+	if n := len(q.Args()); d.maxParams > 0 && n > d.maxParams {
+		return fmt.Errorf("query: %w: %d exceeds the maximum of %d for %s", ErrTooManyParams, n, d.maxParams, d.name)
+	}
+	return nil
+}
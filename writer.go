@@ -0,0 +1,159 @@
+package query
+
+// SQLWriter is what an SQLExpr is written into. The SQL and the arguments of
+// an expression are written together, so the placeholders in the SQL are
+// always in the same order as the arguments. Placeholders are written as ?,
+// these are replaced with the placeholders of the Dialect when the Query the
+// expression is in is built.
+type SQLWriter struct {
+	buf  buffer
+	args []interface{}
+	d    *Dialect
+	err  error
+}
+
+// SQLExpr is an Expr that writes its SQL and arguments into an SQLWriter in a
+// single pass, rather than building its SQL and returning its arguments
+// separately. This is the preferred way of implementing an Expr outside of the
+// package, for example,
+//
+//     type Between struct {
+//         Col      string
+//         Min, Max interface{}
+//     }
+//
+//     func (b Between) WriteSQL(w *query.SQLWriter) {
+//         w.WriteIdent(b.Col)
+//         w.WriteString(" BETWEEN ")
+//         w.WriteArg(b.Min)
+//         w.WriteString(" AND ")
+//         w.WriteArg(b.Max)
+//     }
+//
+//     func (b Between) Build() string {
+//         s, _ := query.WriteSQL(b, nil)
+//         return s
+//     }
+//
+//     func (b Between) Args() []interface{} {
+//         _, args := query.WriteSQL(b, nil)
+//         return args
+//     }
+//
+// When an SQLExpr is used in a Query it is written for the Dialect of the
+// Query, so the Build and Args methods are only used when the expression is
+// used on its own.
+type SQLExpr interface {
+	Expr
+
+	// WriteSQL writes the SQL and arguments of the expression into the given
+	// SQLWriter.
+	WriteSQL(w *SQLWriter)
+}
+
+var _ SQLExpr = (*joinExpr)(nil)
+
+// NewSQLWriter returns a new SQLWriter for writing expressions for the given
+// Dialect. If the Dialect is nil then the DefaultDialect is used.
+func NewSQLWriter(d *Dialect) *SQLWriter {
+	return &SQLWriter{d: dialectOr(d)}
+}
+
+// WriteSQL writes the given expression for the given Dialect, and returns the
+// SQL and arguments that were written. If the Dialect is nil then the
+// DefaultDialect is used.
+func WriteSQL(e Expr, d *Dialect) (string, []interface{}) {
+	w := NewSQLWriter(d)
+	w.WriteExpr(e)
+	return w.String(), w.Args()
+}
+
+// Dialect returns the Dialect the SQLWriter is writing for.
+func (w *SQLWriter) Dialect() *Dialect { return w.d }
+
+// WriteString writes the given string of SQL.
+func (w *SQLWriter) WriteString(s string) (int, error) { return w.buf.WriteString(s) }
+
+// WriteByte writes the given byte of SQL.
+func (w *SQLWriter) WriteByte(c byte) error { return w.buf.WriteByte(c) }
+
+// WriteArg writes a placeholder for the given argument, and collects the
+// argument.
+func (w *SQLWriter) WriteArg(val interface{}) {
+	w.buf.WriteByte('?')
+	w.args = append(w.args, val)
+}
+
+// WriteIdent writes the given identifier, this is quoted if the Dialect
+// requires it.
+func (w *SQLWriter) WriteIdent(ident string) {
+	w.buf.WriteString(w.d.ident(ident))
+}
+
+// WriteExpr writes the given expression, along with its arguments. A Query is
+// written without parentheses, use WriteSubquery for writing a Query as a
+// subquery.
+func (w *SQLWriter) WriteExpr(e Expr) {
+	switch v := e.(type) {
+	case nil:
+		return
+	case SQLExpr:
+		v.WriteSQL(w)
+		return
+	case argExpr:
+		w.WriteArg(v.val)
+		return
+	}
+
+	w.buf.WriteString(buildExpr(e, w.d))
+	w.args = append(w.args, e.Args()...)
+}
+
+// WriteSubquery writes the given Query wrapped in parentheses, along with its
+// arguments.
+func (w *SQLWriter) WriteSubquery(q Query) {
+	w.buf.WriteByte('(')
+	w.WriteExpr(q)
+	w.buf.WriteByte(')')
+}
+
+// WriteExprs writes the given expressions, separated by the given string.
+func (w *SQLWriter) WriteExprs(sep string, exprs ...Expr) {
+	for i, e := range exprs {
+		if i > 0 {
+			w.buf.WriteString(sep)
+		}
+		w.WriteExpr(e)
+	}
+}
+
+// String returns the SQL that has been written.
+func (w *SQLWriter) String() string { return w.buf.String() }
+
+// Args returns the arguments that have been written.
+func (w *SQLWriter) Args() []interface{} { return w.args }
+
+// writeSQL writes the given expression for the given Dialect, and returns the
+// SQL and arguments that were written. If the Dialect is nil then the
+// DefaultDialect is used.
+func writeSQL(e SQLExpr, d *Dialect) (string, []interface{}) {
+	w := SQLWriter{d: dialectOr(d)}
+	e.WriteSQL(&w)
+	return w.String(), w.args
+}
+
+// buildSQL returns the SQL of the given expression written for the given
+// Dialect. This is used for the Build method of the expressions that are
+// written via an SQLWriter.
+func buildSQL(e SQLExpr, d *Dialect) string {
+	s, _ := writeSQL(e, d)
+	return s
+}
+
+// sqlArgs returns the arguments of the given expression written for the given
+// Dialect. This is used for the Args method of the expressions that are
+// written via an SQLWriter.
+func sqlArgs(e SQLExpr, d *Dialect) []interface{} {
+	_, args := writeSQL(e, d)
+	return args
+}